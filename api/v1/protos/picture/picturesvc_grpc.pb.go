@@ -20,6 +20,10 @@ const _ = grpc.SupportPackageIsVersion7
 
 const (
 	Picture_Create_FullMethodName        = "/picture.Picture/Create"
+	Picture_CreateBatch_FullMethodName   = "/picture.Picture/CreateBatch"
+	Picture_CreateStream_FullMethodName  = "/picture.Picture/CreateStream"
+	Picture_CreateTile_FullMethodName    = "/picture.Picture/CreateTile"
+	Picture_Process_FullMethodName       = "/picture.Picture/Process"
 	Picture_ServiceStatus_FullMethodName = "/picture.Picture/ServiceStatus"
 )
 
@@ -28,6 +32,10 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type PictureClient interface {
 	Create(ctx context.Context, in *CreateRequest, opts ...grpc.CallOption) (*CreateResponse, error)
+	CreateBatch(ctx context.Context, in *CreateBatchRequest, opts ...grpc.CallOption) (*CreateBatchResponse, error)
+	CreateStream(ctx context.Context, opts ...grpc.CallOption) (Picture_CreateStreamClient, error)
+	CreateTile(ctx context.Context, in *CreateTileRequest, opts ...grpc.CallOption) (*CreateResponse, error)
+	Process(ctx context.Context, in *ProcessRequest, opts ...grpc.CallOption) (*ProcessResponse, error)
 	ServiceStatus(ctx context.Context, in *ServiceStatusRequest, opts ...grpc.CallOption) (*ServiceStatusResponse, error)
 }
 
@@ -48,6 +56,64 @@ func (c *pictureClient) Create(ctx context.Context, in *CreateRequest, opts ...g
 	return out, nil
 }
 
+func (c *pictureClient) CreateBatch(ctx context.Context, in *CreateBatchRequest, opts ...grpc.CallOption) (*CreateBatchResponse, error) {
+	out := new(CreateBatchResponse)
+	err := c.cc.Invoke(ctx, Picture_CreateBatch_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pictureClient) CreateStream(ctx context.Context, opts ...grpc.CallOption) (Picture_CreateStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Picture_ServiceDesc.Streams[0], Picture_CreateStream_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &pictureCreateStreamClient{stream}
+	return x, nil
+}
+
+type Picture_CreateStreamClient interface {
+	Send(*CreateStreamRequest) error
+	Recv() (*CreateStreamResponse, error)
+	grpc.ClientStream
+}
+
+type pictureCreateStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *pictureCreateStreamClient) Send(m *CreateStreamRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *pictureCreateStreamClient) Recv() (*CreateStreamResponse, error) {
+	m := new(CreateStreamResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *pictureClient) CreateTile(ctx context.Context, in *CreateTileRequest, opts ...grpc.CallOption) (*CreateResponse, error) {
+	out := new(CreateResponse)
+	err := c.cc.Invoke(ctx, Picture_CreateTile_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pictureClient) Process(ctx context.Context, in *ProcessRequest, opts ...grpc.CallOption) (*ProcessResponse, error) {
+	out := new(ProcessResponse)
+	err := c.cc.Invoke(ctx, Picture_Process_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *pictureClient) ServiceStatus(ctx context.Context, in *ServiceStatusRequest, opts ...grpc.CallOption) (*ServiceStatusResponse, error) {
 	out := new(ServiceStatusResponse)
 	err := c.cc.Invoke(ctx, Picture_ServiceStatus_FullMethodName, in, out, opts...)
@@ -62,6 +128,10 @@ func (c *pictureClient) ServiceStatus(ctx context.Context, in *ServiceStatusRequ
 // for forward compatibility
 type PictureServer interface {
 	Create(context.Context, *CreateRequest) (*CreateResponse, error)
+	CreateBatch(context.Context, *CreateBatchRequest) (*CreateBatchResponse, error)
+	CreateStream(Picture_CreateStreamServer) error
+	CreateTile(context.Context, *CreateTileRequest) (*CreateResponse, error)
+	Process(context.Context, *ProcessRequest) (*ProcessResponse, error)
 	ServiceStatus(context.Context, *ServiceStatusRequest) (*ServiceStatusResponse, error)
 	mustEmbedUnimplementedPictureServer()
 }
@@ -73,6 +143,18 @@ type UnimplementedPictureServer struct {
 func (UnimplementedPictureServer) Create(context.Context, *CreateRequest) (*CreateResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Create not implemented")
 }
+func (UnimplementedPictureServer) CreateBatch(context.Context, *CreateBatchRequest) (*CreateBatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateBatch not implemented")
+}
+func (UnimplementedPictureServer) CreateStream(Picture_CreateStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method CreateStream not implemented")
+}
+func (UnimplementedPictureServer) CreateTile(context.Context, *CreateTileRequest) (*CreateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateTile not implemented")
+}
+func (UnimplementedPictureServer) Process(context.Context, *ProcessRequest) (*ProcessResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Process not implemented")
+}
 func (UnimplementedPictureServer) ServiceStatus(context.Context, *ServiceStatusRequest) (*ServiceStatusResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ServiceStatus not implemented")
 }
@@ -107,6 +189,86 @@ func _Picture_Create_Handler(srv interface{}, ctx context.Context, dec func(inte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Picture_CreateBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PictureServer).CreateBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Picture_CreateBatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PictureServer).CreateBatch(ctx, req.(*CreateBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Picture_CreateStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(PictureServer).CreateStream(&pictureCreateStreamServer{stream})
+}
+
+type Picture_CreateStreamServer interface {
+	Send(*CreateStreamResponse) error
+	Recv() (*CreateStreamRequest, error)
+	grpc.ServerStream
+}
+
+type pictureCreateStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *pictureCreateStreamServer) Send(m *CreateStreamResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *pictureCreateStreamServer) Recv() (*CreateStreamRequest, error) {
+	m := new(CreateStreamRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Picture_CreateTile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateTileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PictureServer).CreateTile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Picture_CreateTile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PictureServer).CreateTile(ctx, req.(*CreateTileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Picture_Process_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProcessRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PictureServer).Process(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Picture_Process_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PictureServer).Process(ctx, req.(*ProcessRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Picture_ServiceStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ServiceStatusRequest)
 	if err := dec(in); err != nil {
@@ -136,11 +298,30 @@ var Picture_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Create",
 			Handler:    _Picture_Create_Handler,
 		},
+		{
+			MethodName: "CreateBatch",
+			Handler:    _Picture_CreateBatch_Handler,
+		},
+		{
+			MethodName: "CreateTile",
+			Handler:    _Picture_CreateTile_Handler,
+		},
+		{
+			MethodName: "Process",
+			Handler:    _Picture_Process_Handler,
+		},
 		{
 			MethodName: "ServiceStatus",
 			Handler:    _Picture_ServiceStatus_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "CreateStream",
+			Handler:       _Picture_CreateStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
 	Metadata: "picture/picturesvc.proto",
 }
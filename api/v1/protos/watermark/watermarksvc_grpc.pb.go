@@ -19,10 +19,24 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	Watermark_Get_FullMethodName           = "/watermark.watermark/Get"
-	Watermark_Remove_FullMethodName        = "/watermark.watermark/Remove"
-	Watermark_Add_FullMethodName           = "/watermark.watermark/Add"
-	Watermark_ServiceStatus_FullMethodName = "/watermark.watermark/ServiceStatus"
+	Watermark_Get_FullMethodName                   = "/watermark.watermark/Get"
+	Watermark_Remove_FullMethodName                = "/watermark.watermark/Remove"
+	Watermark_Add_FullMethodName                   = "/watermark.watermark/Add"
+	Watermark_AddStream_FullMethodName             = "/watermark.watermark/AddStream"
+	Watermark_AddBatch_FullMethodName              = "/watermark.watermark/AddBatch"
+	Watermark_GetStatus_FullMethodName             = "/watermark.watermark/GetStatus"
+	Watermark_ListDeadJobs_FullMethodName          = "/watermark.watermark/ListDeadJobs"
+	Watermark_RequeueJob_FullMethodName            = "/watermark.watermark/RequeueJob"
+	Watermark_RegisterWebhook_FullMethodName       = "/watermark.watermark/RegisterWebhook"
+	Watermark_ListWebhooks_FullMethodName          = "/watermark.watermark/ListWebhooks"
+	Watermark_RemoveWebhook_FullMethodName         = "/watermark.watermark/RemoveWebhook"
+	Watermark_ListWebhookDeliveries_FullMethodName = "/watermark.watermark/ListWebhookDeliveries"
+	Watermark_PingWebhook_FullMethodName           = "/watermark.watermark/PingWebhook"
+	Watermark_Watch_FullMethodName                 = "/watermark.watermark/Watch"
+	Watermark_CreateShareLink_FullMethodName       = "/watermark.watermark/CreateShareLink"
+	Watermark_ListShareLinks_FullMethodName        = "/watermark.watermark/ListShareLinks"
+	Watermark_RevokeShareLink_FullMethodName       = "/watermark.watermark/RevokeShareLink"
+	Watermark_ServiceStatus_FullMethodName         = "/watermark.watermark/ServiceStatus"
 )
 
 // WatermarkClient is the client API for Watermark service.
@@ -32,6 +46,20 @@ type WatermarkClient interface {
 	Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetResponse, error)
 	Remove(ctx context.Context, in *RemoveRequest, opts ...grpc.CallOption) (*RemoveResponse, error)
 	Add(ctx context.Context, in *AddRequest, opts ...grpc.CallOption) (*AddResponse, error)
+	AddStream(ctx context.Context, opts ...grpc.CallOption) (Watermark_AddStreamClient, error)
+	AddBatch(ctx context.Context, in *AddBatchRequest, opts ...grpc.CallOption) (*AddBatchResponse, error)
+	GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*GetStatusResponse, error)
+	ListDeadJobs(ctx context.Context, in *ListDeadJobsRequest, opts ...grpc.CallOption) (*ListDeadJobsResponse, error)
+	RequeueJob(ctx context.Context, in *RequeueJobRequest, opts ...grpc.CallOption) (*RequeueJobResponse, error)
+	RegisterWebhook(ctx context.Context, in *RegisterWebhookRequest, opts ...grpc.CallOption) (*WebhookResponse, error)
+	ListWebhooks(ctx context.Context, in *ListWebhooksRequest, opts ...grpc.CallOption) (*ListWebhooksResponse, error)
+	RemoveWebhook(ctx context.Context, in *WebhookRequest, opts ...grpc.CallOption) (*RemoveWebhookResponse, error)
+	ListWebhookDeliveries(ctx context.Context, in *WebhookRequest, opts ...grpc.CallOption) (*ListWebhookDeliveriesResponse, error)
+	PingWebhook(ctx context.Context, in *WebhookRequest, opts ...grpc.CallOption) (*PingWebhookResponse, error)
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (Watermark_WatchClient, error)
+	CreateShareLink(ctx context.Context, in *CreateShareLinkRequest, opts ...grpc.CallOption) (*ShareLinkResponse, error)
+	ListShareLinks(ctx context.Context, in *ListShareLinksRequest, opts ...grpc.CallOption) (*ListShareLinksResponse, error)
+	RevokeShareLink(ctx context.Context, in *ShareLinkRequest, opts ...grpc.CallOption) (*RevokeShareLinkResponse, error)
 	ServiceStatus(ctx context.Context, in *ServiceStatusRequest, opts ...grpc.CallOption) (*ServiceStatusResponse, error)
 }
 
@@ -70,6 +98,180 @@ func (c *watermarkClient) Add(ctx context.Context, in *AddRequest, opts ...grpc.
 	return out, nil
 }
 
+func (c *watermarkClient) AddStream(ctx context.Context, opts ...grpc.CallOption) (Watermark_AddStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Watermark_ServiceDesc.Streams[0], Watermark_AddStream_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &watermarkAddStreamClient{stream}
+	return x, nil
+}
+
+type Watermark_AddStreamClient interface {
+	Send(*AddStreamRequest) error
+	CloseAndRecv() (*AddResponse, error)
+	grpc.ClientStream
+}
+
+type watermarkAddStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *watermarkAddStreamClient) Send(m *AddStreamRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *watermarkAddStreamClient) CloseAndRecv() (*AddResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(AddResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *watermarkClient) AddBatch(ctx context.Context, in *AddBatchRequest, opts ...grpc.CallOption) (*AddBatchResponse, error) {
+	out := new(AddBatchResponse)
+	err := c.cc.Invoke(ctx, Watermark_AddBatch_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *watermarkClient) GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*GetStatusResponse, error) {
+	out := new(GetStatusResponse)
+	err := c.cc.Invoke(ctx, Watermark_GetStatus_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *watermarkClient) ListDeadJobs(ctx context.Context, in *ListDeadJobsRequest, opts ...grpc.CallOption) (*ListDeadJobsResponse, error) {
+	out := new(ListDeadJobsResponse)
+	err := c.cc.Invoke(ctx, Watermark_ListDeadJobs_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *watermarkClient) RequeueJob(ctx context.Context, in *RequeueJobRequest, opts ...grpc.CallOption) (*RequeueJobResponse, error) {
+	out := new(RequeueJobResponse)
+	err := c.cc.Invoke(ctx, Watermark_RequeueJob_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *watermarkClient) RegisterWebhook(ctx context.Context, in *RegisterWebhookRequest, opts ...grpc.CallOption) (*WebhookResponse, error) {
+	out := new(WebhookResponse)
+	err := c.cc.Invoke(ctx, Watermark_RegisterWebhook_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *watermarkClient) ListWebhooks(ctx context.Context, in *ListWebhooksRequest, opts ...grpc.CallOption) (*ListWebhooksResponse, error) {
+	out := new(ListWebhooksResponse)
+	err := c.cc.Invoke(ctx, Watermark_ListWebhooks_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *watermarkClient) RemoveWebhook(ctx context.Context, in *WebhookRequest, opts ...grpc.CallOption) (*RemoveWebhookResponse, error) {
+	out := new(RemoveWebhookResponse)
+	err := c.cc.Invoke(ctx, Watermark_RemoveWebhook_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *watermarkClient) ListWebhookDeliveries(ctx context.Context, in *WebhookRequest, opts ...grpc.CallOption) (*ListWebhookDeliveriesResponse, error) {
+	out := new(ListWebhookDeliveriesResponse)
+	err := c.cc.Invoke(ctx, Watermark_ListWebhookDeliveries_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *watermarkClient) PingWebhook(ctx context.Context, in *WebhookRequest, opts ...grpc.CallOption) (*PingWebhookResponse, error) {
+	out := new(PingWebhookResponse)
+	err := c.cc.Invoke(ctx, Watermark_PingWebhook_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *watermarkClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (Watermark_WatchClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Watermark_ServiceDesc.Streams[1], Watermark_Watch_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &watermarkWatchClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Watermark_WatchClient interface {
+	Recv() (*Update, error)
+	grpc.ClientStream
+}
+
+type watermarkWatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *watermarkWatchClient) Recv() (*Update, error) {
+	m := new(Update)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *watermarkClient) CreateShareLink(ctx context.Context, in *CreateShareLinkRequest, opts ...grpc.CallOption) (*ShareLinkResponse, error) {
+	out := new(ShareLinkResponse)
+	err := c.cc.Invoke(ctx, Watermark_CreateShareLink_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *watermarkClient) ListShareLinks(ctx context.Context, in *ListShareLinksRequest, opts ...grpc.CallOption) (*ListShareLinksResponse, error) {
+	out := new(ListShareLinksResponse)
+	err := c.cc.Invoke(ctx, Watermark_ListShareLinks_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *watermarkClient) RevokeShareLink(ctx context.Context, in *ShareLinkRequest, opts ...grpc.CallOption) (*RevokeShareLinkResponse, error) {
+	out := new(RevokeShareLinkResponse)
+	err := c.cc.Invoke(ctx, Watermark_RevokeShareLink_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *watermarkClient) ServiceStatus(ctx context.Context, in *ServiceStatusRequest, opts ...grpc.CallOption) (*ServiceStatusResponse, error) {
 	out := new(ServiceStatusResponse)
 	err := c.cc.Invoke(ctx, Watermark_ServiceStatus_FullMethodName, in, out, opts...)
@@ -86,6 +288,20 @@ type WatermarkServer interface {
 	Get(context.Context, *GetRequest) (*GetResponse, error)
 	Remove(context.Context, *RemoveRequest) (*RemoveResponse, error)
 	Add(context.Context, *AddRequest) (*AddResponse, error)
+	AddStream(Watermark_AddStreamServer) error
+	AddBatch(context.Context, *AddBatchRequest) (*AddBatchResponse, error)
+	GetStatus(context.Context, *GetStatusRequest) (*GetStatusResponse, error)
+	ListDeadJobs(context.Context, *ListDeadJobsRequest) (*ListDeadJobsResponse, error)
+	RequeueJob(context.Context, *RequeueJobRequest) (*RequeueJobResponse, error)
+	RegisterWebhook(context.Context, *RegisterWebhookRequest) (*WebhookResponse, error)
+	ListWebhooks(context.Context, *ListWebhooksRequest) (*ListWebhooksResponse, error)
+	RemoveWebhook(context.Context, *WebhookRequest) (*RemoveWebhookResponse, error)
+	ListWebhookDeliveries(context.Context, *WebhookRequest) (*ListWebhookDeliveriesResponse, error)
+	PingWebhook(context.Context, *WebhookRequest) (*PingWebhookResponse, error)
+	Watch(*WatchRequest, Watermark_WatchServer) error
+	CreateShareLink(context.Context, *CreateShareLinkRequest) (*ShareLinkResponse, error)
+	ListShareLinks(context.Context, *ListShareLinksRequest) (*ListShareLinksResponse, error)
+	RevokeShareLink(context.Context, *ShareLinkRequest) (*RevokeShareLinkResponse, error)
 	ServiceStatus(context.Context, *ServiceStatusRequest) (*ServiceStatusResponse, error)
 	mustEmbedUnimplementedWatermarkServer()
 }
@@ -103,6 +319,48 @@ func (UnimplementedWatermarkServer) Remove(context.Context, *RemoveRequest) (*Re
 func (UnimplementedWatermarkServer) Add(context.Context, *AddRequest) (*AddResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Add not implemented")
 }
+func (UnimplementedWatermarkServer) AddStream(Watermark_AddStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method AddStream not implemented")
+}
+func (UnimplementedWatermarkServer) AddBatch(context.Context, *AddBatchRequest) (*AddBatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddBatch not implemented")
+}
+func (UnimplementedWatermarkServer) GetStatus(context.Context, *GetStatusRequest) (*GetStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStatus not implemented")
+}
+func (UnimplementedWatermarkServer) ListDeadJobs(context.Context, *ListDeadJobsRequest) (*ListDeadJobsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDeadJobs not implemented")
+}
+func (UnimplementedWatermarkServer) RequeueJob(context.Context, *RequeueJobRequest) (*RequeueJobResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RequeueJob not implemented")
+}
+func (UnimplementedWatermarkServer) RegisterWebhook(context.Context, *RegisterWebhookRequest) (*WebhookResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RegisterWebhook not implemented")
+}
+func (UnimplementedWatermarkServer) ListWebhooks(context.Context, *ListWebhooksRequest) (*ListWebhooksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListWebhooks not implemented")
+}
+func (UnimplementedWatermarkServer) RemoveWebhook(context.Context, *WebhookRequest) (*RemoveWebhookResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveWebhook not implemented")
+}
+func (UnimplementedWatermarkServer) ListWebhookDeliveries(context.Context, *WebhookRequest) (*ListWebhookDeliveriesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListWebhookDeliveries not implemented")
+}
+func (UnimplementedWatermarkServer) PingWebhook(context.Context, *WebhookRequest) (*PingWebhookResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PingWebhook not implemented")
+}
+func (UnimplementedWatermarkServer) Watch(*WatchRequest, Watermark_WatchServer) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
+func (UnimplementedWatermarkServer) CreateShareLink(context.Context, *CreateShareLinkRequest) (*ShareLinkResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateShareLink not implemented")
+}
+func (UnimplementedWatermarkServer) ListShareLinks(context.Context, *ListShareLinksRequest) (*ListShareLinksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListShareLinks not implemented")
+}
+func (UnimplementedWatermarkServer) RevokeShareLink(context.Context, *ShareLinkRequest) (*RevokeShareLinkResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeShareLink not implemented")
+}
 func (UnimplementedWatermarkServer) ServiceStatus(context.Context, *ServiceStatusRequest) (*ServiceStatusResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ServiceStatus not implemented")
 }
@@ -173,6 +431,269 @@ func _Watermark_Add_Handler(srv interface{}, ctx context.Context, dec func(inter
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Watermark_AddStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(WatermarkServer).AddStream(&watermarkAddStreamServer{stream})
+}
+
+type Watermark_AddStreamServer interface {
+	SendAndClose(*AddResponse) error
+	Recv() (*AddStreamRequest, error)
+	grpc.ServerStream
+}
+
+type watermarkAddStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *watermarkAddStreamServer) SendAndClose(m *AddResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *watermarkAddStreamServer) Recv() (*AddStreamRequest, error) {
+	m := new(AddStreamRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Watermark_AddBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WatermarkServer).AddBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Watermark_AddBatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WatermarkServer).AddBatch(ctx, req.(*AddBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Watermark_GetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WatermarkServer).GetStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Watermark_GetStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WatermarkServer).GetStatus(ctx, req.(*GetStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Watermark_ListDeadJobs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDeadJobsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WatermarkServer).ListDeadJobs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Watermark_ListDeadJobs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WatermarkServer).ListDeadJobs(ctx, req.(*ListDeadJobsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Watermark_RequeueJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequeueJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WatermarkServer).RequeueJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Watermark_RequeueJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WatermarkServer).RequeueJob(ctx, req.(*RequeueJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Watermark_RegisterWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterWebhookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WatermarkServer).RegisterWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Watermark_RegisterWebhook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WatermarkServer).RegisterWebhook(ctx, req.(*RegisterWebhookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Watermark_ListWebhooks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListWebhooksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WatermarkServer).ListWebhooks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Watermark_ListWebhooks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WatermarkServer).ListWebhooks(ctx, req.(*ListWebhooksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Watermark_RemoveWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WebhookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WatermarkServer).RemoveWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Watermark_RemoveWebhook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WatermarkServer).RemoveWebhook(ctx, req.(*WebhookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Watermark_ListWebhookDeliveries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WebhookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WatermarkServer).ListWebhookDeliveries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Watermark_ListWebhookDeliveries_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WatermarkServer).ListWebhookDeliveries(ctx, req.(*WebhookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Watermark_PingWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WebhookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WatermarkServer).PingWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Watermark_PingWebhook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WatermarkServer).PingWebhook(ctx, req.(*WebhookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Watermark_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(WatermarkServer).Watch(m, &watermarkWatchServer{stream})
+}
+
+type Watermark_WatchServer interface {
+	Send(*Update) error
+	grpc.ServerStream
+}
+
+type watermarkWatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *watermarkWatchServer) Send(m *Update) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Watermark_CreateShareLink_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateShareLinkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WatermarkServer).CreateShareLink(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Watermark_CreateShareLink_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WatermarkServer).CreateShareLink(ctx, req.(*CreateShareLinkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Watermark_ListShareLinks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListShareLinksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WatermarkServer).ListShareLinks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Watermark_ListShareLinks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WatermarkServer).ListShareLinks(ctx, req.(*ListShareLinksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Watermark_RevokeShareLink_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShareLinkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WatermarkServer).RevokeShareLink(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Watermark_RevokeShareLink_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WatermarkServer).RevokeShareLink(ctx, req.(*ShareLinkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Watermark_ServiceStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ServiceStatusRequest)
 	if err := dec(in); err != nil {
@@ -210,11 +731,70 @@ var Watermark_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Add",
 			Handler:    _Watermark_Add_Handler,
 		},
+		{
+			MethodName: "AddBatch",
+			Handler:    _Watermark_AddBatch_Handler,
+		},
+		{
+			MethodName: "GetStatus",
+			Handler:    _Watermark_GetStatus_Handler,
+		},
+		{
+			MethodName: "ListDeadJobs",
+			Handler:    _Watermark_ListDeadJobs_Handler,
+		},
+		{
+			MethodName: "RequeueJob",
+			Handler:    _Watermark_RequeueJob_Handler,
+		},
+		{
+			MethodName: "RegisterWebhook",
+			Handler:    _Watermark_RegisterWebhook_Handler,
+		},
+		{
+			MethodName: "ListWebhooks",
+			Handler:    _Watermark_ListWebhooks_Handler,
+		},
+		{
+			MethodName: "RemoveWebhook",
+			Handler:    _Watermark_RemoveWebhook_Handler,
+		},
+		{
+			MethodName: "ListWebhookDeliveries",
+			Handler:    _Watermark_ListWebhookDeliveries_Handler,
+		},
+		{
+			MethodName: "PingWebhook",
+			Handler:    _Watermark_PingWebhook_Handler,
+		},
+		{
+			MethodName: "CreateShareLink",
+			Handler:    _Watermark_CreateShareLink_Handler,
+		},
+		{
+			MethodName: "ListShareLinks",
+			Handler:    _Watermark_ListShareLinks_Handler,
+		},
+		{
+			MethodName: "RevokeShareLink",
+			Handler:    _Watermark_RevokeShareLink_Handler,
+		},
 		{
 			MethodName: "ServiceStatus",
 			Handler:    _Watermark_ServiceStatus_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "AddStream",
+			Handler:       _Watermark_AddStream_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Watch",
+			Handler:       _Watermark_Watch_Handler,
+			ServerStreams: true,
+		},
+	},
 	Metadata: "watermark/watermarksvc.proto",
 }
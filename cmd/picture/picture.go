@@ -7,11 +7,13 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"runtime"
 	"syscall"
 	"time"
 	proto "watermark-service/api/v1/protos/picture"
 	"watermark-service/config"
 	"watermark-service/internal"
+	"watermark-service/internal/util"
 	"watermark-service/pkg/picture"
 	"watermark-service/pkg/picture/endpoints"
 	"watermark-service/pkg/picture/transport"
@@ -42,10 +44,51 @@ func main() {
 	}
 	defer closer.Close()
 
+	util.SetLimits(util.ImageLimits{
+		MaxBytes:      cfg.Limits.MaxBytes << 20,
+		MaxWidth:      cfg.Limits.MaxWidth,
+		MaxHeight:     cfg.Limits.MaxHeight,
+		MaxMegapixels: cfg.Limits.MaxMegapixels,
+	})
+
+	err = internal.InitFonts(cfg.Fonts, cfg.FontSize)
+	if err != nil {
+		zap.L().Fatal("Setup failed", zap.String("Fonts", "loading"), zap.Error(err))
+	}
+
+	interpolation, err := internal.ParseInterpolation(cfg.Interpolation)
+	if err != nil {
+		zap.L().Fatal("Setup failed", zap.String("config", "interpolation"), zap.Error(err))
+	}
+
+	var cache *internal.OverlayCache
+	if !cfg.Cache.Disabled {
+		entries, size := cfg.Cache.Entries, cfg.Cache.Size<<20
+		if entries <= 0 {
+			entries = picture.DefaultCacheEntries
+		}
+		if size <= 0 {
+			size = picture.DefaultCacheSize
+		}
+		cache = internal.NewOverlayCache(entries, size)
+	}
+
+	maxRenders, queueSize, queueTimeout := cfg.Concurrency.MaxRenders, cfg.Concurrency.QueueSize, cfg.Concurrency.QueueTimeout
+	if maxRenders <= 0 {
+		maxRenders = runtime.NumCPU()
+	}
+	if queueSize <= 0 {
+		queueSize = picture.DefaultQueueSize
+	}
+	if queueTimeout <= 0 {
+		queueTimeout = picture.DefaultQueueTimeout
+	}
+
 	var service picture.Service
 	{
-		service = picture.NewService()
+		service = picture.NewService(interpolation, cfg.MemoryLimit<<20, cfg.Workers, cache)
 		service = picture.PictureMiddleware()(service)
+		service = picture.LimitMiddleware(internal.NewLimiter(maxRenders, queueSize, queueTimeout))(service)
 	}
 
 	var (
@@ -74,7 +117,10 @@ func main() {
 		}
 		g.Add(func() error {
 			zap.L().Info("transport", zap.String("gRPC", "Listener"), zap.String("address", grpcAddr))
-			baseServer := grpc.NewServer(grpc.UnaryInterceptor(grpckit.Interceptor))
+			baseServer := grpc.NewServer(
+				grpc.UnaryInterceptor(grpckit.Interceptor),
+				grpc.MaxRecvMsgSize(picture.MaxMessageSize),
+			)
 			proto.RegisterPictureServer(baseServer, grpcServer)
 			return baseServer.Serve(grpcListener)
 		}, func(error) {
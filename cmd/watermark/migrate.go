@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path"
+	"sync/atomic"
+	"syscall"
+	"watermark-service/internal"
+	"watermark-service/internal/watermark"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// migrateBatch documents are loaded at once
+const migrateBatch = 100
+
+var errChecksum = errors.New("checksum mismatch")
+
+// migrator copies the documents from one storage backend to another. The documents are walked in
+// the order of their IDs, a copied document records the new backend so a rerun skips it.
+type migrator struct {
+	db       *gorm.DB
+	storages *watermark.Storages
+	from     string
+	to       string
+	src      watermark.Storage
+	dst      watermark.Storage
+	dryRun   bool
+	remove   bool
+	log      *zap.Logger
+
+	copied, skipped, failed atomic.Int64
+}
+
+// migrate runs the migrate subcommand, e.g. watermark -config watermark.yaml migrate -from cloudinary -to s3
+func migrate(dbConnection internal.DatabaseConnectionStr, httpAddr string, args []string) error {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	from := fs.String("from", "", "storage to copy the documents from")
+	to := fs.String("to", "", "storage to copy the documents to")
+	workers := fs.Int("workers", 4, "documents copied concurrently")
+	dryRun := fs.Bool("dry-run", false, "only list the documents to copy")
+	remove := fs.Bool("delete", false, "delete the source copies once the documents are copied")
+	fs.Parse(args)
+	if *from == "" || *to == "" || *from == *to {
+		return errors.New("distinct -from and -to storages are required")
+	}
+
+	storages, err := newStorages(httpAddr, *from, *to)
+	if err != nil {
+		return err
+	}
+	src, ok := storages.Backend(*from)
+	if !ok {
+		return fmt.Errorf("unknown storage %q", *from)
+	}
+	dst, ok := storages.Backend(*to)
+	if !ok {
+		return fmt.Errorf("unknown storage %q", *to)
+	}
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: dbConnection.GetDSN()}))
+	if err != nil {
+		return err
+	}
+	if !*dryRun {
+		if err := watermark.InitDb(db); err != nil {
+			return err
+		}
+	}
+	m := &migrator{
+		db:       db,
+		storages: storages,
+		from:     *from,
+		to:       *to,
+		src:      src,
+		dst:      dst,
+		dryRun:   *dryRun,
+		remove:   *remove,
+		log:      zap.L().With(zap.String("Migrate", *from+" -> "+*to)),
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	err = m.run(ctx, max(1, *workers))
+	m.log.Info("Done", zap.Int64("Copied", m.copied.Load()), zap.Int64("Skipped", m.skipped.Load()),
+		zap.Int64("Failed", m.failed.Load()), zap.Bool("Dry run", m.dryRun))
+	if err == nil && m.failed.Load() > 0 {
+		err = fmt.Errorf("%d documents failed, rerun to retry them", m.failed.Load())
+	}
+	return err
+}
+
+func (m *migrator) run(ctx context.Context, workers int) error {
+	var last uuid.UUID
+	for ctx.Err() == nil {
+		var docs []watermark.Document
+		res := m.db.Where("id > ?", last).Order("id").Limit(migrateBatch).Find(&docs)
+		if res.Error != nil {
+			return res.Error
+		}
+		if len(docs) == 0 {
+			return nil
+		}
+		last = docs[len(docs)-1].ID
+		internal.ForEach(len(docs), workers, func(i int) {
+			if ctx.Err() != nil {
+				return
+			}
+			m.migrate(ctx, docs[i])
+		})
+	}
+	return ctx.Err()
+}
+
+func (m *migrator) migrate(ctx context.Context, doc watermark.Document) {
+	locations := doc.Locations
+	if len(locations) == 0 {
+		locations = watermark.Locations{m.storages.Primary(): doc.ImageUrl}
+	}
+	srcURL, ok := locations[m.from]
+	if _, done := locations[m.to]; done || !ok {
+		m.skipped.Add(1)
+		return
+	}
+	log := m.log.With(zap.String("Document", doc.ID.String()), zap.String("URL", srcURL))
+	if m.dryRun {
+		log.Info("Would copy")
+		m.copied.Add(1)
+		return
+	}
+	if err := m.copy(ctx, doc, locations, srcURL); err != nil {
+		log.Error("Copy", zap.Error(err))
+		m.failed.Add(1)
+		return
+	}
+	m.copied.Add(1)
+}
+
+// copy uploads the document to the destination and verifies the copy against the checksum of the
+// source, then points the document to the copy unless it changed meanwhile.
+func (m *migrator) copy(ctx context.Context, doc watermark.Document, locations watermark.Locations, srcURL string) error {
+	r, err := m.src.Open(ctx, srcURL)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	hash := sha256.New()
+	url, err := m.dst.Upload(ctx, path.Base(srcURL), io.TeeReader(r, hash))
+	if err != nil {
+		return err
+	}
+	if err := m.verify(ctx, url, hash.Sum(nil)); err != nil {
+		m.discard(ctx, url)
+		return err
+	}
+
+	moved := watermark.Locations{m.to: url}
+	for name, location := range locations {
+		if name != m.from || !m.remove {
+			moved[name] = location
+		}
+	}
+	res := m.db.Model(&watermark.Document{}).
+		Where("id = ? AND image_url = ?", doc.ID, doc.ImageUrl).
+		Select("image_url", "locations").
+		Updates(&watermark.Document{ImageUrl: url, Locations: moved})
+	if res.Error == nil && res.RowsAffected == 0 {
+		res.Error = errors.New("document changed during the copy")
+	}
+	if res.Error != nil {
+		m.discard(ctx, url)
+		return res.Error
+	}
+	if m.remove {
+		if err := m.src.Delete(ctx, srcURL); err != nil {
+			m.log.Warn("Delete", zap.String("Document", doc.ID.String()), zap.String("URL", srcURL), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+func (m *migrator) verify(ctx context.Context, url string, sum []byte) error {
+	r, err := m.dst.Open(ctx, url)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, r); err != nil {
+		return err
+	}
+	if !bytes.Equal(hash.Sum(nil), sum) {
+		return errChecksum
+	}
+	return nil
+}
+
+// discard deletes a copy that isn't referenced by its document.
+func (m *migrator) discard(ctx context.Context, url string) {
+	if err := m.dst.Delete(context.WithoutCancel(ctx), url); err != nil {
+		m.log.Warn("Delete", zap.String("URL", url), zap.Error(err))
+	}
+}
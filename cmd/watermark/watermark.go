@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"net"
@@ -11,6 +12,8 @@ import (
 	"time"
 	"watermark-service/config"
 	"watermark-service/internal"
+	"watermark-service/internal/util"
+	"watermark-service/internal/watermark"
 	watermarksvc "watermark-service/pkg/watermark"
 	"watermark-service/pkg/watermark/endpoints"
 	"watermark-service/pkg/watermark/transport"
@@ -53,9 +56,51 @@ func main() {
 	}
 	defer closer.Close()
 
+	if flag.Arg(0) == "migrate" {
+		err := migrate(connectionStr, httpAddr, flag.Args()[1:])
+		closer.Close()
+		if err != nil {
+			zap.L().Fatal("Migrate", zap.Error(err))
+		}
+		return
+	}
+
+	util.SetLimits(util.ImageLimits{
+		MaxBytes:      cfg.Limits.MaxBytes << 20,
+		MaxWidth:      cfg.Limits.MaxWidth,
+		MaxHeight:     cfg.Limits.MaxHeight,
+		MaxMegapixels: cfg.Limits.MaxMegapixels,
+	})
+
+	storage, err := newStorages(httpAddr)
+	if err != nil {
+		zap.L().Fatal("Setup failed", zap.String("Storage", "init"), zap.Error(err))
+	}
+
 	var service watermarksvc.Service
 	{
-		service = watermarksvc.NewService(connectionStr, pictureSvcAddr, cfg.Cloudinary.Cloud, cfg.Cloudinary.Api, cfg.Cloudinary.Secret)
+		tiling := watermarksvc.Tiling{
+			Replicas:  cfg.Tiling.Replicas,
+			Threshold: cfg.Tiling.Threshold,
+			TileSize:  cfg.Tiling.TileSize,
+		}
+		jobs := watermarksvc.Jobs{
+			Workers:     cfg.Jobs.Workers,
+			QueueSize:   cfg.Jobs.QueueSize,
+			MaxAttempts: cfg.Jobs.MaxAttempts,
+			Lease:       cfg.Jobs.Lease,
+			Backoff:     cfg.Jobs.Backoff,
+			Admins:      cfg.Jobs.Admins,
+		}
+		links := watermarksvc.Links{
+			BaseURL: cfg.Links.BaseURL,
+			Secrets: cfg.Links.Secrets,
+			TTL:     cfg.Links.TTL,
+		}
+		if links.BaseURL == "" {
+			links.BaseURL = "http://" + httpAddr
+		}
+		service = watermarksvc.NewService(connectionStr, pictureSvcAddr, tiling, jobs, links, storage)
 		service = watermarksvc.AuthMiddleware(authSvcAddr)(service)
 	}
 
@@ -85,7 +130,10 @@ func main() {
 		}
 		g.Add(func() error {
 			zap.L().Info("transport", zap.String("gRPC", "Listener"), zap.String("address", grpcAddr))
-			baseServer := grpc.NewServer(grpc.UnaryInterceptor(grpckit.Interceptor))
+			baseServer := grpc.NewServer(
+				grpc.UnaryInterceptor(grpckit.Interceptor),
+				grpc.MaxRecvMsgSize(watermarksvc.MaxMessageSize),
+			)
 			reflection.Register(baseServer)
 			proto.RegisterWatermarkServer(baseServer, grpcServer)
 			return baseServer.Serve(grpcListener)
@@ -112,6 +160,74 @@ func main() {
 	zap.L().Info("exit", zap.Error(err))
 }
 
+// newStorages creates the primary and secondary storage backends, the extra ones and the ones declared in the
+// config file, the backends declared by env are named after their types.
+func newStorages(httpAddr string, extra ...string) (*watermark.Storages, error) {
+	declared := map[string]config.StorageBackend{}
+	for _, typ := range []string{"cloudinary", "filesystem", "s3"} {
+		backend := cfg.Storage.StorageBackend
+		backend.Type = typ
+		backend.Cloudinary.Cloud, backend.Cloudinary.Api, backend.Cloudinary.Secret = cfg.Cloudinary.Cloud, cfg.Cloudinary.Api, cfg.Cloudinary.Secret
+		declared[typ] = backend
+	}
+	for name, backend := range cfg.Storage.Backends {
+		declared[name] = backend
+	}
+	primary := cfg.Storage.Primary
+	if primary == "" {
+		primary = cfg.Storage.Type
+	}
+	if primary == "" {
+		primary = "cloudinary"
+	}
+	used := append([]string{primary, cfg.Storage.Secondary}, extra...)
+	for name := range cfg.Storage.Backends {
+		used = append(used, name)
+	}
+	backends := map[string]watermark.Storage{}
+	for _, name := range used {
+		backend, ok := declared[name]
+		if _, done := backends[name]; done || !ok {
+			continue
+		}
+		storage, err := newStorage(backend, httpAddr)
+		if err != nil {
+			return nil, fmt.Errorf("storage %s: %w", name, err)
+		}
+		backends[name] = storage
+	}
+	return watermark.NewStorages(backends, primary, cfg.Storage.Secondary, cfg.Storage.Mirror)
+}
+
+func newStorage(backend config.StorageBackend, httpAddr string) (watermark.Storage, error) {
+	switch backend.Type {
+	case "filesystem":
+		baseURL := backend.Filesystem.BaseURL
+		if baseURL == "" {
+			baseURL = "http://" + httpAddr
+		}
+		return watermark.NewFilesystemStorage(backend.Filesystem.Root, baseURL)
+	case "s3":
+		return watermark.NewS3Storage(watermark.S3{
+			Bucket:    backend.S3.Bucket,
+			Prefix:    backend.S3.Prefix,
+			Region:    backend.S3.Region,
+			Endpoint:  backend.S3.Endpoint,
+			PathStyle: backend.S3.PathStyle,
+			AccessKey: backend.S3.AccessKey,
+			SecretKey: backend.S3.SecretKey,
+			PartSize:  backend.S3.PartSize,
+			SSE:       backend.S3.SSE,
+			KMSKeyID:  backend.S3.KMSKeyID,
+			PublicURL: backend.S3.PublicURL,
+		})
+	case "cloudinary":
+		return watermark.NewCloudinaryStorage(backend.Cloudinary.Cloud, backend.Cloudinary.Api, backend.Cloudinary.Secret)
+	default:
+		return nil, errors.New("unknown storage type " + backend.Type)
+	}
+}
+
 func init() {
 	var conf, logf string
 	flag.StringVar(&conf, "config", "", "config file")
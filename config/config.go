@@ -1,5 +1,7 @@
 package config
 
+import "time"
+
 type AuthenticationConfig struct {
 	HTTPAddress struct {
 		Port string `yaml:"port" envconfig:"HTTP_PORT"`
@@ -40,6 +42,13 @@ type WatermarkConfig struct {
 		Api    string `yaml:"api" envconfig:"CLOUDINARY_API"`
 		Secret string `yaml:"secret" envconfig:"CLOUDINARY_SECRET"`
 	} `yaml:"cloudinary"`
+	Storage struct {
+		StorageBackend `yaml:",inline"`
+		Primary        string                    `yaml:"primary" envconfig:"STORAGE_PRIMARY"`
+		Secondary      string                    `yaml:"secondary" envconfig:"STORAGE_SECONDARY"`
+		Mirror         bool                      `yaml:"mirror" envconfig:"STORAGE_MIRROR"`
+		Backends       map[string]StorageBackend `yaml:"backends" ignored:"true"`
+	} `yaml:"storage"`
 	Services struct {
 		Auth struct {
 			Port string `yaml:"port" envconfig:"AUTH_PORT"`
@@ -50,12 +59,64 @@ type WatermarkConfig struct {
 			Host string `yaml:"host" envconfig:"PICTURE_HOST"`
 		} `yaml:"picture"`
 	} `yaml:"services"`
+	Tiling struct {
+		Replicas  []string `yaml:"replicas" envconfig:"PICTURE_REPLICAS"`
+		Threshold float64  `yaml:"threshold" envconfig:"TILING_THRESHOLD"`
+		TileSize  float64  `yaml:"tile_size" envconfig:"TILE_SIZE"`
+	} `yaml:"tiling"`
+	Jobs struct {
+		Workers     int           `yaml:"workers" envconfig:"JOB_WORKERS"`
+		QueueSize   int           `yaml:"queue_size" envconfig:"JOB_QUEUE_SIZE"`
+		MaxAttempts int           `yaml:"max_attempts" envconfig:"JOB_MAX_ATTEMPTS"`
+		Lease       time.Duration `yaml:"lease" envconfig:"JOB_LEASE"`
+		Backoff     time.Duration `yaml:"backoff" envconfig:"JOB_BACKOFF"`
+		Admins      []string      `yaml:"admins" envconfig:"JOB_ADMINS"`
+	} `yaml:"jobs"`
+	Links struct {
+		BaseURL string        `yaml:"base_url" envconfig:"LINK_BASE_URL"`
+		Secrets []string      `yaml:"secrets" envconfig:"LINK_SECRETS"`
+		TTL     time.Duration `yaml:"ttl" envconfig:"LINK_TTL"`
+	} `yaml:"links"`
+	Limits struct {
+		MaxBytes      int64   `yaml:"max_bytes" envconfig:"MAX_IMAGE_BYTES"`
+		MaxWidth      int     `yaml:"max_width" envconfig:"MAX_IMAGE_WIDTH"`
+		MaxHeight     int     `yaml:"max_height" envconfig:"MAX_IMAGE_HEIGHT"`
+		MaxMegapixels float64 `yaml:"max_megapixels" envconfig:"MAX_IMAGE_MEGAPIXELS"`
+	} `yaml:"limits"`
 	JaegerAddress struct {
 		Port string `yaml:"port" envconfig:"JAEGER_PORT"`
 		Host string `yaml:"host" envconfig:"JAEGER_HOST"`
 	} `yaml:"jaeger"`
 }
 
+// StorageBackend is a storage backend of the watermark service, Type is cloudinary, filesystem or s3.
+// The Cloudinary credentials of the backends declared by env are the top-level ones.
+type StorageBackend struct {
+	Type       string `yaml:"type" envconfig:"STORAGE"`
+	Cloudinary struct {
+		Cloud  string `yaml:"cloud"`
+		Api    string `yaml:"api"`
+		Secret string `yaml:"secret"`
+	} `yaml:"cloudinary" ignored:"true"`
+	Filesystem struct {
+		Root    string `yaml:"root" envconfig:"FS_ROOT"`
+		BaseURL string `yaml:"base_url" envconfig:"FS_BASE_URL"`
+	} `yaml:"filesystem"`
+	S3 struct {
+		Bucket    string `yaml:"bucket" envconfig:"S3_BUCKET"`
+		Prefix    string `yaml:"prefix" envconfig:"S3_PREFIX"`
+		Region    string `yaml:"region" envconfig:"S3_REGION"`
+		Endpoint  string `yaml:"endpoint" envconfig:"S3_ENDPOINT"`
+		PathStyle bool   `yaml:"path_style" envconfig:"S3_PATH_STYLE"`
+		AccessKey string `yaml:"access_key" envconfig:"S3_ACCESS_KEY"`
+		SecretKey string `yaml:"secret_key" envconfig:"S3_SECRET_KEY"`
+		PartSize  uint64 `yaml:"part_size" envconfig:"S3_PART_SIZE"`
+		SSE       string `yaml:"sse" envconfig:"S3_SSE"`
+		KMSKeyID  string `yaml:"kms_key_id" envconfig:"S3_KMS_KEY_ID"`
+		PublicURL string `yaml:"public_url" envconfig:"S3_PUBLIC_URL"`
+	} `yaml:"s3"`
+}
+
 type PictureConfig struct {
 	HTTPAddress struct {
 		Port string `yaml:"port" envconfig:"HTTP_PORT"`
@@ -69,4 +130,25 @@ type PictureConfig struct {
 		Port string `yaml:"port" envconfig:"JAEGER_PORT"`
 		Host string `yaml:"host" envconfig:"JAEGER_HOST"`
 	} `yaml:"jaeger"`
+	Fonts         []string `yaml:"fonts" envconfig:"FONTS"`
+	FontSize      float64  `yaml:"font_size" envconfig:"FONT_SIZE"`
+	Interpolation string   `yaml:"interpolation" envconfig:"INTERPOLATION"`
+	MemoryLimit   int64    `yaml:"memory_limit" envconfig:"MEMORY_LIMIT"`
+	Workers       int      `yaml:"workers" envconfig:"WORKERS"`
+	Cache         struct {
+		Disabled bool  `yaml:"disabled" envconfig:"CACHE_DISABLED"`
+		Entries  int   `yaml:"entries" envconfig:"CACHE_ENTRIES"`
+		Size     int64 `yaml:"size" envconfig:"CACHE_SIZE"`
+	} `yaml:"cache"`
+	Concurrency struct {
+		MaxRenders   int           `yaml:"max_renders" envconfig:"MAX_RENDERS"`
+		QueueSize    int           `yaml:"queue_size" envconfig:"QUEUE_SIZE"`
+		QueueTimeout time.Duration `yaml:"queue_timeout" envconfig:"QUEUE_TIMEOUT"`
+	} `yaml:"concurrency"`
+	Limits struct {
+		MaxBytes      int64   `yaml:"max_bytes" envconfig:"MAX_IMAGE_BYTES"`
+		MaxWidth      int     `yaml:"max_width" envconfig:"MAX_IMAGE_WIDTH"`
+		MaxHeight     int     `yaml:"max_height" envconfig:"MAX_IMAGE_HEIGHT"`
+		MaxMegapixels float64 `yaml:"max_megapixels" envconfig:"MAX_IMAGE_MEGAPIXELS"`
+	} `yaml:"limits"`
 }
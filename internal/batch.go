@@ -0,0 +1,31 @@
+package internal
+
+import "sync"
+
+// BatchItem is the outcome for one image of a batch, a failed item keeps its error and doesn't fail the others.
+type BatchItem struct {
+	Image    Blob   `json:"-"`
+	TicketID string `json:"ticketID,omitempty"`
+	Err      string `json:"err,omitempty"`
+}
+
+// ForEach calls fn for every index below n using at most workers goroutines.
+func ForEach(n, workers int, fn func(i int)) {
+	workers = max(1, min(workers, n))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
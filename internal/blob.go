@@ -0,0 +1,12 @@
+package internal
+
+// Blob is an encoded image along with its format (".png", ".jpg" or ".svg"). The services pass
+// blobs around as is, so an image is decoded only where its pixels are needed.
+type Blob struct {
+	Data   []byte `json:"-"`
+	Format string `json:"format,omitempty"`
+}
+
+func (b Blob) Empty() bool {
+	return len(b.Data) == 0
+}
@@ -0,0 +1,123 @@
+package internal
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+	"image"
+	"sync"
+	"sync/atomic"
+)
+
+// OverlayCache keeps the most recently used watermark overlays, bounded both by the number of
+// entries and by the memory their pixels take. It is safe for concurrent use, cached overlays
+// are shared and must not be modified.
+type OverlayCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	maxBytes   int64
+	bytes      int64
+	order      *list.List
+	entries    map[string]*list.Element
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+type CacheStats struct {
+	Hits    int64 `json:"hits"`
+	Misses  int64 `json:"misses"`
+	Entries int   `json:"entries"`
+	Bytes   int64 `json:"bytes"`
+}
+
+type cacheEntry struct {
+	key   string
+	img   image.Image
+	bytes int64
+}
+
+func NewOverlayCache(maxEntries int, maxBytes int64) *OverlayCache {
+	return &OverlayCache{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+func (c *OverlayCache) Get(key string) (image.Image, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		c.misses.Add(1)
+		return nil, false
+	}
+	c.hits.Add(1)
+	c.order.MoveToFront(el)
+	return el.Value.(*cacheEntry).img, true
+}
+
+// Add stores the overlay evicting the least recently used ones, overlays bigger than the whole cache are skipped.
+func (c *OverlayCache) Add(key string, img image.Image) {
+	size := int64(img.Bounds().Dx()) * int64(img.Bounds().Dy()) * 4
+	if size > c.maxBytes {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, img: img, bytes: size})
+	c.bytes += size
+	for c.order.Len() > c.maxEntries || c.bytes > c.maxBytes {
+		last := c.order.Back()
+		entry := last.Value.(*cacheEntry)
+		c.order.Remove(last)
+		delete(c.entries, entry.key)
+		c.bytes -= entry.bytes
+	}
+}
+
+func (c *OverlayCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return CacheStats{
+		Hits:    c.hits.Load(),
+		Misses:  c.misses.Load(),
+		Entries: c.order.Len(),
+		Bytes:   c.bytes,
+	}
+}
+
+// OverlayKey identifies the overlay made of the logo and the text rendered with the given options.
+// The logo is hashed as uploaded, so the cache is looked up before the logo is decoded.
+func OverlayKey(logo Blob, text string, opts LogoOptions, interp Interpolation) string {
+	h := sha256.New()
+	writeString(h, text)
+	writeString(h, opts.KeyColor)
+	writeString(h, opts.Tint)
+	writeString(h, string(interp))
+	h.Write([]byte{opts.KeyTolerance, boolByte(opts.Invert), boolByte(opts.Grayscale)})
+	writeString(h, logo.Format)
+	binary.Write(h, binary.LittleEndian, int64(len(logo.Data)))
+	h.Write(logo.Data)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func writeString(h hash.Hash, s string) {
+	binary.Write(h, binary.LittleEndian, int64(len(s)))
+	h.Write([]byte(s))
+}
+
+func boolByte(b bool) byte {
+	if b {
+		return 1
+	}
+	return 0
+}
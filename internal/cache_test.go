@@ -0,0 +1,57 @@
+package internal
+
+import (
+	"image"
+	"testing"
+)
+
+func TestOverlayKey(t *testing.T) {
+	logo := Blob{Data: []byte{1, 2, 3}, Format: ".png"}
+	base := OverlayKey(logo, "text", LogoOptions{}, Lanczos)
+	tests := []struct {
+		name   string
+		logo   Blob
+		text   string
+		opts   LogoOptions
+		interp Interpolation
+	}{
+		{"logo bytes", Blob{Data: []byte{1, 2, 4}, Format: ".png"}, "text", LogoOptions{}, Lanczos},
+		{"logo format", Blob{Data: []byte{1, 2, 3}, Format: ".jpg"}, "text", LogoOptions{}, Lanczos},
+		{"no logo", Blob{}, "text", LogoOptions{}, Lanczos},
+		{"text", logo, "other", LogoOptions{}, Lanczos},
+		{"text moved into the logo", Blob{Data: []byte{1, 2, 3}, Format: ".pngt"}, "ext", LogoOptions{}, Lanczos},
+		{"key color", logo, "text", LogoOptions{KeyColor: "#fff"}, Lanczos},
+		{"tolerance", logo, "text", LogoOptions{KeyTolerance: 1}, Lanczos},
+		{"grayscale", logo, "text", LogoOptions{Grayscale: true}, Lanczos},
+		{"interpolation", logo, "text", LogoOptions{}, NearestNeighbor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if OverlayKey(tt.logo, tt.text, tt.opts, tt.interp) == base {
+				t.Error("key doesn't change")
+			}
+		})
+	}
+	if OverlayKey(Blob{Data: []byte{1, 2, 3}, Format: ".png"}, "text", LogoOptions{}, Lanczos) != base {
+		t.Error("key of the same overlay changes")
+	}
+}
+
+func TestOverlayCacheEviction(t *testing.T) {
+	cache := NewOverlayCache(2, 3*4*100)
+	img := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	cache.Add("a", img)
+	cache.Add("b", img)
+	cache.Get("a")
+	cache.Add("c", img)
+	if _, ok := cache.Get("b"); ok {
+		t.Error("least recently used overlay wasn't evicted")
+	}
+	cache.Add("big", image.NewRGBA(image.Rect(0, 0, 100, 100)))
+	if _, ok := cache.Get("big"); ok {
+		t.Error("overlay bigger than the cache was added")
+	}
+	if stats := cache.Stats(); stats.Entries != 2 || stats.Bytes != 800 {
+		t.Errorf("Stats() = %+v", stats)
+	}
+}
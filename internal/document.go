@@ -1,6 +1,9 @@
 package internal
 
 import (
+	"io"
+	"time"
+
 	uuid "github.com/google/uuid"
 )
 
@@ -11,6 +14,27 @@ type Document struct {
 	ImageUrl string    `json:"image_url"`
 }
 
+// Download is the content of a document, the receiver closes it.
+type Download struct {
+	Name        string
+	ContentType string
+	Content     io.ReadCloser
+}
+
+// ShareLink opens a document without an account until it expires, runs out of views or is revoked.
+// MaxViews is 0 when the views are unlimited, Protected links ask for a password.
+type ShareLink struct {
+	ID         uuid.UUID  `json:"id"`
+	DocumentID uuid.UUID  `json:"document_id"`
+	URL        string     `json:"url"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	MaxViews   int32      `json:"max_views,omitempty"`
+	Views      int32      `json:"views"`
+	Protected  bool       `json:"protected"`
+	Revoked    bool       `json:"revoked"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
 type Filter struct {
 	Key   string `json:"key"`
 	Value string `json:"value,omitempty"`
@@ -24,4 +48,18 @@ const (
 	InProgress Status = "InProgress"
 	Finished   Status = "Finished"
 	Failed     Status = "Failed"
+	// DeadLetter jobs ran out of attempts, an admin may requeue them.
+	DeadLetter Status = "DeadLetter"
 )
+
+// Job reports the progress of an asynchronous Add, the ticket returned by Add is its ID.
+type Job struct {
+	ID       uuid.UUID         `json:"id"`
+	Status   Status            `json:"status"`
+	Progress int32             `json:"progress"`
+	Attempts int32             `json:"attempts,omitempty"`
+	AuthorId int32             `json:"author_id,omitempty"`
+	Err      string            `json:"error,omitempty"`
+	ImageUrl string            `json:"image_url,omitempty"`
+	Outputs  map[string]string `json:"outputs,omitempty"`
+}
@@ -0,0 +1,106 @@
+package internal
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// Limiter bounds the number of concurrent renders. The callers over the limit wait in a bounded
+// queue and are turned away when the queue is full or they have waited longer than the timeout.
+// It is safe for concurrent use.
+type Limiter struct {
+	slots   chan struct{}
+	queue   chan struct{}
+	timeout time.Duration
+
+	rejected  atomic.Int64
+	waits     atomic.Int64
+	waited    atomic.Int64
+	completed atomic.Int64
+	busy      atomic.Int64
+}
+
+type LoadStats struct {
+	InFlight    int     `json:"in_flight"`
+	MaxInFlight int     `json:"max_in_flight"`
+	Queued      int     `json:"queued"`
+	MaxQueued   int     `json:"max_queued"`
+	Rejected    int64   `json:"rejected"`
+	AvgWaitMs   float64 `json:"avg_wait_ms"`
+}
+
+func NewLimiter(maxInFlight, maxQueued int, timeout time.Duration) *Limiter {
+	return &Limiter{
+		slots:   make(chan struct{}, max(1, maxInFlight)),
+		queue:   make(chan struct{}, max(0, maxQueued)),
+		timeout: timeout,
+	}
+}
+
+// Acquire takes a render slot waiting for it in the queue if needed, the returned function releases
+// the slot. It reports false when the request is rejected or its context is done while waiting.
+func (l *Limiter) Acquire(ctx context.Context) (func(), bool) {
+	select {
+	case l.slots <- struct{}{}:
+		return l.releaser(time.Now()), true
+	default:
+	}
+	select {
+	case l.queue <- struct{}{}:
+	default:
+		l.rejected.Add(1)
+		return nil, false
+	}
+	defer func() { <-l.queue }()
+	start := time.Now()
+	timer := time.NewTimer(l.timeout)
+	defer timer.Stop()
+	select {
+	case l.slots <- struct{}{}:
+		l.waits.Add(1)
+		l.waited.Add(int64(time.Since(start)))
+		return l.releaser(time.Now()), true
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+	l.rejected.Add(1)
+	return nil, false
+}
+
+func (l *Limiter) releaser(start time.Time) func() {
+	var once atomic.Bool
+	return func() {
+		if once.Swap(true) {
+			return
+		}
+		l.completed.Add(1)
+		l.busy.Add(int64(time.Since(start)))
+		<-l.slots
+	}
+}
+
+// RetryAfter estimates when a rejected request may be admitted, from the average render time
+// and the number of requests ahead of it.
+func (l *Limiter) RetryAfter() time.Duration {
+	avg := time.Second
+	if n := l.completed.Load(); n > 0 {
+		avg = time.Duration(l.busy.Load() / n)
+	}
+	ahead := len(l.queue)/cap(l.slots) + 1
+	return max(time.Second, (avg * time.Duration(ahead)).Round(time.Second))
+}
+
+func (l *Limiter) Stats() LoadStats {
+	stats := LoadStats{
+		InFlight:    len(l.slots),
+		MaxInFlight: cap(l.slots),
+		Queued:      len(l.queue),
+		MaxQueued:   cap(l.queue),
+		Rejected:    l.rejected.Load(),
+	}
+	if n := l.waits.Load(); n > 0 {
+		stats.AvgWaitMs = float64(l.waited.Load()) / float64(n) / float64(time.Millisecond)
+	}
+	return stats
+}
@@ -0,0 +1,157 @@
+package internal
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+	"sync"
+)
+
+type LogoOptions struct {
+	KeyColor     string `json:"key_color,omitempty"`
+	KeyTolerance uint8  `json:"key_tolerance,omitempty"`
+	Tint         string `json:"tint,omitempty"`
+	Invert       bool   `json:"invert,omitempty"`
+	Grayscale    bool   `json:"grayscale,omitempty"`
+
+	Interpolation string `json:"interpolation,omitempty"`
+}
+
+func (o LogoOptions) preprocessing() bool {
+	return o.KeyColor != "" || o.Tint != "" || o.Invert || o.Grayscale
+}
+
+// ParseHexColor accepts colors in "#rgb", "#rrggbb" and "#rrggbbaa" forms, the leading "#" is optional.
+func ParseHexColor(s string) (color.NRGBA, error) {
+	c := color.NRGBA{A: 255}
+	s = strings.TrimPrefix(s, "#")
+	var err error
+	switch len(s) {
+	case 3:
+		_, err = fmt.Sscanf(s, "%1x%1x%1x", &c.R, &c.G, &c.B)
+		c.R, c.G, c.B = c.R*17, c.G*17, c.B*17
+	case 6:
+		_, err = fmt.Sscanf(s, "%2x%2x%2x", &c.R, &c.G, &c.B)
+	case 8:
+		_, err = fmt.Sscanf(s, "%2x%2x%2x%2x", &c.R, &c.G, &c.B, &c.A)
+	default:
+		err = fmt.Errorf("invalid color %q", s)
+	}
+	return c, err
+}
+
+// PrepareLogo applies the preprocessing steps in a fixed order:
+// background keying, grayscale, inversion and finally tinting.
+func PrepareLogo(logo image.Image, opts LogoOptions) (image.Image, error) {
+	if logo == nil || !opts.preprocessing() {
+		return logo, nil
+	}
+	key, tint, err := opts.colors()
+	if err != nil {
+		return nil, err
+	}
+	if vector, ok := logo.(VectorImage); ok {
+		return &preparedVector{VectorImage: vector, opts: opts}, nil
+	}
+	var (
+		bounds          = logo.Bounds()
+		res             = image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+		tolerance, edge = int(opts.KeyTolerance), int(opts.KeyTolerance)/2 + 1
+	)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(logo.At(x, y)).(color.NRGBA)
+			if key != nil {
+				// pixels close to the key color are cut out, the ones slightly further are faded
+				// so the logo doesn't get a hard jagged outline
+				d := colorDistance(c, *key)
+				if d <= tolerance {
+					c.A = 0
+				} else if d < tolerance+edge {
+					c.A = uint8(int(c.A) * (d - tolerance) / edge)
+				}
+			}
+			if opts.Grayscale {
+				l := uint8((299*int(c.R) + 587*int(c.G) + 114*int(c.B)) / 1000)
+				c.R, c.G, c.B = l, l, l
+			}
+			if opts.Invert {
+				c.R, c.G, c.B = 255-c.R, 255-c.G, 255-c.B
+			}
+			if tint != nil {
+				c.R, c.G, c.B = tint.R, tint.G, tint.B
+				c.A = uint8(int(c.A) * int(tint.A) / 255)
+			}
+			res.SetNRGBA(x-bounds.Min.X, y-bounds.Min.Y, c)
+		}
+	}
+	return res, nil
+}
+
+// Validate checks the colors and the interpolation of the options.
+func (o LogoOptions) Validate() error {
+	if _, _, err := o.colors(); err != nil {
+		return err
+	}
+	_, err := ParseInterpolation(o.Interpolation)
+	return err
+}
+
+func (o LogoOptions) colors() (key, tint *color.NRGBA, err error) {
+	if o.KeyColor != "" {
+		c, err := ParseHexColor(o.KeyColor)
+		if err != nil {
+			return nil, nil, err
+		}
+		key = &c
+	}
+	if o.Tint != "" {
+		c, err := ParseHexColor(o.Tint)
+		if err != nil {
+			return nil, nil, err
+		}
+		tint = &c
+	}
+	return key, tint, nil
+}
+
+// preparedVector postpones preprocessing of vector logos until they are rasterized at the final size.
+type preparedVector struct {
+	VectorImage
+	opts LogoOptions
+
+	once    sync.Once
+	natural image.Image
+}
+
+func (v *preparedVector) Rasterize(width, height int) image.Image {
+	img, _ := PrepareLogo(v.VectorImage.Rasterize(width, height), v.opts)
+	return img
+}
+
+func (v *preparedVector) At(x, y int) color.Color {
+	v.once.Do(func() {
+		b := v.Bounds()
+		v.natural = v.Rasterize(b.Dx(), b.Dy())
+	})
+	return v.natural.At(x, y)
+}
+
+func colorDistance(a, b color.NRGBA) int {
+	d := absDiff(a.R, b.R)
+	if g := absDiff(a.G, b.G); g > d {
+		d = g
+	}
+	if bl := absDiff(a.B, b.B); bl > d {
+		d = bl
+	}
+	return d
+}
+
+func absDiff(a, b uint8) int {
+	if a > b {
+		return int(a - b)
+	}
+	return int(b - a)
+}
@@ -0,0 +1,233 @@
+package internal
+
+import (
+	"fmt"
+	"image"
+	"strconv"
+	"strings"
+	"watermark-service/internal/util"
+)
+
+type OperationType string
+
+const (
+	ResizeOperation    OperationType = "resize"
+	CropOperation      OperationType = "crop"
+	PadOperation       OperationType = "pad"
+	SmartCropOperation OperationType = "smart_crop"
+	RotateOperation    OperationType = "rotate"
+	FlipOperation      OperationType = "flip"
+	AdjustOperation    OperationType = "adjust"
+	WatermarkOperation OperationType = "watermark"
+	RedactOperation    OperationType = "redact"
+	ConvertOperation   OperationType = "convert"
+)
+
+// Operation is a single step of the picture processing pipeline, only the fields
+// relevant to its type are taken into account.
+type Operation struct {
+	Type OperationType `json:"type"`
+
+	// resize, crop, pad and redact
+	X             int    `json:"x,omitempty"`
+	Y             int    `json:"y,omitempty"`
+	Width         int    `json:"width,omitempty"`
+	Height        int    `json:"height,omitempty"`
+	Interpolation string `json:"interpolation,omitempty"`
+	// smart_crop, "16:9" like ratio
+	Aspect string `json:"aspect,omitempty"`
+	// rotate, clockwise multiple of 90
+	Angle int `json:"angle,omitempty"`
+	// flip, "horizontal" or "vertical"
+	Direction string `json:"direction,omitempty"`
+	// adjust, both in [-1, 1]
+	Brightness float64 `json:"brightness,omitempty"`
+	Contrast   float64 `json:"contrast,omitempty"`
+	// watermark
+	Text        string      `json:"text,omitempty"`
+	Fill        bool        `json:"fill,omitempty"`
+	Pos         Position    `json:"pos,omitempty"`
+	LogoOptions LogoOptions `json:"logo_options,omitempty"`
+	// watermark, distance from the image edges for a positioned watermark
+	MarginX int `json:"margin_x,omitempty"`
+	MarginY int `json:"margin_y,omitempty"`
+	// redact, "fill" or "pixelate"; the color is used by both redact and pad
+	Mode  string `json:"mode,omitempty"`
+	Color string `json:"color,omitempty"`
+	// convert, "png" or "jpg"
+	Format  string `json:"format,omitempty"`
+	Quality int    `json:"quality,omitempty"`
+}
+
+// Encoding describes how the pipeline result is encoded, Format uses the same extensions as image types.
+type Encoding struct {
+	Format  string `json:"format"`
+	Quality int    `json:"quality,omitempty"`
+}
+
+var DefaultEncoding = Encoding{Format: ".png"}
+
+// FormatFromString normalizes the format names accepted from clients.
+func FormatFromString(format string) (string, error) {
+	switch strings.TrimPrefix(strings.ToLower(format), ".") {
+	case "png":
+		return ".png", nil
+	case "jpg", "jpeg":
+		return ".jpg", nil
+	}
+	return "", fmt.Errorf("unsupported format %q", format)
+}
+
+// maxAspect bounds both terms of an aspect ratio, so the crop sizes computed from them don't overflow.
+const maxAspect = 1 << 16
+
+// ParseAspect reads ratios written as "W:H".
+func ParseAspect(aspect string) (int, int, error) {
+	w, h, ok := strings.Cut(aspect, ":")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid aspect ratio %q", aspect)
+	}
+	aw, err := strconv.Atoi(strings.TrimSpace(w))
+	if err != nil || aw <= 0 || aw > maxAspect {
+		return 0, 0, fmt.Errorf("invalid aspect ratio %q", aspect)
+	}
+	ah, err := strconv.Atoi(strings.TrimSpace(h))
+	if err != nil || ah <= 0 || ah > maxAspect {
+		return 0, 0, fmt.Errorf("invalid aspect ratio %q", aspect)
+	}
+	return aw, ah, nil
+}
+
+// ValidateOperations checks the whole pipeline before anything is executed, so a mistake
+// in the last step doesn't waste the work done by the previous ones. The size of every intermediate
+// image is tracked from the size of the source and checked against util.Limits, an oversized one
+// results in util.ErrTooLarge.
+func ValidateOperations(ops []Operation, size image.Point, hasLogo bool) error {
+	if len(ops) == 0 {
+		return fmt.Errorf("no operations")
+	}
+	for i, op := range ops {
+		var err error
+		if size, err = op.validate(size, hasLogo); err != nil {
+			return fmt.Errorf("operation %d (%s): %w", i, op.Type, err)
+		}
+		if err := util.Limits.CheckDimensions(size.X, size.Y); err != nil {
+			return fmt.Errorf("operation %d (%s): %w", i, op.Type, err)
+		}
+		if op.Type == ConvertOperation && i != len(ops)-1 {
+			return fmt.Errorf("operation %d (%s): must be the last one", i, op.Type)
+		}
+	}
+	return nil
+}
+
+// Region is the part of an image of the given size covered by the crop or redact region, computed
+// without overflowing for huge regions.
+func (op Operation) Region(size image.Point) image.Rectangle {
+	if op.X < 0 || op.Y < 0 || op.X >= size.X || op.Y >= size.Y {
+		return image.Rectangle{}
+	}
+	return image.Rect(op.X, op.Y, op.X+min(op.Width, size.X-op.X), op.Y+min(op.Height, size.Y-op.Y))
+}
+
+// validate checks the operation applied to an image of the given size and returns the size of its result.
+func (op Operation) validate(size image.Point, hasLogo bool) (image.Point, error) {
+	switch op.Type {
+	case ResizeOperation:
+		if op.Width < 0 || op.Height < 0 || op.Width == 0 && op.Height == 0 {
+			return size, fmt.Errorf("width or height must be positive")
+		}
+		// the requested sides are checked before they're multiplied
+		if err := util.Limits.CheckDimensions(max(1, op.Width), max(1, op.Height)); err != nil {
+			return size, err
+		}
+		if _, err := ParseInterpolation(op.Interpolation); err != nil {
+			return size, err
+		}
+		return ResizedSize(size, op.Width, op.Height), nil
+	case CropOperation, RedactOperation:
+		if op.Width <= 0 || op.Height <= 0 || op.X < 0 || op.Y < 0 {
+			return size, fmt.Errorf("invalid region")
+		}
+		if op.Type == RedactOperation {
+			switch op.Mode {
+			case "", "fill":
+				if op.Color != "" {
+					if _, err := ParseHexColor(op.Color); err != nil {
+						return size, err
+					}
+				}
+			case "pixelate":
+			default:
+				return size, fmt.Errorf("unknown mode %q", op.Mode)
+			}
+			return size, nil
+		}
+		r := op.Region(size)
+		if r.Empty() {
+			return size, fmt.Errorf("region is outside of the image")
+		}
+		return r.Size(), nil
+	case PadOperation:
+		if op.Width <= 0 || op.Height <= 0 {
+			return size, fmt.Errorf("width and height must be positive")
+		}
+		if op.Color != "" {
+			if _, err := ParseHexColor(op.Color); err != nil {
+				return size, err
+			}
+		}
+		if _, err := ParseInterpolation(op.Interpolation); err != nil {
+			return size, err
+		}
+		return image.Pt(op.Width, op.Height), nil
+	case SmartCropOperation:
+		aw, ah, err := ParseAspect(op.Aspect)
+		if err != nil {
+			return size, err
+		}
+		cropped := SmartCropSize(size, aw, ah)
+		if cropped.X <= 0 || cropped.Y <= 0 {
+			return size, fmt.Errorf("aspect ratio %q leaves nothing of the image", op.Aspect)
+		}
+		return cropped, nil
+	case RotateOperation:
+		if op.Angle%90 != 0 {
+			return size, fmt.Errorf("angle must be a multiple of 90")
+		}
+		if op.Angle%180 != 0 {
+			return image.Pt(size.Y, size.X), nil
+		}
+	case FlipOperation:
+		if op.Direction != "horizontal" && op.Direction != "vertical" {
+			return size, fmt.Errorf("unknown direction %q", op.Direction)
+		}
+	case AdjustOperation:
+		if op.Brightness < -1 || op.Brightness > 1 || op.Contrast < -1 || op.Contrast > 1 {
+			return size, fmt.Errorf("brightness and contrast must be in [-1, 1]")
+		}
+	case WatermarkOperation:
+		if op.Text == "" && !hasLogo {
+			return size, fmt.Errorf("no data to insert")
+		}
+		if op.MarginX < 0 || op.MarginY < 0 {
+			return size, fmt.Errorf("margins must not be negative")
+		}
+		if _, err := ParseInterpolation(op.LogoOptions.Interpolation); err != nil {
+			return size, err
+		}
+		_, _, err := op.LogoOptions.colors()
+		return size, err
+	case ConvertOperation:
+		if _, err := FormatFromString(op.Format); err != nil {
+			return size, err
+		}
+		// 0 keeps the default quality
+		if op.Quality < 0 || op.Quality > 100 {
+			return size, fmt.Errorf("quality must be in [0, 100]")
+		}
+	default:
+		return size, fmt.Errorf("unknown operation")
+	}
+	return size, nil
+}
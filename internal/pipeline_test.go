@@ -0,0 +1,99 @@
+package internal
+
+import (
+	"errors"
+	"image"
+	"testing"
+	"watermark-service/internal/util"
+)
+
+func TestValidateOperations(t *testing.T) {
+	size := image.Pt(400, 300)
+	tests := []struct {
+		name    string
+		ops     []Operation
+		hasLogo bool
+		err     error
+		ok      bool
+	}{
+		{"empty", nil, false, nil, false},
+		{"resize", []Operation{{Type: ResizeOperation, Width: 800}}, false, nil, true},
+		{"resize without sides", []Operation{{Type: ResizeOperation}}, false, nil, false},
+		{"resize too wide", []Operation{{Type: ResizeOperation, Width: 100000, Height: 10}}, false, util.ErrTooLarge, false},
+		{"resize too many pixels", []Operation{{Type: ResizeOperation, Width: 25000, Height: 25000}}, false, util.ErrTooLarge, false},
+		{"resize keeping the ratio too large", []Operation{{Type: ResizeOperation, Height: 29000}}, false, util.ErrTooLarge, false},
+		{"resize with huge side", []Operation{{Type: ResizeOperation, Width: 1 << 62}}, false, util.ErrTooLarge, false},
+		{"pad too large", []Operation{{Type: PadOperation, Width: 100000, Height: 100000}}, false, util.ErrTooLarge, false},
+		{"pad", []Operation{{Type: PadOperation, Width: 500, Height: 500, Color: "#fff"}}, false, nil, true},
+		{"pad bad color", []Operation{{Type: PadOperation, Width: 500, Height: 500, Color: "white"}}, false, nil, false},
+		{"crop", []Operation{{Type: CropOperation, X: 10, Y: 10, Width: 100, Height: 100}}, false, nil, true},
+		{"crop huge region", []Operation{{Type: CropOperation, X: 10, Y: 10, Width: 1 << 62, Height: 1 << 62}}, false, nil, true},
+		{"crop outside", []Operation{{Type: CropOperation, X: 400, Y: 0, Width: 10, Height: 10}}, false, nil, false},
+		{"smart crop", []Operation{{Type: SmartCropOperation, Aspect: "16:9"}}, false, nil, true},
+		{"smart crop to nothing", []Operation{{Type: SmartCropOperation, Aspect: "1:1000"}}, false, nil, false},
+		{"smart crop huge ratio", []Operation{{Type: SmartCropOperation, Aspect: "1:9223372036854775807"}}, false, nil, false},
+		{"rotated size", []Operation{{Type: ResizeOperation, Width: 29000, Height: 10}, {Type: RotateOperation, Angle: 90}}, false, nil, true},
+		{"rotate", []Operation{{Type: RotateOperation, Angle: 45}}, false, nil, false},
+		{"grows across steps", []Operation{
+			{Type: ResizeOperation, Width: 20000, Height: 15000},
+			{Type: PadOperation, Width: 20000, Height: 25000},
+		}, false, util.ErrTooLarge, false},
+		{"flip", []Operation{{Type: FlipOperation, Direction: "diagonal"}}, false, nil, false},
+		{"adjust", []Operation{{Type: AdjustOperation, Brightness: 2}}, false, nil, false},
+		{"watermark without data", []Operation{{Type: WatermarkOperation}}, false, nil, false},
+		{"watermark with logo", []Operation{{Type: WatermarkOperation}}, true, nil, true},
+		{"redact", []Operation{{Type: RedactOperation, Width: 10, Height: 10, Mode: "pixelate"}}, false, nil, true},
+		{"redact unknown mode", []Operation{{Type: RedactOperation, Width: 10, Height: 10, Mode: "blur"}}, false, nil, false},
+		{"convert default quality", []Operation{{Type: ConvertOperation, Format: "jpeg"}}, false, nil, true},
+		{"convert quality", []Operation{{Type: ConvertOperation, Format: "jpg", Quality: 101}}, false, nil, false},
+		{"convert not last", []Operation{{Type: ConvertOperation, Format: "png"}, {Type: FlipOperation, Direction: "vertical"}}, false, nil, false},
+		{"unknown", []Operation{{Type: "blur"}}, false, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateOperations(tt.ops, size, tt.hasLogo)
+			if tt.ok != (err == nil) {
+				t.Fatalf("ValidateOperations() error = %v, want ok %v", err, tt.ok)
+			}
+			if tt.err != nil && !errors.Is(err, tt.err) {
+				t.Errorf("ValidateOperations() error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestOperationRegion(t *testing.T) {
+	size := image.Pt(100, 50)
+	tests := []struct {
+		op   Operation
+		want image.Rectangle
+	}{
+		{Operation{X: 10, Y: 10, Width: 20, Height: 20}, image.Rect(10, 10, 30, 30)},
+		{Operation{X: 90, Y: 40, Width: 20, Height: 20}, image.Rect(90, 40, 100, 50)},
+		{Operation{X: 1, Y: 1, Width: 1 << 62, Height: 1 << 62}, image.Rect(1, 1, 100, 50)},
+		{Operation{X: 100, Y: 0, Width: 1, Height: 1}, image.Rectangle{}},
+		{Operation{X: -1, Y: 0, Width: 1, Height: 1}, image.Rectangle{}},
+	}
+	for _, tt := range tests {
+		if got := tt.op.Region(size); got != tt.want {
+			t.Errorf("Region(%+v) = %v, want %v", tt.op, got, tt.want)
+		}
+	}
+}
+
+func TestResizedSize(t *testing.T) {
+	tests := []struct {
+		width, height int
+		want          image.Point
+	}{
+		{200, 0, image.Pt(200, 100)},
+		{0, 50, image.Pt(100, 50)},
+		{30, 40, image.Pt(30, 40)},
+		{1, 0, image.Pt(1, 1)},
+	}
+	for _, tt := range tests {
+		if got := ResizedSize(image.Pt(400, 200), tt.width, tt.height); got != tt.want {
+			t.Errorf("ResizedSize(%d, %d) = %v, want %v", tt.width, tt.height, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package internal
+
+import (
+	"fmt"
+	"image"
+)
+
+// Preset is a named output format for social networks. The margins keep the watermark clear
+// of the parts covered by the networks' own interface.
+type Preset struct {
+	Name    string
+	Width   int
+	Height  int
+	Pos     Position
+	MarginX int
+	MarginY int
+	Format  string
+	Quality int
+}
+
+var Presets = map[string]Preset{
+	"instagram_square": {Name: "instagram_square", Width: 1080, Height: 1080, Pos: RightBottom, MarginX: 32, MarginY: 32, Format: ".jpg", Quality: 90},
+	// stories show the profile on top and the reply box at the bottom, roughly 250px each
+	"story":    {Name: "story", Width: 1080, Height: 1920, Pos: RightBottom, MarginX: 48, MarginY: 280, Format: ".jpg", Quality: 90},
+	"og_image": {Name: "og_image", Width: 1200, Height: 630, Pos: RightBottom, MarginX: 40, MarginY: 40, Format: ".jpg", Quality: 85},
+	"twitter":  {Name: "twitter", Width: 1600, Height: 900, Pos: RightBottom, MarginX: 48, MarginY: 48, Format: ".jpg", Quality: 85},
+}
+
+func PresetFromString(name string) (Preset, error) {
+	p, ok := Presets[name]
+	if !ok {
+		return Preset{}, fmt.Errorf("unknown preset %q", name)
+	}
+	return p, nil
+}
+
+// Operations builds the pipeline producing the preset from an image of the given size. The source is
+// smart-cropped to the preset aspect ratio unless that would throw away more than half of it, then it is
+// padded instead.
+func (p Preset) Operations(src image.Rectangle, text string, fill bool, logoOpts LogoOptions) []Operation {
+	fit := Operation{Type: SmartCropOperation, Aspect: fmt.Sprintf("%d:%d", p.Width, p.Height)}
+	crop := SmartCropSize(src.Size(), p.Width, p.Height)
+	ops := []Operation{fit, {Type: ResizeOperation, Width: p.Width, Height: p.Height}}
+	if 2*crop.X*crop.Y < src.Dx()*src.Dy() {
+		ops = []Operation{{Type: PadOperation, Width: p.Width, Height: p.Height}}
+	}
+	return append(ops,
+		Operation{
+			Type:        WatermarkOperation,
+			Text:        text,
+			Fill:        fill,
+			Pos:         p.Pos,
+			MarginX:     p.MarginX,
+			MarginY:     p.MarginY,
+			LogoOptions: logoOpts,
+		},
+		Operation{Type: ConvertOperation, Format: p.Format, Quality: p.Quality},
+	)
+}
@@ -0,0 +1,70 @@
+package internal
+
+import (
+	"fmt"
+	"image"
+	"math"
+
+	"golang.org/x/image/draw"
+)
+
+type Interpolation string
+
+const (
+	NearestNeighbor Interpolation = "nearest"
+	BiLinear        Interpolation = "bilinear"
+	CatmullRom      Interpolation = "catmullrom"
+	Lanczos         Interpolation = "lanczos"
+)
+
+// DefaultInterpolation is used for logos unless the request or the service configuration says otherwise.
+const DefaultInterpolation = Lanczos
+
+// Lanczos3 is the Lanczos kernel with a support of 3, sharper than Catmull-Rom on downscaling.
+var Lanczos3 = &draw.Kernel{Support: 3, At: func(t float64) float64 {
+	if t == 0 {
+		return 1
+	}
+	if t < 0 {
+		t = -t
+	}
+	if t >= 3 {
+		return 0
+	}
+	x := math.Pi * t
+	return 3 * math.Sin(x) * math.Sin(x/3) / (x * x)
+}}
+
+// ParseInterpolation returns an empty Interpolation for an empty string, so the caller can fall back to its default.
+func ParseInterpolation(s string) (Interpolation, error) {
+	switch i := Interpolation(s); i {
+	case "", NearestNeighbor, BiLinear, CatmullRom, Lanczos:
+		return i, nil
+	}
+	return "", fmt.Errorf("unknown interpolation %q", s)
+}
+
+func (i Interpolation) Scaler() draw.Scaler {
+	switch i {
+	case NearestNeighbor:
+		return draw.NearestNeighbor
+	case BiLinear:
+		return draw.BiLinear
+	case CatmullRom:
+		return draw.CatmullRom
+	case Lanczos:
+		return Lanczos3
+	}
+	return DefaultInterpolation.Scaler()
+}
+
+// Resize scales src to exactly width x height.
+func Resize(src image.Image, width, height int, interp Interpolation) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	if vector, ok := src.(VectorImage); ok {
+		draw.Draw(dst, dst.Rect, vector.Rasterize(width, height), image.Point{0, 0}, draw.Src)
+		return dst
+	}
+	interp.Scaler().Scale(dst, dst.Rect, src, src.Bounds(), draw.Over, nil)
+	return dst
+}
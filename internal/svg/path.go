@@ -0,0 +1,428 @@
+package svg
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+type opKind int
+
+const (
+	opMove opKind = iota
+	opLine
+	opCubic
+	opClose
+)
+
+type op struct {
+	kind opKind
+	pts  [3][2]float64
+}
+
+// path keeps absolute coordinates only, quadratic curves and arcs are converted to cubics when parsed.
+type path []op
+
+func (p *path) moveTo(x, y float64) {
+	*p = append(*p, op{kind: opMove, pts: [3][2]float64{{x, y}}})
+}
+
+func (p *path) lineTo(x, y float64) {
+	*p = append(*p, op{kind: opLine, pts: [3][2]float64{{x, y}}})
+}
+
+func (p *path) cubicTo(x1, y1, x2, y2, x, y float64) {
+	*p = append(*p, op{kind: opCubic, pts: [3][2]float64{{x1, y1}, {x2, y2}, {x, y}}})
+}
+
+func (p *path) close() {
+	*p = append(*p, op{kind: opClose})
+}
+
+func (p path) current() (float64, float64) {
+	start := [2]float64{}
+	for i := len(p) - 1; i >= 0; i-- {
+		switch p[i].kind {
+		case opClose:
+			// after closing, the pen returns to the start of that subpath
+			for j := i - 1; j >= 0; j-- {
+				if p[j].kind == opMove {
+					start = p[j].pts[0]
+					break
+				}
+			}
+			return start[0], start[1]
+		case opCubic:
+			return p[i].pts[2][0], p[i].pts[2][1]
+		default:
+			return p[i].pts[0][0], p[i].pts[0][1]
+		}
+	}
+	return 0, 0
+}
+
+// arcTo appends an elliptical arc in SVG endpoint parameterization, approximated with cubic curves.
+func (p *path) arcTo(rx, ry, rotation float64, large, sweep bool, x, y float64) {
+	x0, y0 := p.current()
+	rx, ry = math.Abs(rx), math.Abs(ry)
+	if rx == 0 || ry == 0 {
+		p.lineTo(x, y)
+		return
+	}
+	if x0 == x && y0 == y {
+		return
+	}
+	phi := rotation * math.Pi / 180
+	sinPhi, cosPhi := math.Sincos(phi)
+	dx, dy := (x0-x)/2, (y0-y)/2
+	x1 := cosPhi*dx + sinPhi*dy
+	y1 := -sinPhi*dx + cosPhi*dy
+
+	// scale the radii up when they are too small to reach the end point
+	if l := x1*x1/(rx*rx) + y1*y1/(ry*ry); l > 1 {
+		rx, ry = rx*math.Sqrt(l), ry*math.Sqrt(l)
+	}
+	num := rx*rx*ry*ry - rx*rx*y1*y1 - ry*ry*x1*x1
+	den := rx*rx*y1*y1 + ry*ry*x1*x1
+	coef := 0.0
+	if den != 0 && num > 0 {
+		coef = math.Sqrt(num / den)
+	}
+	if large == sweep {
+		coef = -coef
+	}
+	cx1, cy1 := coef*rx*y1/ry, -coef*ry*x1/rx
+	cx := cosPhi*cx1 - sinPhi*cy1 + (x0+x)/2
+	cy := sinPhi*cx1 + cosPhi*cy1 + (y0+y)/2
+
+	theta := angle(1, 0, (x1-cx1)/rx, (y1-cy1)/ry)
+	delta := angle((x1-cx1)/rx, (y1-cy1)/ry, (-x1-cx1)/rx, (-y1-cy1)/ry)
+	if !sweep && delta > 0 {
+		delta -= 2 * math.Pi
+	} else if sweep && delta < 0 {
+		delta += 2 * math.Pi
+	}
+
+	segments := int(math.Ceil(math.Abs(delta) / (math.Pi / 2)))
+	step := delta / float64(segments)
+	k := 4.0 / 3 * math.Tan(step/4)
+	point := func(t float64) (float64, float64, float64, float64) {
+		sin, cos := math.Sincos(t)
+		px := cx + rx*cos*cosPhi - ry*sin*sinPhi
+		py := cy + rx*cos*sinPhi + ry*sin*cosPhi
+		// derivative of the ellipse point, used for control points
+		dx := -rx*sin*cosPhi - ry*cos*sinPhi
+		dy := -rx*sin*sinPhi + ry*cos*cosPhi
+		return px, py, dx, dy
+	}
+	for i := 0; i < segments; i++ {
+		t1, t2 := theta+float64(i)*step, theta+float64(i+1)*step
+		ax, ay, adx, ady := point(t1)
+		bx, by, bdx, bdy := point(t2)
+		if i == segments-1 {
+			bx, by = x, y
+		}
+		p.cubicTo(ax+k*adx, ay+k*ady, bx-k*bdx, by-k*bdy, bx, by)
+	}
+}
+
+func angle(ux, uy, vx, vy float64) float64 {
+	a := math.Atan2(uy, ux)
+	b := math.Atan2(vy, vx)
+	d := b - a
+	for d > math.Pi {
+		d -= 2 * math.Pi
+	}
+	for d < -math.Pi {
+		d += 2 * math.Pi
+	}
+	return d
+}
+
+func parsePath(d string) path {
+	var (
+		p          path
+		s          = scanner{s: d}
+		cmd        byte
+		cx, cy     float64
+		sx, sy     float64
+		lastCtrl   [2]float64
+		lastCubic  bool
+		lastQuadCp [2]float64
+		lastQuad   bool
+	)
+	for {
+		s.skipSeparators()
+		if s.done() {
+			return p
+		}
+		if c := s.peek(); isCommand(c) {
+			cmd = c
+			s.pos++
+		} else if cmd == 0 {
+			return p
+		}
+		rel := cmd >= 'a' && cmd <= 'z'
+		ox, oy := 0.0, 0.0
+		if rel {
+			ox, oy = cx, cy
+		}
+		nums := func(n int) ([]float64, bool) {
+			res := make([]float64, n)
+			for i := range res {
+				f, ok := s.number()
+				if !ok {
+					return nil, false
+				}
+				res[i] = f
+			}
+			return res, true
+		}
+		wasCubic, wasQuad := false, false
+		switch strings.ToUpper(string(cmd)) {
+		case "M":
+			n, ok := nums(2)
+			if !ok {
+				return p
+			}
+			cx, cy = ox+n[0], oy+n[1]
+			sx, sy = cx, cy
+			p.moveTo(cx, cy)
+			// subsequent coordinate pairs are implicit line commands
+			if rel {
+				cmd = 'l'
+			} else {
+				cmd = 'L'
+			}
+		case "L":
+			n, ok := nums(2)
+			if !ok {
+				return p
+			}
+			cx, cy = ox+n[0], oy+n[1]
+			p.lineTo(cx, cy)
+		case "H":
+			n, ok := nums(1)
+			if !ok {
+				return p
+			}
+			cx = ox + n[0]
+			p.lineTo(cx, cy)
+		case "V":
+			n, ok := nums(1)
+			if !ok {
+				return p
+			}
+			cy = oy + n[0]
+			p.lineTo(cx, cy)
+		case "C", "S":
+			var c1x, c1y float64
+			var n []float64
+			var ok bool
+			if cmd == 'C' || cmd == 'c' {
+				if n, ok = nums(6); !ok {
+					return p
+				}
+				c1x, c1y = ox+n[0], oy+n[1]
+				n = n[2:]
+			} else {
+				if n, ok = nums(4); !ok {
+					return p
+				}
+				c1x, c1y = cx, cy
+				if lastCubic {
+					c1x, c1y = 2*cx-lastCtrl[0], 2*cy-lastCtrl[1]
+				}
+			}
+			c2x, c2y := ox+n[0], oy+n[1]
+			cx, cy = ox+n[2], oy+n[3]
+			p.cubicTo(c1x, c1y, c2x, c2y, cx, cy)
+			lastCtrl, wasCubic = [2]float64{c2x, c2y}, true
+		case "Q", "T":
+			var qx, qy float64
+			var n []float64
+			var ok bool
+			if cmd == 'Q' || cmd == 'q' {
+				if n, ok = nums(4); !ok {
+					return p
+				}
+				qx, qy = ox+n[0], oy+n[1]
+				n = n[2:]
+			} else {
+				if n, ok = nums(2); !ok {
+					return p
+				}
+				qx, qy = cx, cy
+				if lastQuad {
+					qx, qy = 2*cx-lastQuadCp[0], 2*cy-lastQuadCp[1]
+				}
+			}
+			x, y := ox+n[0], oy+n[1]
+			p.cubicTo(cx+2.0/3*(qx-cx), cy+2.0/3*(qy-cy), x+2.0/3*(qx-x), y+2.0/3*(qy-y), x, y)
+			cx, cy = x, y
+			lastQuadCp, wasQuad = [2]float64{qx, qy}, true
+		case "A":
+			var n [7]float64
+			for i := range n {
+				var ok bool
+				if i == 3 || i == 4 {
+					n[i], ok = s.flag()
+				} else {
+					n[i], ok = s.number()
+				}
+				if !ok {
+					return p
+				}
+			}
+			x, y := ox+n[5], oy+n[6]
+			p.arcTo(n[0], n[1], n[2], n[3] != 0, n[4] != 0, x, y)
+			cx, cy = x, y
+		case "Z":
+			p.close()
+			cx, cy = sx, sy
+		default:
+			return p
+		}
+		lastCubic, lastQuad = wasCubic, wasQuad
+	}
+}
+
+func isCommand(c byte) bool {
+	return strings.IndexByte("MmLlHhVvCcSsQqTtAaZz", c) >= 0
+}
+
+type scanner struct {
+	s   string
+	pos int
+}
+
+func (s *scanner) done() bool {
+	return s.pos >= len(s.s)
+}
+
+func (s *scanner) peek() byte {
+	return s.s[s.pos]
+}
+
+func (s *scanner) skipSeparators() {
+	for !s.done() && strings.IndexByte(" \t\r\n,", s.peek()) >= 0 {
+		s.pos++
+	}
+}
+
+// flag reads a single 0/1 digit, arc flags are allowed to be written without separators.
+func (s *scanner) flag() (float64, bool) {
+	s.skipSeparators()
+	if s.done() || (s.peek() != '0' && s.peek() != '1') {
+		return 0, false
+	}
+	s.pos++
+	return float64(s.s[s.pos-1] - '0'), true
+}
+
+func (s *scanner) number() (float64, bool) {
+	s.skipSeparators()
+	start := s.pos
+	if !s.done() && (s.peek() == '+' || s.peek() == '-') {
+		s.pos++
+	}
+	digits, dot := false, false
+	for !s.done() {
+		c := s.peek()
+		if c >= '0' && c <= '9' {
+			digits = true
+		} else if c == '.' && !dot {
+			dot = true
+		} else {
+			break
+		}
+		s.pos++
+	}
+	if digits && !s.done() && (s.peek() == 'e' || s.peek() == 'E') {
+		save := s.pos
+		s.pos++
+		if !s.done() && (s.peek() == '+' || s.peek() == '-') {
+			s.pos++
+		}
+		expDigits := false
+		for !s.done() && s.peek() >= '0' && s.peek() <= '9' {
+			s.pos++
+			expDigits = true
+		}
+		if !expDigits {
+			s.pos = save
+		}
+	}
+	if !digits {
+		s.pos = start
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(s.s[start:s.pos], 64)
+	return f, err == nil
+}
+
+type matrix [6]float64
+
+var identity = matrix{1, 0, 0, 1, 0, 0}
+
+// mul returns m * n, so n is applied to the points first.
+func (m matrix) mul(n matrix) matrix {
+	return matrix{
+		m[0]*n[0] + m[2]*n[1],
+		m[1]*n[0] + m[3]*n[1],
+		m[0]*n[2] + m[2]*n[3],
+		m[1]*n[2] + m[3]*n[3],
+		m[0]*n[4] + m[2]*n[5] + m[4],
+		m[1]*n[4] + m[3]*n[5] + m[5],
+	}
+}
+
+func (m matrix) apply(x, y float64) (float64, float64) {
+	return m[0]*x + m[2]*y + m[4], m[1]*x + m[3]*y + m[5]
+}
+
+// scale is the average length scaling factor, used for stroke widths.
+func (m matrix) scale() float64 {
+	return math.Sqrt(math.Abs(m[0]*m[3] - m[1]*m[2]))
+}
+
+func parseTransform(v string) matrix {
+	m := identity
+	for {
+		v = strings.TrimLeft(v, " \t\r\n,")
+		open := strings.IndexByte(v, '(')
+		end := strings.IndexByte(v, ')')
+		if open < 0 || end < open {
+			return m
+		}
+		name := strings.TrimSpace(v[:open])
+		a := parseNumbers(v[open+1 : end])
+		v = v[end+1:]
+		var t matrix
+		switch {
+		case name == "matrix" && len(a) == 6:
+			t = matrix{a[0], a[1], a[2], a[3], a[4], a[5]}
+		case name == "translate" && len(a) == 1:
+			t = matrix{1, 0, 0, 1, a[0], 0}
+		case name == "translate" && len(a) == 2:
+			t = matrix{1, 0, 0, 1, a[0], a[1]}
+		case name == "scale" && len(a) == 1:
+			t = matrix{a[0], 0, 0, a[0], 0, 0}
+		case name == "scale" && len(a) == 2:
+			t = matrix{a[0], 0, 0, a[1], 0, 0}
+		case name == "rotate" && (len(a) == 1 || len(a) == 3):
+			sin, cos := math.Sincos(a[0] * math.Pi / 180)
+			t = matrix{cos, sin, -sin, cos, 0, 0}
+			if len(a) == 3 {
+				t = matrix{1, 0, 0, 1, a[1], a[2]}.mul(t).mul(matrix{1, 0, 0, 1, -a[1], -a[2]})
+			}
+		case name == "skewX" && len(a) == 1:
+			t = matrix{1, 0, math.Tan(a[0] * math.Pi / 180), 1, 0, 0}
+		case name == "skewY" && len(a) == 1:
+			t = matrix{1, math.Tan(a[0] * math.Pi / 180), 0, 1, 0, 0}
+		default:
+			continue
+		}
+		m = m.mul(t)
+	}
+}
@@ -0,0 +1,172 @@
+package svg
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"golang.org/x/image/vector"
+)
+
+type point struct{ x, y float64 }
+
+type polyline struct {
+	pts    []point
+	closed bool
+}
+
+// Rasterize renders the document to a width x height image, preserving the aspect ratio
+// and centering the drawing like the default xMidYMid meet of browsers.
+func (img *Image) Rasterize(width, height int) image.Image {
+	if width <= 0 || height <= 0 {
+		return image.NewRGBA(image.Rectangle{})
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	vb := img.viewBox
+	s := math.Min(float64(width)/vb[2], float64(height)/vb[3])
+	tx := (float64(width)-vb[2]*s)/2 - vb[0]*s
+	ty := (float64(height)-vb[3]*s)/2 - vb[1]*s
+	viewport := matrix{s, 0, 0, s, tx, ty}
+
+	z := vector.NewRasterizer(width, height)
+	for _, sh := range img.shapes {
+		m := viewport.mul(sh.m)
+		lines := flatten(sh.path, m)
+		if c, ok := paint(sh.style.fill, sh.style.fillOpacity*sh.style.opacity); ok {
+			z.Reset(width, height)
+			for _, l := range lines {
+				if len(l.pts) < 3 {
+					continue
+				}
+				z.MoveTo(float32(l.pts[0].x), float32(l.pts[0].y))
+				for _, p := range l.pts[1:] {
+					z.LineTo(float32(p.x), float32(p.y))
+				}
+				z.ClosePath()
+			}
+			z.Draw(dst, dst.Bounds(), image.NewUniform(c), image.Point{})
+		}
+		if c, ok := paint(sh.style.stroke, sh.style.strokeOpacity*sh.style.opacity); ok && sh.style.strokeWidth > 0 {
+			z.Reset(width, height)
+			stroke(z, lines, sh.style.strokeWidth*m.scale()/2)
+			z.Draw(dst, dst.Bounds(), image.NewUniform(c), image.Point{})
+		}
+	}
+	return dst
+}
+
+func paint(c color.NRGBA, opacity float64) (color.Color, bool) {
+	c.A = uint8(float64(c.A) * opacity)
+	return c, c.A != 0
+}
+
+// flatten converts the path to device space polylines, curves are split into
+// segments of roughly a pixel and a half.
+func flatten(p path, m matrix) []polyline {
+	var (
+		res []polyline
+		cur = -1
+		pen point
+	)
+	start := func(pt point) {
+		res = append(res, polyline{pts: []point{pt}})
+		cur = len(res) - 1
+	}
+	for _, o := range p {
+		switch o.kind {
+		case opMove:
+			x, y := m.apply(o.pts[0][0], o.pts[0][1])
+			pen = point{x, y}
+			start(pen)
+		case opLine:
+			if cur < 0 {
+				start(pen)
+			}
+			x, y := m.apply(o.pts[0][0], o.pts[0][1])
+			pen = point{x, y}
+			res[cur].pts = append(res[cur].pts, pen)
+		case opCubic:
+			if cur < 0 {
+				start(pen)
+			}
+			var c [3]point
+			for i := range c {
+				x, y := m.apply(o.pts[i][0], o.pts[i][1])
+				c[i] = point{x, y}
+			}
+			length := dist(pen, c[0]) + dist(c[0], c[1]) + dist(c[1], c[2])
+			n := int(math.Max(1, math.Min(256, math.Ceil(length/1.5))))
+			for i := 1; i <= n; i++ {
+				t := float64(i) / float64(n)
+				mt := 1 - t
+				res[cur].pts = append(res[cur].pts, point{
+					mt*mt*mt*pen.x + 3*mt*mt*t*c[0].x + 3*mt*t*t*c[1].x + t*t*t*c[2].x,
+					mt*mt*mt*pen.y + 3*mt*mt*t*c[0].y + 3*mt*t*t*c[1].y + t*t*t*c[2].y,
+				})
+			}
+			pen = c[2]
+		case opClose:
+			if cur >= 0 {
+				res[cur].closed = true
+				pen = res[cur].pts[0]
+				cur = -1
+			}
+		}
+	}
+	return res
+}
+
+func dist(a, b point) float64 {
+	return math.Hypot(b.x-a.x, b.y-a.y)
+}
+
+// stroke outlines every segment with a quad and every vertex with a disc, giving round joins and caps.
+// All polygons are emitted with the same winding so overlapping parts don't cancel each other out.
+func stroke(z *vector.Rasterizer, lines []polyline, hw float64) {
+	for _, l := range lines {
+		pts := l.pts
+		if l.closed && len(pts) > 1 {
+			pts = append(pts[:len(pts):len(pts)], pts[0])
+		}
+		for i := 0; i+1 < len(pts); i++ {
+			a, b := pts[i], pts[i+1]
+			d := dist(a, b)
+			if d == 0 {
+				continue
+			}
+			nx, ny := -(b.y-a.y)/d*hw, (b.x-a.x)/d*hw
+			polygon(z, []point{{a.x + nx, a.y + ny}, {b.x + nx, b.y + ny}, {b.x - nx, b.y - ny}, {a.x - nx, a.y - ny}})
+		}
+		for _, p := range pts {
+			disc(z, p, hw)
+		}
+	}
+}
+
+func disc(z *vector.Rasterizer, c point, r float64) {
+	n := int(math.Max(8, math.Min(64, r*4)))
+	pts := make([]point, n)
+	for i := range pts {
+		sin, cos := math.Sincos(2 * math.Pi * float64(i) / float64(n))
+		pts[i] = point{c.x + r*cos, c.y + r*sin}
+	}
+	polygon(z, pts)
+}
+
+func polygon(z *vector.Rasterizer, pts []point) {
+	area := 0.0
+	for i := range pts {
+		j := (i + 1) % len(pts)
+		area += pts[i].x*pts[j].y - pts[j].x*pts[i].y
+	}
+	if area < 0 {
+		for i, j := 0, len(pts)-1; i < j; i, j = i+1, j-1 {
+			pts[i], pts[j] = pts[j], pts[i]
+		}
+	}
+	z.MoveTo(float32(pts[0].x), float32(pts[0].y))
+	for _, p := range pts[1:] {
+		z.LineTo(float32(p.x), float32(p.y))
+	}
+	z.ClosePath()
+}
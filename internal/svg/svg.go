@@ -0,0 +1,402 @@
+package svg
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"image"
+	"image/color"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/image/colornames"
+)
+
+var (
+	ErrNotSVG      = errors.New("svg: missing root svg element")
+	ErrInvalidSize = errors.New("svg: invalid width, height or viewBox")
+)
+
+// maxSize bounds the natural size of a document so its bounds fit in an int on every platform.
+const maxSize = math.MaxInt32
+
+// Image is a parsed SVG document. It renders at its natural size when used as a regular image.Image,
+// Rasterize should be preferred when the target size is known to keep the edges crisp.
+type Image struct {
+	Source []byte
+
+	viewBox       [4]float64
+	width, height float64
+	shapes        []shape
+
+	once    sync.Once
+	natural *image.RGBA
+}
+
+type style struct {
+	fill, stroke                        color.NRGBA
+	fillOpacity, strokeOpacity, opacity float64
+	strokeWidth                         float64
+}
+
+type shape struct {
+	path  path
+	m     matrix
+	style style
+}
+
+// Parse reads an SVG document, keeping the raw bytes so the logo can be forwarded without re-encoding.
+func Parse(r io.Reader) (*Image, error) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	img := &Image{Source: src}
+	dec := xml.NewDecoder(bytes.NewReader(src))
+	dec.Strict = false
+
+	type frame struct {
+		m     matrix
+		style style
+		skip  bool
+	}
+	root := frame{
+		m:     identity,
+		style: style{fill: color.NRGBA{A: 255}, fillOpacity: 1, strokeOpacity: 1, opacity: 1, strokeWidth: 1},
+	}
+	stack := []frame{root}
+	seenRoot := false
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			parent := stack[len(stack)-1]
+			attrs := attributes(t.Attr)
+			cur := frame{m: parent.m, style: parent.style, skip: parent.skip}
+			if tr, ok := attrs["transform"]; ok {
+				cur.m = parent.m.mul(parseTransform(tr))
+			}
+			cur.style = parseStyle(cur.style, attrs)
+			switch t.Name.Local {
+			case "svg":
+				if !seenRoot {
+					if err := img.parseRoot(attrs); err != nil {
+						return nil, err
+					}
+					seenRoot = true
+				}
+			case "defs", "clipPath", "mask", "symbol", "marker", "pattern", "linearGradient", "radialGradient", "style", "title", "desc", "metadata":
+				cur.skip = true
+			default:
+				if !cur.skip && seenRoot {
+					if p := elementPath(t.Name.Local, attrs); len(p) > 0 {
+						img.shapes = append(img.shapes, shape{path: p, m: cur.m, style: cur.style})
+					}
+				}
+			}
+			stack = append(stack, cur)
+		case xml.EndElement:
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+	if !seenRoot {
+		return nil, ErrNotSVG
+	}
+	return img, nil
+}
+
+// parseRoot resolves the natural size of the document, the sizes which are not finite and positive are rejected.
+func (img *Image) parseRoot(attrs map[string]string) error {
+	if vb, ok := attrs["viewBox"]; ok {
+		nums := parseNumbers(vb)
+		if len(nums) != 4 || !validSize(nums[2]) || !validSize(nums[3]) {
+			return ErrInvalidSize
+		}
+		copy(img.viewBox[:], nums)
+	}
+	var err error
+	if img.width, err = rootLength(attrs["width"]); err != nil {
+		return err
+	}
+	if img.height, err = rootLength(attrs["height"]); err != nil {
+		return err
+	}
+	switch {
+	case img.viewBox[2] == 0 && (img.width == 0 || img.height == 0):
+		// browsers fall back to 300x150 for documents without any size information
+		if img.width == 0 {
+			img.width = 300
+		}
+		if img.height == 0 {
+			img.height = 150
+		}
+	case img.width == 0 && img.height == 0:
+		img.width, img.height = img.viewBox[2], img.viewBox[3]
+	case img.width == 0:
+		img.width = img.height * img.viewBox[2] / img.viewBox[3]
+	case img.height == 0:
+		img.height = img.width * img.viewBox[3] / img.viewBox[2]
+	}
+	if !validSize(img.width) || !validSize(img.height) {
+		return ErrInvalidSize
+	}
+	if img.viewBox[2] == 0 {
+		img.viewBox = [4]float64{0, 0, img.width, img.height}
+	}
+	return nil
+}
+
+// rootLength is 0 for the sizes left to the viewBox, the relative and the unknown units fall back to it too.
+func rootLength(v string) (float64, error) {
+	if v == "" || strings.HasSuffix(v, "%") {
+		return 0, nil
+	}
+	f, err := parseNumber(v)
+	if errors.Is(err, strconv.ErrSyntax) {
+		return 0, nil
+	}
+	if err != nil || !validSize(f) {
+		return 0, ErrInvalidSize
+	}
+	return f, nil
+}
+
+func validSize(f float64) bool {
+	return f > 0 && f <= maxSize
+}
+
+// AspectRatio returns width divided by height of the document.
+func (img *Image) AspectRatio() float64 {
+	return img.width / img.height
+}
+
+func (img *Image) ColorModel() color.Model {
+	return color.RGBAModel
+}
+
+func (img *Image) Bounds() image.Rectangle {
+	return image.Rect(0, 0, int(math.Ceil(img.width)), int(math.Ceil(img.height)))
+}
+
+func (img *Image) At(x, y int) color.Color {
+	img.once.Do(func() {
+		b := img.Bounds()
+		img.natural = img.Rasterize(b.Dx(), b.Dy()).(*image.RGBA)
+	})
+	return img.natural.At(x, y)
+}
+
+func attributes(attrs []xml.Attr) map[string]string {
+	res := make(map[string]string, len(attrs))
+	for _, a := range attrs {
+		res[a.Name.Local] = strings.TrimSpace(a.Value)
+	}
+	return res
+}
+
+func parseStyle(s style, attrs map[string]string) style {
+	props := make(map[string]string)
+	for k, v := range attrs {
+		props[k] = v
+	}
+	// inline style declarations take precedence over presentation attributes
+	for _, decl := range strings.Split(attrs["style"], ";") {
+		if k, v, ok := strings.Cut(decl, ":"); ok {
+			props[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+	}
+	if v, ok := props["fill"]; ok {
+		s.fill = parseColor(v, s.fill)
+	}
+	if v, ok := props["stroke"]; ok {
+		s.stroke = parseColor(v, s.stroke)
+	}
+	if v, ok := props["stroke-width"]; ok {
+		s.strokeWidth = parseLength(v)
+	}
+	if v, ok := props["fill-opacity"]; ok {
+		s.fillOpacity = parseOpacity(v)
+	}
+	if v, ok := props["stroke-opacity"]; ok {
+		s.strokeOpacity = parseOpacity(v)
+	}
+	if v, ok := props["opacity"]; ok {
+		s.opacity *= parseOpacity(v)
+	}
+	return s
+}
+
+func parseColor(v string, inherited color.NRGBA) color.NRGBA {
+	v = strings.ToLower(strings.TrimSpace(v))
+	switch {
+	case v == "none" || v == "transparent":
+		return color.NRGBA{}
+	case v == "inherit":
+		return inherited
+	case v == "currentcolor":
+		return color.NRGBA{A: 255}
+	case strings.HasPrefix(v, "#"):
+		var c color.NRGBA
+		hex := v[1:]
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		n, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil || len(hex) != 6 {
+			return inherited
+		}
+		c.R, c.G, c.B, c.A = uint8(n>>16), uint8(n>>8), uint8(n), 255
+		return c
+	case strings.HasPrefix(v, "rgb(") && strings.HasSuffix(v, ")"):
+		parts := strings.Split(v[4:len(v)-1], ",")
+		if len(parts) != 3 {
+			return inherited
+		}
+		var ch [3]uint8
+		for i, p := range parts {
+			p = strings.TrimSpace(p)
+			var f float64
+			if strings.HasSuffix(p, "%") {
+				f, _ = strconv.ParseFloat(strings.TrimSuffix(p, "%"), 64)
+				f = f * 255 / 100
+			} else {
+				f, _ = strconv.ParseFloat(p, 64)
+			}
+			ch[i] = uint8(math.Max(0, math.Min(255, math.Round(f))))
+		}
+		return color.NRGBA{ch[0], ch[1], ch[2], 255}
+	}
+	if c, ok := colornames.Map[v]; ok {
+		return color.NRGBA{c.R, c.G, c.B, 255}
+	}
+	return inherited
+}
+
+func parseOpacity(v string) float64 {
+	f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+	if err != nil {
+		return 1
+	}
+	return math.Max(0, math.Min(1, f))
+}
+
+// parseLength understands plain user units and the absolute CSS units, relative ones and the values
+// which are not finite fall back to 0.
+func parseLength(v string) float64 {
+	f, err := parseNumber(v)
+	if err != nil {
+		return 0
+	}
+	return f
+}
+
+func parseNumber(v string) (float64, error) {
+	v = strings.TrimSpace(v)
+	scale := 1.0
+	for unit, s := range map[string]float64{"px": 1, "pt": 4.0 / 3, "pc": 16, "mm": 96 / 25.4, "cm": 96 / 2.54, "in": 96} {
+		if strings.HasSuffix(v, unit) {
+			v, scale = strings.TrimSuffix(v, unit), s
+			break
+		}
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, err
+	}
+	if f = f * scale; math.IsInf(f, 0) || math.IsNaN(f) {
+		return 0, strconv.ErrRange
+	}
+	return f, nil
+}
+
+func parseNumbers(v string) []float64 {
+	var res []float64
+	s := scanner{s: v}
+	for {
+		f, ok := s.number()
+		if !ok {
+			return res
+		}
+		res = append(res, f)
+	}
+}
+
+func elementPath(name string, a map[string]string) path {
+	num := func(key string) float64 { return parseLength(a[key]) }
+	var p path
+	switch name {
+	case "path":
+		p = parsePath(a["d"])
+	case "rect":
+		x, y, w, h := num("x"), num("y"), num("width"), num("height")
+		if w <= 0 || h <= 0 {
+			return nil
+		}
+		rx, ry := num("rx"), num("ry")
+		if rx == 0 {
+			rx = ry
+		}
+		if ry == 0 {
+			ry = rx
+		}
+		rx, ry = math.Min(rx, w/2), math.Min(ry, h/2)
+		if rx == 0 {
+			p.moveTo(x, y)
+			p.lineTo(x+w, y)
+			p.lineTo(x+w, y+h)
+			p.lineTo(x, y+h)
+			p.close()
+			return p
+		}
+		p.moveTo(x+rx, y)
+		p.lineTo(x+w-rx, y)
+		p.arcTo(rx, ry, 0, false, true, x+w, y+ry)
+		p.lineTo(x+w, y+h-ry)
+		p.arcTo(rx, ry, 0, false, true, x+w-rx, y+h)
+		p.lineTo(x+rx, y+h)
+		p.arcTo(rx, ry, 0, false, true, x, y+h-ry)
+		p.lineTo(x, y+ry)
+		p.arcTo(rx, ry, 0, false, true, x+rx, y)
+		p.close()
+	case "circle", "ellipse":
+		cx, cy := num("cx"), num("cy")
+		rx, ry := num("rx"), num("ry")
+		if name == "circle" {
+			rx, ry = num("r"), num("r")
+		}
+		if rx <= 0 || ry <= 0 {
+			return nil
+		}
+		p.moveTo(cx+rx, cy)
+		p.arcTo(rx, ry, 0, false, true, cx-rx, cy)
+		p.arcTo(rx, ry, 0, false, true, cx+rx, cy)
+		p.close()
+	case "line":
+		p.moveTo(num("x1"), num("y1"))
+		p.lineTo(num("x2"), num("y2"))
+	case "polyline", "polygon":
+		pts := parseNumbers(a["points"])
+		for i := 0; i+1 < len(pts); i += 2 {
+			if i == 0 {
+				p.moveTo(pts[i], pts[i+1])
+			} else {
+				p.lineTo(pts[i], pts[i+1])
+			}
+		}
+		if name == "polygon" && len(p) > 0 {
+			p.close()
+		}
+	}
+	return p
+}
@@ -0,0 +1,292 @@
+package svg
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		name   string
+		root   string
+		width  float64
+		height float64
+		err    error
+	}{
+		{"no size", `<svg>`, 300, 150, nil},
+		{"width and height", `<svg width="40" height="20">`, 40, 20, nil},
+		{"units", `<svg width="1in" height="72pt">`, 96, 96, nil},
+		{"viewBox", `<svg viewBox="0 0 30 10">`, 30, 10, nil},
+		{"width from viewBox", `<svg height="20" viewBox="0 0 30 10">`, 60, 20, nil},
+		{"height from viewBox", `<svg width="15" viewBox="0 0 30 10">`, 15, 5, nil},
+		{"relative width", `<svg width="100%" height="100%" viewBox="0 0 30 10">`, 30, 10, nil},
+		{"unknown unit", `<svg width="2em" viewBox="0 0 30 10">`, 30, 10, nil},
+		{"infinite width", `<svg width="inf" height="10">`, 0, 0, ErrInvalidSize},
+		{"NaN height", `<svg width="10" height="NaN">`, 0, 0, ErrInvalidSize},
+		{"overflowing width", `<svg width="1e400" height="10">`, 0, 0, ErrInvalidSize},
+		{"huge width", `<svg width="1e300" height="10">`, 0, 0, ErrInvalidSize},
+		{"zero width", `<svg width="0" height="10">`, 0, 0, ErrInvalidSize},
+		{"negative height", `<svg width="10" height="-5">`, 0, 0, ErrInvalidSize},
+		{"negative viewBox", `<svg viewBox="0 0 -30 10">`, 0, 0, ErrInvalidSize},
+		{"zero viewBox", `<svg viewBox="0 0 30 0">`, 0, 0, ErrInvalidSize},
+		{"short viewBox", `<svg viewBox="0 0 30">`, 0, 0, ErrInvalidSize},
+		{"infinite viewBox", `<svg viewBox="0 0 inf 10">`, 0, 0, ErrInvalidSize},
+		{"huge viewBox", `<svg viewBox="0 0 1e300 10">`, 0, 0, ErrInvalidSize},
+		{"huge derived width", `<svg height="1e9" viewBox="0 0 1e9 1">`, 0, 0, ErrInvalidSize},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, err := Parse(strings.NewReader(tt.root + `</svg>`))
+			if err != tt.err {
+				t.Fatalf("Parse() error = %v, want %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			if math.Abs(img.width-tt.width) > 1e-9 || math.Abs(img.height-tt.height) > 1e-9 {
+				t.Errorf("size = %vx%v, want %vx%v", img.width, img.height, tt.width, tt.height)
+			}
+			if b := img.Bounds(); b.Dx() <= 0 || b.Dy() <= 0 {
+				t.Errorf("Bounds() = %v", b)
+			}
+		})
+	}
+}
+
+func TestParseNotSVG(t *testing.T) {
+	for _, src := range []string{``, `<html></html>`, `<rect width="1" height="1"/>`} {
+		if _, err := Parse(strings.NewReader(src)); err != ErrNotSVG {
+			t.Errorf("Parse(%q) error = %v, want %v", src, err, ErrNotSVG)
+		}
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	inherited := color.NRGBA{1, 2, 3, 255}
+	tests := []struct {
+		value string
+		want  color.NRGBA
+	}{
+		{"#ff0000", color.NRGBA{255, 0, 0, 255}},
+		{"#0F0", color.NRGBA{0, 255, 0, 255}},
+		{"rgb(0, 0, 255)", color.NRGBA{0, 0, 255, 255}},
+		{"rgb(100%, 50%, 0%)", color.NRGBA{255, 128, 0, 255}},
+		{"rgb(300, -5, 0)", color.NRGBA{255, 0, 0, 255}},
+		{"white", color.NRGBA{255, 255, 255, 255}},
+		{"none", color.NRGBA{}},
+		{"transparent", color.NRGBA{}},
+		{"currentColor", color.NRGBA{A: 255}},
+		{"inherit", inherited},
+		{"#12345", inherited},
+		{"rgb(1, 2)", inherited},
+		{"nocolor", inherited},
+	}
+	for _, tt := range tests {
+		if got := parseColor(tt.value, inherited); got != tt.want {
+			t.Errorf("parseColor(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestParseLength(t *testing.T) {
+	tests := []struct {
+		value string
+		want  float64
+	}{
+		{"12", 12},
+		{" 12px ", 12},
+		{"1in", 96},
+		{"2.54cm", 96},
+		{"-3", -3},
+		{"10%", 0},
+		{"inf", 0},
+		{"NaN", 0},
+		{"1e400", 0},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		if got := parseLength(tt.value); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("parseLength(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		name string
+		d    string
+		want []opKind
+		end  [2]float64
+	}{
+		{"absolute lines", "M0 0 L10 0 L10 10 Z", []opKind{opMove, opLine, opLine, opClose}, [2]float64{0, 0}},
+		{"relative lines", "m5 5 l10 0 v10 h-10 z", []opKind{opMove, opLine, opLine, opLine, opClose}, [2]float64{5, 5}},
+		{"implicit lines", "M0 0 10 0 10 10", []opKind{opMove, opLine, opLine}, [2]float64{10, 10}},
+		{"compact numbers", "M0-1.5.5.5", []opKind{opMove, opLine}, [2]float64{.5, .5}},
+		{"cubic", "M0 0 C1 1 2 2 3 3 S5 5 6 6", []opKind{opMove, opCubic, opCubic}, [2]float64{6, 6}},
+		{"quadratic", "M0 0 Q1 1 2 0 T4 0", []opKind{opMove, opCubic, opCubic}, [2]float64{4, 0}},
+		{"degenerate arc", "M0 0 A0 0 0 0 1 10 10", []opKind{opMove, opLine}, [2]float64{10, 10}},
+		{"garbage", "M0 0 L10 x", []opKind{opMove}, [2]float64{0, 0}},
+		{"no command", "10 10", nil, [2]float64{0, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := parsePath(tt.d)
+			var kinds []opKind
+			for _, o := range p {
+				kinds = append(kinds, o.kind)
+			}
+			if len(kinds) != len(tt.want) {
+				t.Fatalf("parsePath(%q) ops = %v, want %v", tt.d, kinds, tt.want)
+			}
+			for i := range kinds {
+				if kinds[i] != tt.want[i] {
+					t.Fatalf("parsePath(%q) ops = %v, want %v", tt.d, kinds, tt.want)
+				}
+			}
+			if x, y := p.current(); math.Abs(x-tt.end[0]) > 1e-9 || math.Abs(y-tt.end[1]) > 1e-9 {
+				t.Errorf("parsePath(%q) ends at %v,%v, want %v", tt.d, x, y, tt.end)
+			}
+		})
+	}
+}
+
+func TestParseArc(t *testing.T) {
+	p := parsePath("M0 10 A10 10 0 0 1 20 10")
+	if len(p) < 2 || p[1].kind != opCubic {
+		t.Fatalf("arc is not converted to cubics: %v", p)
+	}
+	// every cubic of the half circle ends on it
+	for _, o := range p[1:] {
+		x, y := o.pts[2][0], o.pts[2][1]
+		if r := math.Hypot(x-10, y-10); math.Abs(r-10) > 1e-6 {
+			t.Errorf("arc point %v,%v is %v away from the center", x, y, r)
+		}
+	}
+}
+
+func TestParseTransform(t *testing.T) {
+	tests := []struct {
+		value string
+		x, y  float64
+	}{
+		{"", 1, 2},
+		{"translate(10)", 11, 2},
+		{"translate(10, 20)", 11, 22},
+		{"scale(2)", 2, 4},
+		{"scale(2 3)", 2, 6},
+		{"rotate(90)", -2, 1},
+		{"rotate(180 1 2)", 1, 2},
+		{"matrix(1 0 0 1 5 5)", 6, 7},
+		{"translate(10) scale(2)", 12, 4},
+		{"unknown(1) translate(1 1)", 2, 3},
+	}
+	for _, tt := range tests {
+		x, y := parseTransform(tt.value).apply(1, 2)
+		if math.Abs(x-tt.x) > 1e-9 || math.Abs(y-tt.y) > 1e-9 {
+			t.Errorf("parseTransform(%q).apply(1, 2) = %v,%v, want %v,%v", tt.value, x, y, tt.x, tt.y)
+		}
+	}
+}
+
+func TestRasterize(t *testing.T) {
+	red := color.RGBA{255, 0, 0, 255}
+	tests := []struct {
+		name          string
+		src           string
+		width, height int
+		inside        []image.Point
+		outside       []image.Point
+	}{
+		{
+			name:    "rect",
+			src:     `<svg width="10" height="10"><rect x="2" y="2" width="6" height="6" fill="red"/></svg>`,
+			width:   10,
+			height:  10,
+			inside:  []image.Point{{5, 5}, {2, 2}, {7, 7}},
+			outside: []image.Point{{0, 0}, {9, 9}, {1, 5}},
+		},
+		{
+			name:    "scaled viewBox",
+			src:     `<svg viewBox="0 0 10 10"><rect width="5" height="5" fill="#f00"/></svg>`,
+			width:   100,
+			height:  100,
+			inside:  []image.Point{{10, 10}, {45, 45}},
+			outside: []image.Point{{55, 55}, {90, 10}},
+		},
+		{
+			name:    "letterboxed",
+			src:     `<svg viewBox="0 0 10 10"><rect width="10" height="10" fill="red"/></svg>`,
+			width:   40,
+			height:  20,
+			inside:  []image.Point{{20, 10}, {11, 1}},
+			outside: []image.Point{{5, 10}, {35, 10}},
+		},
+		{
+			name:    "transformed group",
+			src:     `<svg width="10" height="10"><g transform="translate(5 5)"><rect width="5" height="5" fill="red"/></g></svg>`,
+			width:   10,
+			height:  10,
+			inside:  []image.Point{{7, 7}},
+			outside: []image.Point{{2, 2}},
+		},
+		{
+			name:    "stroke only",
+			src:     `<svg width="20" height="20"><rect x="5" y="5" width="10" height="10" fill="none" stroke="red" stroke-width="2"/></svg>`,
+			width:   20,
+			height:  20,
+			inside:  []image.Point{{5, 10}, {14, 10}},
+			outside: []image.Point{{10, 10}, {1, 1}},
+		},
+		{
+			name:    "skipped defs",
+			src:     `<svg width="10" height="10"><defs><rect width="10" height="10" fill="red"/></defs></svg>`,
+			width:   10,
+			height:  10,
+			outside: []image.Point{{5, 5}},
+		},
+		{
+			name:    "transparent",
+			src:     `<svg width="10" height="10"><rect width="10" height="10" fill="red" opacity="0"/></svg>`,
+			width:   10,
+			height:  10,
+			outside: []image.Point{{5, 5}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, err := Parse(strings.NewReader(tt.src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			dst := img.Rasterize(tt.width, tt.height).(*image.RGBA)
+			if b := dst.Bounds(); b.Dx() != tt.width || b.Dy() != tt.height {
+				t.Fatalf("Rasterize() bounds = %v", b)
+			}
+			for _, p := range tt.inside {
+				if c := dst.RGBAAt(p.X, p.Y); c != red {
+					t.Errorf("pixel %v = %v, want %v", p, c, red)
+				}
+			}
+			for _, p := range tt.outside {
+				if c := dst.RGBAAt(p.X, p.Y); c.A != 0 {
+					t.Errorf("pixel %v = %v, want transparent", p, c)
+				}
+			}
+		})
+	}
+}
+
+func TestRasterizeEmpty(t *testing.T) {
+	img, err := Parse(strings.NewReader(`<svg width="10" height="10"><rect width="10" height="10"/></svg>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, size := range [][2]int{{0, 10}, {10, 0}, {-1, -1}} {
+		if b := img.Rasterize(size[0], size[1]).Bounds(); !b.Empty() {
+			t.Errorf("Rasterize(%d, %d) bounds = %v, want empty", size[0], size[1], b)
+		}
+	}
+}
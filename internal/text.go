@@ -0,0 +1,216 @@
+package internal
+
+import (
+	"image"
+	"os"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/text/unicode/bidi"
+)
+
+const DefaultFontSize = 13
+
+// textFaces hands out the fallback chains rendering the watermark texts. The bundled Go font covers Latin,
+// Greek and Cyrillic, other scripts need fonts configured with InitFonts.
+var textFaces *facePool
+
+func init() {
+	f, err := loadFont(goregular.TTF)
+	if err != nil {
+		panic(err)
+	}
+	if textFaces, err = newFacePool([]*opentype.Font{f}, DefaultFontSize); err != nil {
+		panic(err)
+	}
+}
+
+// TextFace takes a fallback chain for a single render, release returns it to the pool once the text is drawn.
+func TextFace() (face font.Face, release func()) {
+	return textFaces.get()
+}
+
+// InitFonts replaces the text faces with a fallback chain made of the given font files, in order of preference,
+// followed by the bundled Go font. Both single fonts and collections (.ttc/.otc) are accepted, from a
+// collection only the first font is used.
+func InitFonts(paths []string, size float64) error {
+	if size <= 0 {
+		size = DefaultFontSize
+	}
+	fonts := make([]*opentype.Font, 0, len(paths)+1)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		f, err := loadFont(data)
+		if err != nil {
+			return err
+		}
+		fonts = append(fonts, f)
+	}
+	f, err := loadFont(goregular.TTF)
+	if err != nil {
+		return err
+	}
+	pool, err := newFacePool(append(fonts, f), size)
+	if err != nil {
+		return err
+	}
+	textFaces = pool
+	return nil
+}
+
+func loadFont(data []byte) (*opentype.Font, error) {
+	collection, err := opentype.ParseCollection(data)
+	if err != nil {
+		return nil, err
+	}
+	return collection.Font(0)
+}
+
+// facePool keeps the fallback chains between the renders. Opentype faces reuse their glyph buffers
+// and aren't safe for concurrent use, so every render takes a chain of its own instead of sharing one.
+type facePool struct {
+	fonts []*opentype.Font
+	size  float64
+	pool  sync.Pool
+}
+
+func newFacePool(fonts []*opentype.Font, size float64) (*facePool, error) {
+	p := &facePool{fonts: fonts, size: size}
+	face, err := p.newFace()
+	if err != nil {
+		return nil, err
+	}
+	p.pool.Put(face)
+	p.pool.New = func() any {
+		// the options were validated by the first chain
+		face, _ := p.newFace()
+		return face
+	}
+	return p, nil
+}
+
+func (p *facePool) newFace() (*fallbackFace, error) {
+	faces := make([]font.Face, len(p.fonts))
+	for i, f := range p.fonts {
+		face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: p.size, DPI: 72, Hinting: font.HintingFull})
+		if err != nil {
+			return nil, err
+		}
+		faces[i] = face
+	}
+	return &fallbackFace{faces: faces}, nil
+}
+
+func (p *facePool) get() (font.Face, func()) {
+	face := p.pool.Get().(*fallbackFace)
+	return face, func() { p.pool.Put(face) }
+}
+
+// fallbackFace picks the first face having a glyph for every rune, it's used by one render at a time.
+type fallbackFace struct {
+	faces []font.Face
+}
+
+func (f *fallbackFace) face(r rune) font.Face {
+	for _, face := range f.faces {
+		if _, ok := face.GlyphAdvance(r); ok {
+			return face
+		}
+	}
+	return f.faces[0]
+}
+
+func (f *fallbackFace) Glyph(dot fixed.Point26_6, r rune) (image.Rectangle, image.Image, image.Point, fixed.Int26_6, bool) {
+	return f.face(r).Glyph(dot, r)
+}
+
+func (f *fallbackFace) GlyphBounds(r rune) (fixed.Rectangle26_6, fixed.Int26_6, bool) {
+	return f.face(r).GlyphBounds(r)
+}
+
+func (f *fallbackFace) GlyphAdvance(r rune) (fixed.Int26_6, bool) {
+	return f.face(r).GlyphAdvance(r)
+}
+
+func (f *fallbackFace) Kern(r0, r1 rune) fixed.Int26_6 {
+	face := f.face(r0)
+	if face != f.face(r1) {
+		return 0
+	}
+	return face.Kern(r0, r1)
+}
+
+func (f *fallbackFace) Metrics() font.Metrics {
+	metrics := f.faces[0].Metrics()
+	for _, face := range f.faces[1:] {
+		m := face.Metrics()
+		if m.Ascent > metrics.Ascent {
+			metrics.Ascent = m.Ascent
+		}
+		if m.Descent > metrics.Descent {
+			metrics.Descent = m.Descent
+		}
+		if m.Height > metrics.Height {
+			metrics.Height = m.Height
+		}
+	}
+	return metrics
+}
+
+func (f *fallbackFace) Close() error {
+	for _, face := range f.faces {
+		face.Close()
+	}
+	return nil
+}
+
+// VisualOrder reorders the text for left to right drawing, so Arabic and Hebrew runs come out
+// readable and mixed-script text keeps its logical structure. Contextual shaping isn't performed.
+func VisualOrder(text string) string {
+	var p bidi.Paragraph
+	if _, err := p.SetString(text); err != nil {
+		return text
+	}
+	ordering, err := p.Order()
+	if err != nil || ordering.NumRuns() == 0 {
+		return text
+	}
+	runs := make([]string, ordering.NumRuns())
+	for i := range runs {
+		run := ordering.Run(i)
+		runs[i] = run.String()
+		if run.Direction() == bidi.RightToLeft {
+			runs[i] = bidi.ReverseString(runs[i])
+		}
+	}
+	if baseDirection(text) == bidi.RightToLeft {
+		for i, j := 0, len(runs)-1; i < j; i, j = i+1, j-1 {
+			runs[i], runs[j] = runs[j], runs[i]
+		}
+	}
+	res := ""
+	for _, run := range runs {
+		res += run
+	}
+	return res
+}
+
+// baseDirection follows the first strong character of the text.
+func baseDirection(text string) bidi.Direction {
+	for _, r := range text {
+		props, _ := bidi.LookupRune(r)
+		switch props.Class() {
+		case bidi.L:
+			return bidi.LeftToRight
+		case bidi.R, bidi.AL:
+			return bidi.RightToLeft
+		}
+	}
+	return bidi.LeftToRight
+}
@@ -0,0 +1,56 @@
+package internal
+
+import (
+	"bytes"
+	"image"
+	"sync"
+	"testing"
+
+	"golang.org/x/image/font"
+)
+
+func TestTextFaceConcurrent(t *testing.T) {
+	texts := []string{"watermark-service", "Привет, мир", "Ελληνικά 123"}
+	want := make([][]byte, len(texts))
+	for i, text := range texts {
+		want[i] = CombineTextWithLogo(nil, text, DefaultInterpolation).(*image.RGBA).Pix
+	}
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for n := 0; n < 20; n++ {
+				i := (w + n) % len(texts)
+				got := CombineTextWithLogo(nil, texts[i], DefaultInterpolation).(*image.RGBA).Pix
+				if !bytes.Equal(got, want[i]) {
+					t.Errorf("concurrent render of %q differs", texts[i])
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+}
+
+func TestTextFaceFallback(t *testing.T) {
+	face, release := TextFace()
+	defer release()
+	tests := []struct {
+		r  rune
+		ok bool
+	}{
+		{'a', true},
+		{'Ж', true},
+		{'Ω', true},
+		{'字', false},
+	}
+	for _, tt := range tests {
+		if _, ok := face.GlyphAdvance(tt.r); ok != tt.ok {
+			t.Errorf("GlyphAdvance(%q) ok = %v, want %v", tt.r, ok, tt.ok)
+		}
+	}
+	if w := font.MeasureString(face, "ab"); w <= 0 {
+		t.Errorf("MeasureString() = %v", w)
+	}
+}
@@ -0,0 +1,169 @@
+package internal
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"golang.org/x/image/draw"
+)
+
+// BandedImage produces its pixels lazily in horizontal bands, only one band is kept in memory at a time.
+// Image encoders read rows from top to bottom, so encoding a BandedImage renders every band once as long
+// as the band height is a multiple of BandAlign. It is not safe for concurrent use.
+type BandedImage struct {
+	bounds image.Rectangle
+	rows   int
+	render func(dst *image.RGBA)
+	band   *image.RGBA
+	pix    []uint8
+	opaque bool
+}
+
+// NewBandedImage creates an image of the given bounds, render has to fill all of dst.Rect.
+func NewBandedImage(bounds image.Rectangle, rows int, render func(dst *image.RGBA)) *BandedImage {
+	return &BandedImage{bounds: bounds, rows: max(1, rows), render: render}
+}
+
+// BandAlign is the height of the blocks read by the JPEG encoder, bands of a multiple of it
+// don't have to be rendered again for the next row of blocks.
+const BandAlign = 16
+
+// AlignRows rounds the band height down to a multiple of BandAlign, but not below it.
+func AlignRows(rows int) int {
+	return max(BandAlign, rows/BandAlign*BandAlign)
+}
+
+// BandRows returns how many rows of the given width fit in limit bytes of RGBA pixels, aligned with AlignRows.
+func BandRows(width int, limit int64) int {
+	return AlignRows(int(min(limit/int64(4*max(1, width)), math.MaxInt32)))
+}
+
+func (b *BandedImage) ColorModel() color.Model {
+	return color.RGBAModel
+}
+
+func (b *BandedImage) Bounds() image.Rectangle {
+	return b.bounds
+}
+
+func (b *BandedImage) Opaque() bool {
+	return b.opaque
+}
+
+// Flatten returns the image put on a white background band by band, so it can be encoded
+// in JPEG without a full size copy.
+func (b *BandedImage) Flatten() image.Image {
+	if b.opaque {
+		return b
+	}
+	return &BandedImage{bounds: b.bounds, rows: b.rows, opaque: true, render: func(dst *image.RGBA) {
+		b.render(dst)
+		flatten(dst)
+	}}
+}
+
+// flatten draws the premultiplied pixels of dst over white in place.
+func flatten(dst *image.RGBA) {
+	for y := 0; y < dst.Rect.Dy(); y++ {
+		row := dst.Pix[y*dst.Stride : y*dst.Stride+4*dst.Rect.Dx()]
+		for i := 0; i < len(row); i += 4 {
+			a := 255 - row[i+3]
+			row[i] += a
+			row[i+1] += a
+			row[i+2] += a
+			row[i+3] = 255
+		}
+	}
+}
+
+func (b *BandedImage) At(x, y int) color.Color {
+	return b.RGBAAt(x, y)
+}
+
+func (b *BandedImage) RGBAAt(x, y int) color.RGBA {
+	if !image.Pt(x, y).In(b.bounds) {
+		return color.RGBA{}
+	}
+	if b.band == nil || y < b.band.Rect.Min.Y || y >= b.band.Rect.Max.Y {
+		b.load(y)
+	}
+	return b.band.RGBAAt(x, y)
+}
+
+func (b *BandedImage) load(y int) {
+	top := b.bounds.Min.Y + (y-b.bounds.Min.Y)/b.rows*b.rows
+	r := image.Rect(b.bounds.Min.X, top, b.bounds.Max.X, min(top+b.rows, b.bounds.Max.Y))
+	if b.pix == nil {
+		b.pix = make([]uint8, 4*b.bounds.Dx()*min(b.rows, b.bounds.Dy()))
+	}
+	b.band = &image.RGBA{Pix: b.pix[:4*r.Dx()*r.Dy()], Stride: 4 * r.Dx(), Rect: r}
+	b.render(b.band)
+}
+
+// WatermarkBands is the memory bounded counterpart of FillImageWithWatermarks and AddWatermarkToImageWithMargin,
+// the result is composed band by band when it is read instead of being copied as a whole.
+func WatermarkBands(watermark image.Image, src image.Image, fill bool, pos Position, margin image.Point, rows, workers int) *BandedImage {
+	src_rect := src.Bounds()
+	var placements []image.Point
+	if fill {
+		placements = fillPlacements(watermark, src_rect.Size())
+	} else {
+		placements = []image.Point{placement(watermark, src_rect.Size(), pos, margin)}
+	}
+	bounds := image.Rect(0, 0, src_rect.Dx(), src_rect.Dy())
+	banded := NewBandedImage(bounds, rows, func(dst *image.RGBA) {
+		renderParallel(dst, concurrentReaders(src, workers), func(band *image.RGBA) {
+			draw.Draw(band, band.Rect, src, src_rect.Min.Add(band.Rect.Min), draw.Src)
+			drawWatermarks(band, watermark, placements)
+		})
+	})
+	// watermarks drawn over an opaque image keep it opaque, so the JPEG encoder doesn't need to flatten the result
+	if o, ok := src.(interface{ Opaque() bool }); ok {
+		banded.opaque = o.Opaque()
+	}
+	return banded
+}
+
+// Tile places an image in a bigger canvas. The tiles of a canvas are watermarked separately with the
+// placements computed for the whole canvas, so the watermarks crossing the tile borders line up.
+type Tile struct {
+	X, Y          int
+	Width, Height int
+}
+
+// Valid reports whether an image of the given size fits in the canvas at the tile offset.
+func (t Tile) Valid(size image.Point) bool {
+	return t.X >= 0 && t.Y >= 0 && size.X <= t.Width-t.X && size.Y <= t.Height-t.Y
+}
+
+// WatermarkTile watermarks src as the part of the canvas described by tile.
+func WatermarkTile(watermark image.Image, src image.Image, fill bool, pos Position, margin image.Point, tile Tile) *image.RGBA {
+	canvas := image.Pt(tile.Width, tile.Height)
+	var placements []image.Point
+	if fill {
+		placements = fillPlacements(watermark, canvas)
+	} else {
+		placements = []image.Point{placement(watermark, canvas, pos, margin)}
+	}
+	src_rect := src.Bounds()
+	// the tile is drawn in the canvas coordinates so the placements apply as is
+	dst := image.NewRGBA(src_rect.Sub(src_rect.Min).Add(image.Pt(tile.X, tile.Y)))
+	draw.Draw(dst, dst.Rect, src, src_rect.Min, draw.Src)
+	drawWatermarks(dst, watermark, placements)
+	dst.Rect = dst.Rect.Sub(dst.Rect.Min)
+	return dst
+}
+
+// StitchBands joins the horizontal bands of the given number of rows back into one image,
+// band i is produced by load when it is read. A nil band is left transparent. Every band is loaded
+// once by the encoders if rows is aligned with AlignRows.
+func StitchBands(bounds image.Rectangle, rows int, opaque bool, load func(i int) image.Image) *BandedImage {
+	banded := NewBandedImage(bounds, rows, func(dst *image.RGBA) {
+		if band := load((dst.Rect.Min.Y - bounds.Min.Y) / max(1, rows)); band != nil {
+			draw.Draw(dst, dst.Rect, band, band.Bounds().Min, draw.Src)
+		}
+	})
+	banded.opaque = opaque
+	return banded
+}
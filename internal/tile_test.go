@@ -0,0 +1,107 @@
+package internal
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+	"watermark-service/internal/util"
+)
+
+func TestBandedImageEncoding(t *testing.T) {
+	bounds := image.Rect(0, 0, 50, 100)
+	tests := []struct {
+		name   string
+		format string
+		rows   int
+		opaque bool
+	}{
+		{"png", ".png", 7, false},
+		{"jpeg", ".jpg", BandRows(bounds.Dx(), 4*50*20), false},
+		{"opaque jpeg", ".jpg", AlignRows(40), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			renders := map[int]int{}
+			banded := NewBandedImage(bounds, tt.rows, func(dst *image.RGBA) {
+				renders[dst.Rect.Min.Y]++
+				for y := dst.Rect.Min.Y; y < dst.Rect.Max.Y; y++ {
+					for x := dst.Rect.Min.X; x < dst.Rect.Max.X; x++ {
+						dst.SetRGBA(x, y, color.RGBA{A: 128})
+					}
+				}
+			})
+			banded.opaque = tt.opaque
+			if _, err := util.EncodeImage(banded, tt.format, 0); err != nil {
+				t.Fatal(err)
+			}
+			if want := (bounds.Dy() + tt.rows - 1) / tt.rows; len(renders) != want {
+				t.Errorf("rendered %d bands, want %d", len(renders), want)
+			}
+			for top, n := range renders {
+				if n != 1 {
+					t.Errorf("band at %d rendered %d times", top, n)
+				}
+			}
+		})
+	}
+}
+
+func TestBandedImageFlatten(t *testing.T) {
+	banded := NewBandedImage(image.Rect(0, 0, 4, 40), AlignRows(1), func(dst *image.RGBA) {
+		for y := dst.Rect.Min.Y; y < dst.Rect.Max.Y; y++ {
+			for x := dst.Rect.Min.X; x < dst.Rect.Max.X; x++ {
+				dst.SetRGBA(x, y, color.RGBA{R: 100, A: 200})
+			}
+		}
+	})
+	flat := banded.Flatten()
+	if o, ok := flat.(interface{ Opaque() bool }); !ok || !o.Opaque() {
+		t.Fatal("flattened image is not opaque")
+	}
+	want := color.RGBA{R: 155, G: 55, B: 55, A: 255}
+	for _, y := range []int{0, 17, 39} {
+		if got := flat.At(1, y); got != want {
+			t.Errorf("At(1, %d) = %v, want %v", y, got, want)
+		}
+	}
+}
+
+func TestBandRows(t *testing.T) {
+	tests := []struct {
+		width int
+		limit int64
+		want  int
+	}{
+		{100, 400 * 100, 96},
+		{100, 400 * 16, 16},
+		{100, 1, 16},
+		{1 << 20, 1 << 62, 2147483632},
+	}
+	for _, tt := range tests {
+		if got := BandRows(tt.width, tt.limit); got != tt.want {
+			t.Errorf("BandRows(%d, %d) = %d, want %d", tt.width, tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestTileValid(t *testing.T) {
+	tests := []struct {
+		name string
+		tile Tile
+		size image.Point
+		want bool
+	}{
+		{"inside", Tile{X: 10, Y: 20, Width: 100, Height: 100}, image.Pt(90, 80), true},
+		{"too wide", Tile{X: 10, Y: 20, Width: 100, Height: 100}, image.Pt(91, 80), false},
+		{"negative offset", Tile{X: -1, Width: 100, Height: 100}, image.Pt(10, 10), false},
+		{"overflowing offset", Tile{X: math.MaxInt, Y: 0, Width: 100, Height: 100}, image.Pt(10, 10), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.tile.Valid(tt.size); got != tt.want {
+				t.Errorf("Valid() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package internal
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"golang.org/x/image/draw"
+)
+
+// Crop copies the part of src inside r, r is clipped to the image bounds first.
+func Crop(src image.Image, r image.Rectangle) *image.RGBA {
+	r = r.Add(src.Bounds().Min).Intersect(src.Bounds())
+	dst := image.NewRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	draw.Draw(dst, dst.Rect, src, r.Min, draw.Src)
+	return dst
+}
+
+// SmartCropSize is the size of the largest window of the given aspect ratio fitting in size.
+func SmartCropSize(size image.Point, aspectW, aspectH int) image.Point {
+	cw, ch := size.X, size.X*aspectH/aspectW
+	if ch > size.Y {
+		cw, ch = size.Y*aspectW/aspectH, size.Y
+	}
+	return image.Pt(cw, ch)
+}
+
+// ResizedSize is the size of an image of the given size resized to width x height, a missing side
+// keeps the aspect ratio.
+func ResizedSize(size image.Point, width, height int) image.Point {
+	if width == 0 {
+		width = max(1, size.X*height/size.Y)
+	}
+	if height == 0 {
+		height = max(1, size.Y*width/size.X)
+	}
+	return image.Pt(width, height)
+}
+
+// SmartCrop crops src to the given aspect ratio, keeping the window with the most detail.
+// Detail is measured as the gradient energy of a downscaled copy of the image.
+func SmartCrop(src image.Image, aspectW, aspectH int) *image.RGBA {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	crop := SmartCropSize(b.Size(), aspectW, aspectH)
+	cw, ch := crop.X, crop.Y
+	if cw == w && ch == h {
+		return Crop(src, image.Rect(0, 0, w, h))
+	}
+
+	// a coarse energy map is enough to find the interesting region and keeps big images cheap
+	const maxSide = 256
+	scale := math.Min(1, float64(maxSide)/float64(max(w, h)))
+	sw, sh := max(1, int(float64(w)*scale)), max(1, int(float64(h)*scale))
+	small := image.NewGray(image.Rect(0, 0, sw, sh))
+	draw.ApproxBiLinear.Scale(small, small.Rect, src, b, draw.Src, nil)
+
+	horizontal := cw < w
+	length := sh
+	if horizontal {
+		length = sw
+	}
+	energy := make([]float64, length)
+	for y := 0; y < sh; y++ {
+		for x := 0; x < sw; x++ {
+			c := float64(small.GrayAt(x, y).Y)
+			var e float64
+			if x+1 < sw {
+				e += math.Abs(c - float64(small.GrayAt(x+1, y).Y))
+			}
+			if y+1 < sh {
+				e += math.Abs(c - float64(small.GrayAt(x, y+1).Y))
+			}
+			if horizontal {
+				energy[x] += e
+			} else {
+				energy[y] += e
+			}
+		}
+	}
+
+	window := int(float64(cw) * scale)
+	full := w
+	if !horizontal {
+		window, full = int(float64(ch)*scale), h
+	}
+	window = max(1, min(window, length))
+	best, bestSum, sum := 0, -1.0, 0.0
+	for i := 0; i < length; i++ {
+		sum += energy[i]
+		if i >= window {
+			sum -= energy[i-window]
+		}
+		if i >= window-1 && sum > bestSum {
+			best, bestSum = i-window+1, sum
+		}
+	}
+	offset := min(int(float64(best)/scale), full-cw)
+	if !horizontal {
+		offset = min(int(float64(best)/scale), full-ch)
+		return Crop(src, image.Rect(0, offset, cw, offset+ch))
+	}
+	return Crop(src, image.Rect(offset, 0, offset+cw, ch))
+}
+
+// Pad scales src to fit inside width x height keeping its aspect ratio and fills the rest with col.
+func Pad(src image.Image, width, height int, col color.Color, interp Interpolation) *image.RGBA {
+	b := src.Bounds()
+	w, h := width, b.Dy()*width/b.Dx()
+	if h > height {
+		w, h = b.Dx()*height/b.Dy(), height
+	}
+	w, h = max(1, w), max(1, h)
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(dst, dst.Rect, image.NewUniform(col), image.Point{}, draw.Src)
+	offset := image.Pt((width-w)/2, (height-h)/2)
+	draw.Draw(dst, image.Rect(0, 0, w, h).Add(offset), Resize(src, w, h, interp), image.Point{}, draw.Over)
+	return dst
+}
+
+// Rotate turns src clockwise by a multiple of 90 degrees.
+func Rotate(src image.Image, angle int) *image.RGBA {
+	b := src.Bounds()
+	angle = ((angle % 360) + 360) % 360
+	var dst *image.RGBA
+	if angle == 90 || angle == 270 {
+		dst = image.NewRGBA(image.Rect(0, 0, b.Dy(), b.Dx()))
+	} else {
+		dst = image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	}
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			c := src.At(b.Min.X+x, b.Min.Y+y)
+			switch angle {
+			case 90:
+				dst.Set(b.Dy()-1-y, x, c)
+			case 180:
+				dst.Set(b.Dx()-1-x, b.Dy()-1-y, c)
+			case 270:
+				dst.Set(y, b.Dx()-1-x, c)
+			default:
+				dst.Set(x, y, c)
+			}
+		}
+	}
+	return dst
+}
+
+func Flip(src image.Image, horizontal bool) *image.RGBA {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			c := src.At(b.Min.X+x, b.Min.Y+y)
+			if horizontal {
+				dst.Set(b.Dx()-1-x, y, c)
+			} else {
+				dst.Set(x, b.Dy()-1-y, c)
+			}
+		}
+	}
+	return dst
+}
+
+// Adjust changes brightness and contrast, both in [-1, 1] where 0 leaves the image untouched.
+func Adjust(src image.Image, brightness, contrast float64) *image.RGBA {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Rect, src, b.Min, draw.Src)
+	var lut [256]uint8
+	for i := range lut {
+		v := (float64(i)-127.5)*(1+contrast) + 127.5 + brightness*255
+		lut[i] = uint8(math.Max(0, math.Min(255, math.Round(v))))
+	}
+	for i := 0; i < len(dst.Pix); i += 4 {
+		// pixels are alpha premultiplied, the lookup is done on the straight color
+		a := dst.Pix[i+3]
+		if a == 0 {
+			continue
+		}
+		for c := 0; c < 3; c++ {
+			v := uint32(dst.Pix[i+c]) * 255 / uint32(a)
+			dst.Pix[i+c] = uint8(uint32(lut[v]) * uint32(a) / 255)
+		}
+	}
+	return dst
+}
+
+// Redact hides r either with a solid color or by pixelating it.
+func Redact(src image.Image, r image.Rectangle, pixelate bool, col color.Color) *image.RGBA {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Rect, src, b.Min, draw.Src)
+	r = r.Intersect(dst.Rect)
+	if !pixelate {
+		draw.Draw(dst, r, image.NewUniform(col), image.Point{}, draw.Src)
+		return dst
+	}
+	block := max(8, min(r.Dx(), r.Dy())/10)
+	for y := r.Min.Y; y < r.Max.Y; y += block {
+		for x := r.Min.X; x < r.Max.X; x += block {
+			cell := image.Rect(x, y, x+block, y+block).Intersect(r)
+			var sr, sg, sb, sa, n uint32
+			for cy := cell.Min.Y; cy < cell.Max.Y; cy++ {
+				for cx := cell.Min.X; cx < cell.Max.X; cx++ {
+					c := dst.RGBAAt(cx, cy)
+					sr, sg, sb, sa, n = sr+uint32(c.R), sg+uint32(c.G), sb+uint32(c.B), sa+uint32(c.A), n+1
+				}
+			}
+			avg := color.RGBA{uint8(sr / n), uint8(sg / n), uint8(sb / n), uint8(sa / n)}
+			draw.Draw(dst, cell, image.NewUniform(avg), image.Point{}, draw.Src)
+		}
+	}
+	return dst
+}
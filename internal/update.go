@@ -0,0 +1,19 @@
+package internal
+
+// Update is a change of a document or a job pushed to its owner by Watch, Event is one of
+// the webhook events or EventJobProgress.
+type Update struct {
+	Event    string            `json:"event"`
+	TicketID string            `json:"ticketID,omitempty"`
+	Status   Status            `json:"status,omitempty"`
+	Progress int32             `json:"progress,omitempty"`
+	ImageUrl string            `json:"image_url,omitempty"`
+	Outputs  map[string]string `json:"outputs,omitempty"`
+	Err      string            `json:"error,omitempty"`
+}
+
+// StreamToken opens the update stream of its user until ExpiresAt, a unix time.
+type StreamToken struct {
+	Token     string `json:"token"`
+	ExpiresAt int64  `json:"expires_at"`
+}
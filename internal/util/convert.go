@@ -3,27 +3,18 @@ package util
 import (
 	"bytes"
 	"image"
+	"image/draw"
 	"image/jpeg"
 	"image/png"
 )
 
+// ByteToImage returns nil for images which can't be decoded or exceed Limits, use DecodeImage to tell them apart.
 func ByteToImage(image []byte, encoding string) image.Image {
-	switch encoding {
-	case ".png":
-		img, err := png.Decode(bytes.NewReader(image))
-		if err != nil {
-			return nil
-		}
-		return img
-	case ".jpg":
-		img, err := jpeg.Decode(bytes.NewReader(image))
-		if err != nil {
-			return nil
-		}
-		return img
-	default:
+	img, err := DecodeImage(bytes.NewReader(image), encoding)
+	if err != nil {
 		return nil
 	}
+	return img
 }
 
 func ImageToBytes(image image.Image, encoding string) []byte {
@@ -39,3 +30,36 @@ func ImageToBytes(image image.Image, encoding string) []byte {
 		return nil
 	}
 }
+
+// EncodeImage works like ImageToBytes but allows to set the JPEG quality, images with transparency
+// are put on a white background before the JPEG encoding. Images which can flatten themselves,
+// like the banded ones, are not copied for that.
+func EncodeImage(img image.Image, encoding string, quality int) ([]byte, error) {
+	var buffer bytes.Buffer
+	switch encoding {
+	case ".png":
+		if err := png.Encode(&buffer, img); err != nil {
+			return nil, err
+		}
+	case ".jpg":
+		if f, ok := img.(interface{ Flatten() image.Image }); ok {
+			img = f.Flatten()
+		} else if o, ok := img.(interface{ Opaque() bool }); !ok || !o.Opaque() {
+			b := img.Bounds()
+			flat := image.NewRGBA(b)
+			draw.Draw(flat, b, image.White, image.Point{}, draw.Src)
+			draw.Draw(flat, b, img, b.Min, draw.Over)
+			img = flat
+		}
+		var opts *jpeg.Options
+		if quality > 0 {
+			opts = &jpeg.Options{Quality: quality}
+		}
+		if err := jpeg.Encode(&buffer, img, opts); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrInvalidArg
+	}
+	return buffer.Bytes(), nil
+}
@@ -1,6 +1,11 @@
 package util
 
-import "errors"
+import (
+	"errors"
+	"math"
+	"strings"
+	"time"
+)
 
 var (
 	ErrUnknownArg = errors.New("unknown argument passed")
@@ -8,11 +13,45 @@ var (
 	ErrInvalidArg = errors.New("invalid argument passed")
 
 	ErrDatabaseServiceUnavailable = errors.New("database service unavailable")
+
+	ErrForbidden = errors.New("permission denied")
 )
 
 func FromString(s string) error {
-	if s == "" {
+	switch s {
+	case "":
 		return nil
+	case ErrUnknownArg.Error():
+		return ErrUnknownArg
+	case ErrInvalidArg.Error():
+		return ErrInvalidArg
+	case ErrDatabaseServiceUnavailable.Error():
+		return ErrDatabaseServiceUnavailable
+	case ErrTooLarge.Error():
+		return ErrTooLarge
+	case ErrForbidden.Error():
+		return ErrForbidden
+	}
+	if d, ok := strings.CutPrefix(s, overloadedPrefix); ok {
+		if retryAfter, err := time.ParseDuration(d); err == nil {
+			return &OverloadedError{RetryAfter: retryAfter}
+		}
 	}
 	return errors.New(s)
 }
+
+const overloadedPrefix = "service is overloaded, retry after "
+
+// OverloadedError rejects a request when the service is saturated, RetryAfter hints when to try again.
+type OverloadedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *OverloadedError) Error() string {
+	return overloadedPrefix + e.RetryAfter.String()
+}
+
+// Seconds rounds the retry hint up to whole seconds as expected by the Retry-After headers.
+func (e *OverloadedError) Seconds() int {
+	return max(1, int(math.Ceil(e.RetryAfter.Seconds())))
+}
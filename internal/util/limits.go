@@ -0,0 +1,121 @@
+package util
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"math"
+	"watermark-service/internal/svg"
+)
+
+var ErrTooLarge = errors.New("image exceeds the size limits")
+
+// ImageLimits protect the services from oversized uploads and decompression bombs,
+// a zero field means no limit.
+type ImageLimits struct {
+	MaxBytes      int64
+	MaxWidth      int
+	MaxHeight     int
+	MaxMegapixels float64
+}
+
+var DefaultLimits = ImageLimits{
+	MaxBytes:      32 << 20,
+	MaxWidth:      30000,
+	MaxHeight:     30000,
+	MaxMegapixels: 400,
+}
+
+// Limits are checked by every transport before an image is decoded.
+var Limits = DefaultLimits
+
+// SetLimits replaces the limits, the zero fields keep their default values.
+func SetLimits(l ImageLimits) {
+	if l.MaxBytes <= 0 {
+		l.MaxBytes = DefaultLimits.MaxBytes
+	}
+	if l.MaxWidth <= 0 {
+		l.MaxWidth = DefaultLimits.MaxWidth
+	}
+	if l.MaxHeight <= 0 {
+		l.MaxHeight = DefaultLimits.MaxHeight
+	}
+	if l.MaxMegapixels <= 0 {
+		l.MaxMegapixels = DefaultLimits.MaxMegapixels
+	}
+	Limits = l
+}
+
+func (l ImageLimits) CheckBytes(n int64) error {
+	if l.MaxBytes > 0 && n > l.MaxBytes {
+		return ErrTooLarge
+	}
+	return nil
+}
+
+// CheckDimensions rejects empty images as invalid and the ones above the limits as too large.
+// The pixel count is compared without multiplying the sides, so huge sides can't overflow it.
+func (l ImageLimits) CheckDimensions(width, height int) error {
+	if width <= 0 || height <= 0 {
+		return ErrInvalidArg
+	}
+	if l.MaxWidth > 0 && width > l.MaxWidth || l.MaxHeight > 0 && height > l.MaxHeight {
+		return ErrTooLarge
+	}
+	if l.MaxMegapixels > 0 && l.MaxMegapixels*1e6 < math.MaxInt64 {
+		if int64(width) > int64(l.MaxMegapixels*1e6)/int64(height) {
+			return ErrTooLarge
+		}
+	}
+	return nil
+}
+
+// CheckImage returns the bounds of the encoded image checking Limits, only the header is decoded.
+func CheckImage(data []byte, encoding string) (image.Rectangle, error) {
+	if err := Limits.CheckBytes(int64(len(data))); err != nil {
+		return image.Rectangle{}, err
+	}
+	var cfg image.Config
+	var err error
+	switch encoding {
+	case ".png":
+		cfg, err = png.DecodeConfig(bytes.NewReader(data))
+	case ".jpg":
+		cfg, err = jpeg.DecodeConfig(bytes.NewReader(data))
+	case ".svg":
+		img, err := svg.Parse(bytes.NewReader(data))
+		if err != nil {
+			return image.Rectangle{}, ErrInvalidArg
+		}
+		// vector images are rasterized at their natural size when used as is
+		return img.Bounds(), Limits.CheckDimensions(img.Bounds().Dx(), img.Bounds().Dy())
+	default:
+		return image.Rectangle{}, ErrInvalidArg
+	}
+	if err != nil {
+		return image.Rectangle{}, err
+	}
+	return image.Rect(0, 0, cfg.Width, cfg.Height), Limits.CheckDimensions(cfg.Width, cfg.Height)
+}
+
+// DecodeImage reads an image of the given type checking Limits first, the dimensions are taken
+// from the header so oversized images are rejected before any pixel memory is allocated.
+func DecodeImage(r io.Reader, encoding string) (image.Image, error) {
+	data, err := io.ReadAll(io.LimitReader(r, Limits.MaxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := CheckImage(data, encoding); err != nil {
+		return nil, err
+	}
+	switch encoding {
+	case ".png":
+		return png.Decode(bytes.NewReader(data))
+	case ".jpg":
+		return jpeg.Decode(bytes.NewReader(data))
+	}
+	return svg.Parse(bytes.NewReader(data))
+}
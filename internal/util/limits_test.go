@@ -0,0 +1,67 @@
+package util
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"math"
+	"testing"
+)
+
+func TestCheckDimensions(t *testing.T) {
+	limits := ImageLimits{MaxWidth: 1000, MaxHeight: 1000, MaxMegapixels: 0.5}
+	tests := []struct {
+		name          string
+		limits        ImageLimits
+		width, height int
+		err           error
+	}{
+		{"fits", limits, 500, 1000, nil},
+		{"zero width", limits, 0, 10, ErrInvalidArg},
+		{"negative height", limits, 10, -1, ErrInvalidArg},
+		{"too wide", limits, 1001, 1, ErrTooLarge},
+		{"too high", limits, 1, 1001, ErrTooLarge},
+		{"too many pixels", limits, 501, 1000, ErrTooLarge},
+		{"overflowing sides", ImageLimits{MaxMegapixels: 400}, math.MaxInt, math.MaxInt, ErrTooLarge},
+		{"overflowing product", ImageLimits{MaxMegapixels: 400}, 1 << 32, 1 << 32, ErrTooLarge},
+		{"no limits", ImageLimits{}, math.MaxInt, math.MaxInt, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.limits.CheckDimensions(tt.width, tt.height); err != tt.err {
+				t.Errorf("CheckDimensions(%d, %d) = %v, want %v", tt.width, tt.height, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestCheckImage(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 40, 30))); err != nil {
+		t.Fatal(err)
+	}
+	defer SetLimits(Limits)
+	tests := []struct {
+		name   string
+		limits ImageLimits
+		data   []byte
+		format string
+		err    error
+	}{
+		{"png", DefaultLimits, buf.Bytes(), ".png", nil},
+		{"too wide", ImageLimits{MaxWidth: 39}, buf.Bytes(), ".png", ErrTooLarge},
+		{"too many bytes", ImageLimits{MaxBytes: 10}, buf.Bytes(), ".png", ErrTooLarge},
+		{"unknown format", DefaultLimits, buf.Bytes(), ".gif", ErrInvalidArg},
+		{"svg", DefaultLimits, []byte(`<svg xmlns="http://www.w3.org/2000/svg" width="40" height="30"/>`), ".svg", nil},
+		{"invalid svg", DefaultLimits, []byte(`<svg width="0" height="30"/>`), ".svg", ErrInvalidArg},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetLimits(tt.limits)
+			_, err := CheckImage(tt.data, tt.format)
+			if err != tt.err {
+				t.Errorf("CheckImage() = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
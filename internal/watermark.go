@@ -3,12 +3,12 @@ package internal
 import (
 	"image"
 	"image/color"
+	"sync"
 
 	"golang.org/x/image/draw"
 	"golang.org/x/image/math/fixed"
 
 	"golang.org/x/image/font"
-	"golang.org/x/image/font/basicfont"
 )
 
 type Position string
@@ -20,6 +20,12 @@ const (
 	RightBottom Position = "right_bottom"
 )
 
+// VectorImage is implemented by logos that can be rendered at any size without losing quality, such as SVG.
+type VectorImage interface {
+	image.Image
+	Rasterize(width, height int) image.Image
+}
+
 func PositionFromString(text string) Position {
 	switch text {
 	case "left_top":
@@ -34,23 +40,25 @@ func PositionFromString(text string) Position {
 	return LeftTop
 }
 
-func CombineTextWithLogo(logo image.Image, text string) image.Image {
+func CombineTextWithLogo(logo image.Image, text string, interp Interpolation) image.Image {
 	if text != "" {
 		var logo_new *image.RGBA
 		var text_x, text_y fixed.Int26_6
 
 		space_between := 20
 
-		text_width := font.MeasureString(basicfont.Face7x13, text).Ceil()
-		text_height := basicfont.Face7x13.Metrics().Ascent.Ceil()
+		face, release := TextFace()
+		defer release()
+		text = VisualOrder(text)
+		text_width := font.MeasureString(face, text).Ceil()
+		text_height := face.Metrics().Ascent.Ceil()
 
 		if logo != nil {
 			logo_rect := logo.Bounds()
 			//scaling logo
 			adjacent_h := text_height * 4
 			multiplier := float64(adjacent_h) / float64(logo_rect.Dy())
-			logo_img := image.NewRGBA(image.Rect(0, 0, int(float64(logo_rect.Dx())*multiplier), adjacent_h))
-			draw.ApproxBiLinear.Scale(logo_img, logo_img.Rect, logo, logo_rect, draw.Over, nil)
+			logo_img := Resize(logo, int(float64(logo_rect.Dx())*multiplier), adjacent_h, interp)
 			//adding extra space for text
 			logo_new = image.NewRGBA(image.Rect(0, 0, logo_img.Rect.Dx()+space_between+text_width, logo_img.Rect.Dy()))
 			draw.Draw(logo_new, logo_img.Rect, logo_img, image.Point{0, 0}, draw.Over)
@@ -65,7 +73,7 @@ func CombineTextWithLogo(logo image.Image, text string) image.Image {
 		d := font.Drawer{
 			Dst:  logo_new,
 			Src:  image.NewUniform(col),
-			Face: basicfont.Face7x13,
+			Face: face,
 			Dot:  point,
 		}
 		d.DrawString(text)
@@ -78,54 +86,109 @@ func CombineTextWithLogo(logo image.Image, text string) image.Image {
 }
 
 func FillImageWithWatermarks(watermark image.Image, src image.Image) draw.Image {
-	const space = 20
+	return FillImageWithWatermarksParallel(watermark, src, 1)
+}
+
+// FillImageWithWatermarksParallel splits the result into disjoint horizontal bands composited by the given number of workers.
+func FillImageWithWatermarksParallel(watermark image.Image, src image.Image, workers int) draw.Image {
 	src_rect := src.Bounds()
-	wtm_rect := watermark.Bounds()
+	bg := image.NewRGBA(image.Rect(0, 0, src_rect.Dx(), src_rect.Dy()))
+	placements := fillPlacements(watermark, src_rect.Size())
+	renderParallel(bg, concurrentReaders(src, workers), func(band *image.RGBA) {
+		draw.Draw(band, band.Rect, src, src_rect.Min.Add(band.Rect.Min), draw.Src)
+		drawWatermarks(band, watermark, placements)
+	})
+	return bg
+}
+
+// concurrentReaders limits the workers to one for sources which can't be read concurrently.
+func concurrentReaders(src image.Image, workers int) int {
+	if _, ok := src.(*BandedImage); ok {
+		return 1
+	}
+	return workers
+}
+
+// renderParallel calls render for disjoint horizontal strips of dst, one goroutine per strip.
+func renderParallel(dst *image.RGBA, workers int, render func(band *image.RGBA)) {
+	r := dst.Rect
+	workers = max(1, min(workers, r.Dy()))
+	if workers == 1 {
+		render(dst)
+		return
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		band := image.Rect(r.Min.X, r.Min.Y+r.Dy()*i/workers, r.Max.X, r.Min.Y+r.Dy()*(i+1)/workers)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			render(dst.SubImage(band).(*image.RGBA))
+		}()
+	}
+	wg.Wait()
+}
+
+func AddWatermarkToImage(watermark image.Image, src image.Image, pos Position) draw.Image {
+	return AddWatermarkToImageWithMargin(watermark, src, pos, image.Point{})
+}
 
+// AddWatermarkToImageWithMargin keeps the watermark margin.X and margin.Y pixels away from the nearest edges.
+func AddWatermarkToImageWithMargin(watermark image.Image, src image.Image, pos Position, margin image.Point) draw.Image {
+	src_rect := src.Bounds()
 	bg := image.NewRGBA(image.Rect(0, 0, src_rect.Dx(), src_rect.Dy()))
 	draw.Draw(bg, src_rect, src, image.Point{0, 0}, draw.Over)
-	//applying opacity mask to watermark
-	mask := image.NewUniform(color.Alpha{96})
+	drawWatermarks(bg, watermark, []image.Point{placement(watermark, src_rect.Size(), pos, margin)})
+	return bg
+}
 
+// fillPlacements returns the top left corners of the watermarks covering an image of the given size.
+func fillPlacements(watermark image.Image, size image.Point) []image.Point {
+	const space = 20
+	wtm_rect := watermark.Bounds()
+
+	var res []image.Point
 	offset := image.Pt(0, 0)
 	step_x := image.Pt(wtm_rect.Dx()+space, 0)
 	step_y := image.Pt(0, (wtm_rect.Dy()+space)*2)
 	counter := 0
-	for offset.X < src_rect.Dx() {
-		for ; offset.Y < src_rect.Dy(); counter++ {
+	for offset.X < size.X {
+		for ; offset.Y < size.Y; counter++ {
 			if counter%2 == 0 {
-				draw.DrawMask(bg, src_rect.Add(offset), watermark, image.Point{0, 0}, mask, image.Point{0, 0}, draw.Over)
+				res = append(res, offset)
 			}
 			offset = offset.Add(step_y)
 		}
 		offset.Y = 0
 		offset = offset.Add(step_x)
 	}
-	return bg
+	return res
 }
 
-func AddWatermarkToImage(watermark image.Image, src image.Image, pos Position) draw.Image {
-	src_rect := src.Bounds()
+func placement(watermark image.Image, size image.Point, pos Position, margin image.Point) image.Point {
 	wtm_rect := watermark.Bounds()
-
-	var offset image.Point
 	switch pos {
-	case LeftTop:
-		offset = image.Pt(0, 0)
 	case RightTop:
-		offset = image.Pt(src_rect.Dx()-wtm_rect.Bounds().Dx(), 0)
+		return image.Pt(size.X-wtm_rect.Dx()-margin.X, margin.Y)
 	case LeftBottom:
-		offset = image.Pt(0, src_rect.Dy()-wtm_rect.Bounds().Dy())
+		return image.Pt(margin.X, size.Y-wtm_rect.Dy()-margin.Y)
 	case RightBottom:
-		offset = image.Pt(src_rect.Dx()-wtm_rect.Bounds().Dx(), src_rect.Dy()-wtm_rect.Bounds().Dy())
+		return image.Pt(size.X-wtm_rect.Dx()-margin.X, size.Y-wtm_rect.Dy()-margin.Y)
 	default:
-		offset = image.Pt(0, 0)
+		return image.Pt(margin.X, margin.Y)
 	}
+}
 
-	bg := image.NewRGBA(image.Rect(0, 0, src_rect.Dx(), src_rect.Dy()))
-	draw.Draw(bg, src_rect, src, image.Point{0, 0}, draw.Over)
+// drawWatermarks blends the watermark at every placement intersecting dst.
+func drawWatermarks(dst draw.Image, watermark image.Image, placements []image.Point) {
 	//applying opacity mask to watermark
 	mask := image.NewUniform(color.Alpha{96})
-	draw.DrawMask(bg, src_rect.Add(offset), watermark, image.Point{0, 0}, mask, image.Point{0, 0}, draw.Over)
-	return bg
+	wtm_rect := watermark.Bounds()
+	for _, p := range placements {
+		r := image.Rect(0, 0, wtm_rect.Dx(), wtm_rect.Dy()).Add(p)
+		if !r.Overlaps(dst.Bounds()) {
+			continue
+		}
+		draw.DrawMask(dst, r, watermark, wtm_rect.Min, mask, image.Point{0, 0}, draw.Over)
+	}
 }
@@ -0,0 +1,106 @@
+package watermark
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"watermark-service/internal"
+
+	"github.com/google/uuid"
+)
+
+// FilesPath separates the base URL of a FilesystemStorage from the keys of its files.
+const FilesPath = "/files/"
+
+var (
+	fileKey = regexp.MustCompile(`^[0-9a-f]{2}/[0-9a-f]{2}/[0-9a-f-]{36}(\.[0-9a-z]+)?$`)
+	fileExt = regexp.MustCompile(`^\.[0-9a-z]+$`)
+)
+
+// FilesystemStorage keeps the files under root in directories sharded by the first bytes of their
+// random names, a file is written to a temporary one and renamed so a reader never sees it partially.
+// The files aren't served, their URLs start with baseURL and only identify them, the service reads them
+// for the signed download links.
+type FilesystemStorage struct {
+	root    string
+	baseURL string
+}
+
+func NewFilesystemStorage(root, baseURL string) (*FilesystemStorage, error) {
+	if err := os.MkdirAll(filepath.Join(root, "tmp"), 0755); err != nil {
+		return nil, err
+	}
+	return &FilesystemStorage{root: root, baseURL: strings.TrimSuffix(baseURL, "/")}, nil
+}
+
+func (s *FilesystemStorage) Upload(ctx context.Context, name string, image io.Reader) (string, error) {
+	span := internal.StartSpan("Filesystem upload", ctx)
+	defer span.Finish()
+	id := uuid.NewString()
+	ext := strings.ToLower(path.Ext(name))
+	if !fileExt.MatchString(ext) {
+		ext = ""
+	}
+	key := path.Join(id[:2], id[2:4], id+ext)
+	dst := filepath.Join(s.root, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return "", err
+	}
+	tmp, err := os.CreateTemp(filepath.Join(s.root, "tmp"), "upload-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, image); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), dst); err != nil {
+		return "", err
+	}
+	return s.baseURL + FilesPath + key, nil
+}
+
+func (s *FilesystemStorage) Open(ctx context.Context, url string) (io.ReadCloser, error) {
+	file, err := s.path(url)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+func (s *FilesystemStorage) Delete(ctx context.Context, url string) error {
+	file, err := s.path(url)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// path maps the URL of a stored file to its location, anything else is rejected.
+func (s *FilesystemStorage) path(url string) (string, error) {
+	_, key, ok := strings.Cut(url, FilesPath)
+	if !ok || !fileKey.MatchString(key) {
+		return "", errors.New("Invalid url")
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
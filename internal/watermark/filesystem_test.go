@@ -0,0 +1,56 @@
+package watermark
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"watermark-service/internal"
+
+	"github.com/opentracing/opentracing-go"
+)
+
+func TestFilesystemStorage(t *testing.T) {
+	internal.Tracer = opentracing.NoopTracer{}
+	s, err := NewFilesystemStorage(t.TempDir(), "http://files.test/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	url, err := s.Upload(ctx, "Image.PNG", strings.NewReader("data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(url, "http://files.test"+FilesPath) || !strings.HasSuffix(url, ".png") {
+		t.Errorf("Upload() = %q", url)
+	}
+	r, err := s.Open(ctx, url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(r)
+	r.Close()
+	if string(data) != "data" {
+		t.Errorf("Open() read %q", data)
+	}
+	if err := s.Delete(ctx, url); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Open(ctx, url); err != ErrNotFound {
+		t.Errorf("Open() of a deleted file = %v", err)
+	}
+	if err := s.Delete(ctx, url); err != nil {
+		t.Errorf("Delete() of a deleted file = %v", err)
+	}
+
+	for _, url := range []string{
+		"http://files.test/other/ab/cd/file.png",
+		"http://files.test" + FilesPath + "../../etc/passwd",
+		"http://files.test" + FilesPath + "ab/cd/../../../secret",
+		"http://files.test" + FilesPath + "tmp/upload-1",
+	} {
+		if _, err := s.Open(ctx, url); err == nil || err == ErrNotFound {
+			t.Errorf("Open(%q) error = %v, want a rejected url", url, err)
+		}
+	}
+}
@@ -1,16 +1,25 @@
 package watermark
 
 import (
+	"time"
+	"watermark-service/internal"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+// Document is a stored result, ImageUrl identifies it and Locations are its copies in the storage backends.
+// The documents stored before the backends were recorded have no Locations, they are held by the primary backend.
+// The results of a job are unique by JobID and Preset, so a retried job doesn't store them twice.
 type Document struct {
 	gorm.Model
-	ID       uuid.UUID `gorm:"type:uuid;primary_key"`
-	AuthorId int32     `gorm:"not null"`
-	Title    string    `gorm:"type:varchar(255);not null"`
-	ImageUrl string    `gorm:"type:text;uniqueIndex;not null"`
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key"`
+	AuthorId  int32      `gorm:"not null"`
+	Title     string     `gorm:"type:varchar(255);not null"`
+	ImageUrl  string     `gorm:"type:text;uniqueIndex;not null"`
+	Locations Locations  `gorm:"serializer:json"`
+	JobID     *uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_documents_job_preset,priority:1"`
+	Preset    string     `gorm:"type:varchar(32);not null;default:'';uniqueIndex:idx_documents_job_preset,priority:2"`
 }
 
 func (d *Document) BeforeCreate(*gorm.DB) error {
@@ -19,6 +28,100 @@ func (d *Document) BeforeCreate(*gorm.DB) error {
 	return nil
 }
 
+// Job keeps an asynchronous Add with its request, the workers claim the runnable jobs ordered by RunAt
+// and hold them until LeaseUntil. The request images are staged in the storage backends, the job keeps
+// their locations. They are deleted once the job is done, a dead-lettered job keeps them to be requeued.
+type Job struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key"`
+	AuthorId   int32     `gorm:"not null;index"`
+	Status     string    `gorm:"type:varchar(16);not null;index:idx_jobs_queue,priority:1"`
+	Progress   int32     `gorm:"not null;default:0"`
+	Attempts   int32     `gorm:"not null;default:0"`
+	RunAt      time.Time `gorm:"not null;index:idx_jobs_queue,priority:2"`
+	LeaseUntil *time.Time
+	Error      string            `gorm:"type:text"`
+	ImageUrl   string            `gorm:"type:text"`
+	Outputs    map[string]string `gorm:"serializer:json"`
+
+	ImageLocations Locations         `gorm:"serializer:json"`
+	ImageFormat    string            `gorm:"type:varchar(16)"`
+	LogoLocations  Locations         `gorm:"serializer:json"`
+	LogoFormat     string            `gorm:"type:varchar(16)"`
+	Params         JobParams         `gorm:"serializer:json"`
+	Trace          map[string]string `gorm:"serializer:json"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// JobParams are the Add arguments besides the images.
+type JobParams struct {
+	Text        string               `json:"text"`
+	Fill        bool                 `json:"fill"`
+	Pos         internal.Position    `json:"pos"`
+	LogoOptions internal.LogoOptions `json:"logo_options"`
+	Presets     []string             `json:"presets,omitempty"`
+}
+
+func (j *Job) BeforeCreate(*gorm.DB) error {
+	if j.ID == uuid.Nil {
+		j.ID = uuid.New()
+	}
+	return nil
+}
+
+type Webhook struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key"`
+	AuthorId  int32     `gorm:"not null;index"`
+	URL       string    `gorm:"type:text;not null"`
+	Secret    string    `gorm:"type:varchar(64);not null"`
+	Events    []string  `gorm:"serializer:json"`
+	CreatedAt time.Time
+}
+
+func (w *Webhook) BeforeCreate(*gorm.DB) error {
+	w.ID = uuid.New()
+	return nil
+}
+
+// ShareLink is a public link of a document, Views are counted against MaxViews unless it's 0.
+// PasswordHash is empty when the link isn't protected.
+type ShareLink struct {
+	ID           uuid.UUID `gorm:"type:uuid;primary_key"`
+	DocumentID   uuid.UUID `gorm:"type:uuid;not null;index"`
+	AuthorId     int32     `gorm:"not null;index"`
+	PasswordHash string    `gorm:"type:varchar(60)"`
+	ExpiresAt    *time.Time
+	MaxViews     int32 `gorm:"not null;default:0"`
+	Views        int32 `gorm:"not null;default:0"`
+	RevokedAt    *time.Time
+	CreatedAt    time.Time
+	// FailedAttempts counts the wrong passwords since the last opening or lock, LockedUntil stops the guessing.
+	FailedAttempts int32 `gorm:"not null;default:0"`
+	LockedUntil    *time.Time
+}
+
+func (l *ShareLink) BeforeCreate(*gorm.DB) error {
+	l.ID = uuid.New()
+	return nil
+}
+
+// WebhookDelivery is an event queued for a webhook, the signed payload is kept for the retries.
+type WebhookDelivery struct {
+	ID           uuid.UUID `gorm:"type:uuid;primary_key"`
+	WebhookID    uuid.UUID `gorm:"type:uuid;not null;index"`
+	Event        string    `gorm:"type:varchar(32);not null"`
+	Payload      []byte    `gorm:"type:bytea;not null"`
+	Status       string    `gorm:"type:varchar(16);not null;index:idx_deliveries_queue,priority:1"`
+	Attempts     int32     `gorm:"not null;default:0"`
+	RunAt        time.Time `gorm:"not null;index:idx_deliveries_queue,priority:2"`
+	LeaseUntil   *time.Time
+	ResponseCode int32
+	Error        string `gorm:"type:text"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
 func InitDb(db *gorm.DB) error {
-	return db.AutoMigrate(&Document{})
+	return db.AutoMigrate(&Document{}, &Job{}, &Webhook{}, &WebhookDelivery{}, &ShareLink{})
 }
@@ -0,0 +1,203 @@
+package watermark
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net/url"
+	"path"
+	"strings"
+	"watermark-service/internal"
+
+	"github.com/google/uuid"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
+)
+
+const (
+	// DefaultS3Endpoint is used when no S3 endpoint is configured
+	DefaultS3Endpoint = "https://s3.amazonaws.com"
+	// DefaultS3PartSize files larger than this number of megabytes are uploaded in parts
+	DefaultS3PartSize = 16
+)
+
+// Server-side encryption modes of S3Storage.
+const (
+	SSENone = ""
+	SSES3   = "AES256"
+	SSEKMS  = "aws:kms"
+)
+
+// S3 configures an S3Storage. Endpoint is the URL of any S3-compatible service, PathStyle addresses
+// the bucket in the path instead of the host name as MinIO and Ceph usually require. Without the keys
+// the credentials are taken from the environment or the instance role. The files larger than PartSize
+// megabytes are uploaded in parts. SSE is one of SSENone, SSES3, SSEKMS, KMSKeyID is the key of SSEKMS.
+// PublicURL replaces the endpoint and the bucket in the URLs of the files, e.g. for a CDN.
+type S3 struct {
+	Bucket    string
+	Prefix    string
+	Region    string
+	Endpoint  string
+	PathStyle bool
+	AccessKey string
+	SecretKey string
+	PartSize  uint64
+	SSE       string
+	KMSKeyID  string
+	PublicURL string
+}
+
+type S3Storage struct {
+	client  *minio.Client
+	bucket  string
+	prefix  string
+	baseURL string
+	part    uint64
+	sse     encrypt.ServerSide
+}
+
+func NewS3Storage(cfg S3) (*S3Storage, error) {
+	if cfg.Bucket == "" {
+		return nil, errors.New("no bucket")
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = DefaultS3Endpoint
+	}
+	if cfg.PartSize == 0 {
+		cfg.PartSize = DefaultS3PartSize
+	}
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if endpoint.Host == "" || (endpoint.Scheme != "http" && endpoint.Scheme != "https") {
+		return nil, errors.New("Invalid endpoint")
+	}
+	creds := credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, "")
+	if cfg.AccessKey == "" {
+		creds = credentials.NewChainCredentials([]credentials.Provider{
+			&credentials.EnvAWS{},
+			&credentials.EnvMinio{},
+			&credentials.IAM{},
+		})
+	}
+	lookup := minio.BucketLookupAuto
+	if cfg.PathStyle {
+		lookup = minio.BucketLookupPath
+	}
+	client, err := minio.New(endpoint.Host, &minio.Options{
+		Creds:        creds,
+		Secure:       endpoint.Scheme == "https",
+		Region:       cfg.Region,
+		BucketLookup: lookup,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var sse encrypt.ServerSide
+	switch cfg.SSE {
+	case SSENone:
+	case SSES3:
+		sse = encrypt.NewSSE()
+	case SSEKMS:
+		if sse, err = encrypt.NewSSEKMS(cfg.KMSKeyID, nil); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New("unknown server-side encryption " + cfg.SSE)
+	}
+	baseURL := cfg.PublicURL
+	switch {
+	case baseURL != "":
+	case cfg.PathStyle:
+		baseURL = endpoint.Scheme + "://" + endpoint.Host + "/" + cfg.Bucket
+	default:
+		baseURL = endpoint.Scheme + "://" + cfg.Bucket + "." + endpoint.Host
+	}
+	prefix := strings.Trim(cfg.Prefix, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+	return &S3Storage{
+		client:  client,
+		bucket:  cfg.Bucket,
+		prefix:  prefix,
+		baseURL: strings.TrimSuffix(baseURL, "/") + "/",
+		part:    cfg.PartSize << 20,
+		sse:     sse,
+	}, nil
+}
+
+func (s *S3Storage) Upload(ctx context.Context, name string, image io.Reader) (string, error) {
+	span := internal.StartSpan("S3 upload", ctx)
+	defer span.Finish()
+	ext := strings.ToLower(path.Ext(name))
+	if !fileExt.MatchString(ext) {
+		ext = ""
+	}
+	key := s.prefix + uuid.NewString() + ext
+	// a file fitting into a part is uploaded at once, a larger one is streamed in parts
+	head, err := io.ReadAll(io.LimitReader(image, int64(s.part)))
+	if err != nil {
+		return "", err
+	}
+	body, size := io.Reader(bytes.NewReader(head)), int64(len(head))
+	if size == int64(s.part) {
+		body, size = io.MultiReader(body, image), -1
+	}
+	_, err = s.client.PutObject(ctx, s.bucket, key, body, size, minio.PutObjectOptions{
+		ContentType:          mime.TypeByExtension(ext),
+		PartSize:             s.part,
+		ServerSideEncryption: s.sse,
+	})
+	if err != nil {
+		return "", err
+	}
+	return s.baseURL + key, nil
+}
+
+func (s *S3Storage) Open(ctx context.Context, url string) (io.ReadCloser, error) {
+	key, err := s.key(url)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	// the object is requested lazily, a missing one is only reported by the first call
+	if _, err := obj.Stat(); err != nil {
+		obj.Close()
+		return nil, s3Error(err)
+	}
+	return obj, nil
+}
+
+// s3Error maps the missing objects to ErrNotFound, the other errors are kept as is.
+func s3Error(err error) error {
+	switch minio.ToErrorResponse(err).Code {
+	case "NoSuchKey", "NoSuchBucket":
+		return ErrNotFound
+	}
+	return err
+}
+
+func (s *S3Storage) Delete(ctx context.Context, url string) error {
+	key, err := s.key(url)
+	if err != nil {
+		return err
+	}
+	return s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+}
+
+// key maps the URL of a stored file to its key, the files outside of the prefix are rejected.
+func (s *S3Storage) key(url string) (string, error) {
+	key, ok := strings.CutPrefix(url, s.baseURL)
+	if !ok || !strings.HasPrefix(key, s.prefix) || key == s.prefix {
+		return "", errors.New("Invalid url")
+	}
+	return key, nil
+}
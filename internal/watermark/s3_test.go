@@ -0,0 +1,244 @@
+package watermark
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"watermark-service/internal"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/opentracing/opentracing-go"
+)
+
+// fakeS3 keeps the objects of a path-style bucket in memory, it serves the requests S3Storage makes.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	parts   map[int][]byte
+	headers map[string]http.Header
+	// down fails every request as an unavailable service
+	down bool
+}
+
+func newFakeS3() *fakeS3 {
+	return &fakeS3{objects: map[string][]byte{}, parts: map[int][]byte{}, headers: map[string]http.Header{}}
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	q := r.URL.Query()
+	key := r.URL.Path
+	body, _ := io.ReadAll(r.Body)
+	if r.Header.Get("X-Amz-Content-Sha256") == "STREAMING-AWS4-HMAC-SHA256-PAYLOAD" {
+		body = unchunk(body)
+	}
+	switch {
+	case f.down:
+		s3Fail(w, http.StatusServiceUnavailable, "ServiceUnavailable")
+	case strings.HasSuffix(key, "/denied"):
+		s3Fail(w, http.StatusForbidden, "AccessDenied")
+	case r.Method == http.MethodPost && q.Has("uploads"):
+		f.headers[key] = r.Header.Clone()
+		fmt.Fprint(w, `<InitiateMultipartUploadResult><UploadId>upload</UploadId></InitiateMultipartUploadResult>`)
+	case r.Method == http.MethodPut && q.Has("partNumber"):
+		n, _ := strconv.Atoi(q.Get("partNumber"))
+		f.parts[n] = body
+		w.Header().Set("ETag", `"part`+q.Get("partNumber")+`"`)
+	case r.Method == http.MethodPost && q.Has("uploadId"):
+		numbers := make([]int, 0, len(f.parts))
+		for n := range f.parts {
+			numbers = append(numbers, n)
+		}
+		sort.Ints(numbers)
+		var object []byte
+		for _, n := range numbers {
+			object = append(object, f.parts[n]...)
+		}
+		f.objects[key] = object
+		fmt.Fprint(w, `<CompleteMultipartUploadResult><Bucket>bucket</Bucket><ETag>"object"</ETag></CompleteMultipartUploadResult>`)
+	case r.Method == http.MethodPut:
+		f.objects[key] = body
+		f.headers[key] = r.Header.Clone()
+		w.Header().Set("ETag", `"object"`)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		object, ok := f.objects[key]
+		if !ok {
+			s3Fail(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		w.Header().Set("ETag", `"object"`)
+		w.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+		w.Header().Set("Content-Length", strconv.Itoa(len(object)))
+		if r.Method == http.MethodGet {
+			w.Write(object)
+		}
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		s3Fail(w, http.StatusNotImplemented, "NotImplemented")
+	}
+}
+
+// unchunk decodes the aws-chunked body of the streaming signature, the signatures aren't checked.
+func unchunk(body []byte) []byte {
+	var data []byte
+	for {
+		header, rest, ok := bytes.Cut(body, []byte("\r\n"))
+		if !ok {
+			return data
+		}
+		size, _, _ := strings.Cut(string(header), ";")
+		n, err := strconv.ParseInt(size, 16, 64)
+		if err != nil || n == 0 || int64(len(rest)) < n {
+			return data
+		}
+		data = append(data, rest[:n]...)
+		body = bytes.TrimPrefix(rest[n:], []byte("\r\n"))
+	}
+}
+
+func s3Fail(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `<Error><Code>%s</Code><Message>%s</Message></Error>`, code, code)
+}
+
+func newTestS3(t *testing.T, cfg S3) (*S3Storage, *fakeS3) {
+	t.Helper()
+	internal.Tracer = opentracing.NoopTracer{}
+	fake := newFakeS3()
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	cfg.Bucket, cfg.Region, cfg.Endpoint, cfg.PathStyle = "bucket", "us-east-1", server.URL, true
+	cfg.AccessKey, cfg.SecretKey = "access", "secret"
+	s, err := NewS3Storage(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, fake
+}
+
+func TestS3Storage(t *testing.T) {
+	s, fake := newTestS3(t, S3{Prefix: "/results/", PartSize: 5, SSE: SSES3})
+	ctx := context.Background()
+	tests := []struct {
+		name, file string
+		data       []byte
+	}{
+		{"single part", "image.PNG", []byte("image")},
+		{"multipart", "image.jpg", bytes.Repeat([]byte("0123456789"), 1<<20+1)},
+		{"no extension", "image", []byte("image")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url, err := s.Upload(ctx, tt.file, bytes.NewReader(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(url, s.baseURL+"results/") {
+				t.Errorf("Upload() = %s", url)
+			}
+			key, _ := s.key(url)
+			if h := fake.headers["/bucket/"+key]; h.Get("X-Amz-Server-Side-Encryption") != SSES3 {
+				t.Errorf("server-side encryption not requested: %v", h)
+			}
+			r, err := s.Open(ctx, url)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := io.ReadAll(r)
+			r.Close()
+			if err != nil || !bytes.Equal(got, tt.data) {
+				t.Fatalf("Open() read %d bytes, %v", len(got), err)
+			}
+			if err := s.Delete(ctx, url); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := s.Open(ctx, url); err != ErrNotFound {
+				t.Errorf("Open() after Delete() = %v, want ErrNotFound", err)
+			}
+		})
+	}
+	if len(fake.objects) != 0 {
+		t.Errorf("%d objects left", len(fake.objects))
+	}
+}
+
+func TestS3StorageErrors(t *testing.T) {
+	s, fake := newTestS3(t, S3{Prefix: "results"})
+	ctx := context.Background()
+	tests := []struct {
+		name string
+		url  string
+		err  error
+	}{
+		{"missing", s.baseURL + "results/missing.png", ErrNotFound},
+		{"other bucket", strings.Replace(s.baseURL, "bucket", "other", 1) + "results/image.png", nil},
+		{"outside of the prefix", s.baseURL + "other/image.png", nil},
+		{"prefix only", s.baseURL + "results/", nil},
+		{"denied", s.baseURL + "results/denied", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.Open(ctx, tt.url)
+			if err == nil || tt.err != nil && err != tt.err || tt.err == nil && err == ErrNotFound {
+				t.Errorf("Open() = %v, want %v", err, tt.err)
+			}
+		})
+	}
+	if err := s.Delete(ctx, s.baseURL+"other/image.png"); err == nil {
+		t.Error("file outside of the prefix deleted")
+	}
+	url, err := s.Upload(ctx, "image.png", strings.NewReader("image"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the client retries the unavailable service with a backoff
+	defer func(retries int) { minio.MaxRetry = retries }(minio.MaxRetry)
+	minio.MaxRetry = 1
+	fake.down = true
+	if _, err := s.Upload(ctx, "image.png", strings.NewReader("image")); err == nil {
+		t.Error("Upload() to an unavailable service succeeded")
+	}
+	if _, err := s.Open(ctx, url); err == nil || err == ErrNotFound {
+		t.Errorf("Open() from an unavailable service = %v", err)
+	}
+}
+
+func TestNewS3Storage(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     S3
+		baseURL string
+		ok      bool
+	}{
+		{"virtual host", S3{Bucket: "b", Endpoint: "https://s3.test"}, "https://b.s3.test/", true},
+		{"path style", S3{Bucket: "b", Endpoint: "http://minio:9000", PathStyle: true}, "http://minio:9000/b/", true},
+		{"public url", S3{Bucket: "b", PublicURL: "https://cdn.test/files/"}, "https://cdn.test/files/", true},
+		{"no bucket", S3{Endpoint: "https://s3.test"}, "", false},
+		{"endpoint scheme", S3{Bucket: "b", Endpoint: "ftp://s3.test"}, "", false},
+		{"unknown encryption", S3{Bucket: "b", SSE: "rot13"}, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.AccessKey, tt.cfg.SecretKey = "access", "secret"
+			s, err := NewS3Storage(tt.cfg)
+			if (err == nil) != tt.ok {
+				t.Fatalf("NewS3Storage() error = %v", err)
+			}
+			if err == nil && s.baseURL != tt.baseURL {
+				t.Errorf("baseURL = %s, want %s", s.baseURL, tt.baseURL)
+			}
+		})
+	}
+}
@@ -3,7 +3,9 @@ package watermark
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
+	"net/http"
 	"regexp"
 	"watermark-service/internal"
 
@@ -11,8 +13,12 @@ import (
 	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
 )
 
+// ErrNotFound is reported by Storage.Open for the objects missing from the backend.
+var ErrNotFound = errors.New("object not found")
+
 type Storage interface {
 	Upload(ctx context.Context, name string, image io.Reader) (string, error)
+	Open(ctx context.Context, url string) (io.ReadCloser, error)
 	Delete(ctx context.Context, id string) error
 }
 
@@ -20,12 +26,12 @@ type CloudinaryStorage struct {
 	instance *cloudinary.Cloudinary
 }
 
-func NewCloudinaryStorage(cloud, apiKey, secretKey string) *CloudinaryStorage {
+func NewCloudinaryStorage(cloud, apiKey, secretKey string) (*CloudinaryStorage, error) {
 	cld, err := cloudinary.NewFromParams(cloud, apiKey, secretKey)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
-	return &CloudinaryStorage{instance: cld}
+	return &CloudinaryStorage{instance: cld}, nil
 }
 
 func (s *CloudinaryStorage) Upload(ctx context.Context, name string, image io.Reader) (string, error) {
@@ -38,6 +44,27 @@ func (s *CloudinaryStorage) Upload(ctx context.Context, name string, image io.Re
 	return res.URL, err
 }
 
+// Open downloads the file, the uploaded files are public.
+func (s *CloudinaryStorage) Open(ctx context.Context, url string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("cloudinary responded %s", resp.Status)
+	}
+	return resp.Body, nil
+}
+
 func (s *CloudinaryStorage) Delete(ctx context.Context, url string) error {
 	id, ok := idFromURL(url)
 	if !ok {
@@ -0,0 +1,157 @@
+package watermark
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"watermark-service/internal"
+)
+
+// Locations are the URLs of the copies of an object by the names of the backends holding them.
+type Locations map[string]string
+
+// Placement reports where Storages.Upload stored an object, URL is the copy in the primary backend
+// or in the secondary one when the primary failed. Errs are the failures of the other backends.
+type Placement struct {
+	URL       string
+	Locations Locations
+	Errs      map[string]error
+}
+
+// Storages is a set of named backends. The objects are uploaded to the primary backend and mirrored
+// to the secondary one, without mirroring the secondary backend is only used when the primary fails.
+// The objects are read from the primary backend first and then from the others holding them.
+type Storages struct {
+	backends  map[string]Storage
+	primary   string
+	secondary string
+	mirror    bool
+}
+
+func NewStorages(backends map[string]Storage, primary, secondary string, mirror bool) (*Storages, error) {
+	if _, ok := backends[primary]; !ok {
+		return nil, fmt.Errorf("unknown primary storage %q", primary)
+	}
+	if _, ok := backends[secondary]; secondary != "" && !ok {
+		return nil, fmt.Errorf("unknown secondary storage %q", secondary)
+	}
+	if secondary == primary {
+		secondary = ""
+	}
+	if mirror && secondary == "" {
+		return nil, errors.New("no secondary storage to mirror to")
+	}
+	return &Storages{backends: backends, primary: primary, secondary: secondary, mirror: mirror}, nil
+}
+
+// Primary is the name of the backend the objects are uploaded to.
+func (s *Storages) Primary() string {
+	return s.primary
+}
+
+func (s *Storages) Backend(name string) (Storage, bool) {
+	backend, ok := s.backends[name]
+	return backend, ok
+}
+
+// Upload stores the object in the primary backend and in the secondary one when it's mirrored or the
+// primary failed. It fails only when no backend stored the object.
+func (s *Storages) Upload(ctx context.Context, name string, image io.Reader) (Placement, error) {
+	data, err := io.ReadAll(image)
+	if err != nil {
+		return Placement{}, err
+	}
+	targets := []string{s.primary}
+	if s.mirror {
+		targets = append(targets, s.secondary)
+	}
+	urls := make([]string, len(targets))
+	errs := make([]error, len(targets))
+	internal.ForEach(len(targets), len(targets), func(i int) {
+		urls[i], errs[i] = s.backends[targets[i]].Upload(ctx, name, bytes.NewReader(data))
+	})
+	if errs[0] != nil && s.secondary != "" && !s.mirror {
+		targets = append(targets, s.secondary)
+		url, err := s.backends[s.secondary].Upload(ctx, name, bytes.NewReader(data))
+		urls, errs = append(urls, url), append(errs, err)
+	}
+	placement := Placement{Locations: Locations{}, Errs: map[string]error{}}
+	for i, target := range targets {
+		if errs[i] != nil {
+			placement.Errs[target] = errs[i]
+			continue
+		}
+		placement.Locations[target] = urls[i]
+		if placement.URL == "" {
+			placement.URL = urls[i]
+		}
+	}
+	if placement.URL == "" {
+		return placement, errs[0]
+	}
+	return placement, nil
+}
+
+// Open reads the first available copy of the object, ErrNotFound is reported when every backend misses it.
+func (s *Storages) Open(ctx context.Context, locations Locations) (io.ReadCloser, error) {
+	var errs []error
+	missing := 0
+	for _, name := range s.order(locations) {
+		backend, ok := s.backends[name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("unknown storage %q", name))
+			continue
+		}
+		r, err := backend.Open(ctx, locations[name])
+		if err == nil {
+			return r, nil
+		}
+		if err == ErrNotFound {
+			missing++
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", name, err))
+	}
+	if missing == len(errs) {
+		return nil, ErrNotFound
+	}
+	return nil, errors.Join(errs...)
+}
+
+// Delete removes all the copies of the object, it returns the locations still holding it on failure.
+func (s *Storages) Delete(ctx context.Context, locations Locations) (Locations, error) {
+	remaining := Locations{}
+	var errs []error
+	for _, name := range s.order(locations) {
+		backend, ok := s.backends[name]
+		if !ok {
+			remaining[name] = locations[name]
+			errs = append(errs, fmt.Errorf("unknown storage %q", name))
+			continue
+		}
+		if err := backend.Delete(ctx, locations[name]); err != nil {
+			remaining[name] = locations[name]
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return remaining, errors.Join(errs...)
+}
+
+// order lists the backends holding the object, the primary and the secondary ones first.
+func (s *Storages) order(locations Locations) []string {
+	names := make([]string, 0, len(locations))
+	for name := range locations {
+		if name != s.primary && name != s.secondary {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range []string{s.secondary, s.primary} {
+		if _, ok := locations[name]; ok && name != "" {
+			names = append([]string{name}, names...)
+		}
+	}
+	return names
+}
@@ -0,0 +1,253 @@
+package watermark
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// memStorage keeps the objects in memory, failing marks the backend as unavailable.
+type memStorage struct {
+	name    string
+	objects map[string][]byte
+	failing bool
+	opened  int
+}
+
+var errUnavailable = errors.New("unavailable")
+
+func newMemStorage(name string) *memStorage {
+	return &memStorage{name: name, objects: map[string][]byte{}}
+}
+
+func (s *memStorage) Upload(_ context.Context, name string, image io.Reader) (string, error) {
+	if s.failing {
+		return "", errUnavailable
+	}
+	data, err := io.ReadAll(image)
+	if err != nil {
+		return "", err
+	}
+	url := "mem://" + s.name + "/" + name
+	s.objects[url] = data
+	return url, nil
+}
+
+func (s *memStorage) Open(_ context.Context, url string) (io.ReadCloser, error) {
+	s.opened++
+	if s.failing {
+		return nil, errUnavailable
+	}
+	data, ok := s.objects[url]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (s *memStorage) Delete(_ context.Context, url string) error {
+	if s.failing {
+		return errUnavailable
+	}
+	delete(s.objects, url)
+	return nil
+}
+
+func newTestStorages(t *testing.T, mirror bool) (*Storages, map[string]*memStorage) {
+	mems := map[string]*memStorage{"main": newMemStorage("main"), "backup": newMemStorage("backup"), "old": newMemStorage("old")}
+	backends := map[string]Storage{}
+	for name, mem := range mems {
+		backends[name] = mem
+	}
+	s, err := NewStorages(backends, "main", "backup", mirror)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, mems
+}
+
+func TestNewStorages(t *testing.T) {
+	backends := map[string]Storage{"main": newMemStorage("main"), "backup": newMemStorage("backup")}
+	tests := []struct {
+		name      string
+		primary   string
+		secondary string
+		mirror    bool
+		ok        bool
+	}{
+		{"primary only", "main", "", false, true},
+		{"secondary", "main", "backup", false, true},
+		{"mirror", "main", "backup", true, true},
+		{"unknown primary", "other", "", false, false},
+		{"unknown secondary", "main", "other", false, false},
+		{"mirror without secondary", "main", "", true, false},
+		{"mirror to itself", "main", "main", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewStorages(backends, tt.primary, tt.secondary, tt.mirror)
+			if tt.ok != (err == nil) {
+				t.Errorf("NewStorages() error = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestStoragesUpload(t *testing.T) {
+	tests := []struct {
+		name      string
+		mirror    bool
+		failing   []string
+		url       string
+		locations []string
+		errs      []string
+		ok        bool
+	}{
+		{"primary", false, nil, "mem://main/a.png", []string{"main"}, nil, true},
+		{"failover", false, []string{"main"}, "mem://backup/a.png", []string{"backup"}, []string{"main"}, true},
+		{"mirror", true, nil, "mem://main/a.png", []string{"backup", "main"}, nil, true},
+		{"mirror without primary", true, []string{"main"}, "mem://backup/a.png", []string{"backup"}, []string{"main"}, true},
+		{"mirror without secondary", true, []string{"backup"}, "mem://main/a.png", []string{"main"}, []string{"backup"}, true},
+		{"secondary failing", false, []string{"backup"}, "mem://main/a.png", []string{"main"}, nil, true},
+		{"all failing", false, []string{"main", "backup"}, "", nil, []string{"backup", "main"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, mems := newTestStorages(t, tt.mirror)
+			for _, name := range tt.failing {
+				mems[name].failing = true
+			}
+			placement, err := s.Upload(context.Background(), "a.png", strings.NewReader("data"))
+			if tt.ok != (err == nil) {
+				t.Fatalf("Upload() error = %v, want ok %v", err, tt.ok)
+			}
+			if placement.URL != tt.url {
+				t.Errorf("URL = %q, want %q", placement.URL, tt.url)
+			}
+			if got := names(placement.Locations); !reflect.DeepEqual(got, tt.locations) {
+				t.Errorf("Locations = %v, want %v", got, tt.locations)
+			}
+			if got := names(placement.Errs); !reflect.DeepEqual(got, tt.errs) {
+				t.Errorf("Errs = %v, want %v", placement.Errs, tt.errs)
+			}
+			for name, url := range placement.Locations {
+				if string(mems[name].objects[url]) != "data" {
+					t.Errorf("%s doesn't hold %s", name, url)
+				}
+			}
+		})
+	}
+}
+
+func TestStoragesOpen(t *testing.T) {
+	tests := []struct {
+		name      string
+		locations Locations
+		failing   []string
+		missing   []string
+		want      string
+		err       error
+		opened    []string
+	}{
+		{"primary first", Locations{"old": "mem://old/a", "backup": "mem://backup/a", "main": "mem://main/a"}, nil, nil, "main", nil, []string{"main"}},
+		{"secondary before others", Locations{"old": "mem://old/a", "backup": "mem://backup/a"}, nil, nil, "backup", nil, []string{"backup"}},
+		{"primary failing", Locations{"backup": "mem://backup/a", "main": "mem://main/a"}, []string{"main"}, nil, "backup", nil, []string{"backup", "main"}},
+		{"primary missing", Locations{"old": "mem://old/a", "main": "mem://main/a"}, nil, []string{"main"}, "old", nil, []string{"main", "old"}},
+		{"missing everywhere", Locations{"backup": "mem://backup/a", "main": "mem://main/a"}, nil, []string{"main", "backup"}, "", ErrNotFound, []string{"backup", "main"}},
+		{"missing and failing", Locations{"backup": "mem://backup/a", "main": "mem://main/a"}, []string{"backup"}, []string{"main"}, "", errUnavailable, []string{"backup", "main"}},
+		{"unknown backend", Locations{"gone": "mem://gone/a"}, nil, nil, "", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, mems := newTestStorages(t, false)
+			for name, url := range tt.locations {
+				if mem, ok := mems[name]; ok {
+					mem.objects[url] = []byte(name)
+				}
+			}
+			for _, name := range tt.failing {
+				mems[name].failing = true
+			}
+			for _, name := range tt.missing {
+				mems[name].objects = map[string][]byte{}
+			}
+			r, err := s.Open(context.Background(), tt.locations)
+			if tt.want == "" {
+				if err == nil {
+					t.Fatal("Open() succeeded")
+				}
+				if tt.err == ErrNotFound && err != ErrNotFound {
+					t.Errorf("Open() error = %v, want %v", err, ErrNotFound)
+				}
+				if tt.err != nil && tt.err != ErrNotFound && (err == ErrNotFound || !errors.Is(err, tt.err)) {
+					t.Errorf("Open() error = %v, want %v", err, tt.err)
+				}
+			} else {
+				if err != nil {
+					t.Fatal(err)
+				}
+				data, _ := io.ReadAll(r)
+				r.Close()
+				if string(data) != tt.want {
+					t.Errorf("Open() read %q, want %q", data, tt.want)
+				}
+			}
+			opened := map[string]int{}
+			for name, mem := range mems {
+				if mem.opened > 0 {
+					opened[name] = mem.opened
+				}
+			}
+			if got := names(opened); !reflect.DeepEqual(got, tt.opened) {
+				t.Errorf("opened %v, want %v", got, tt.opened)
+			}
+		})
+	}
+}
+
+func TestStoragesDelete(t *testing.T) {
+	s, mems := newTestStorages(t, true)
+	placement, err := s.Upload(context.Background(), "a.png", strings.NewReader("data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mems["backup"].failing = true
+	remaining, err := s.Delete(context.Background(), placement.Locations)
+	if !errors.Is(err, errUnavailable) {
+		t.Errorf("Delete() error = %v", err)
+	}
+	if !reflect.DeepEqual(remaining, Locations{"backup": placement.Locations["backup"]}) {
+		t.Errorf("remaining = %v", remaining)
+	}
+	if len(mems["main"].objects) != 0 {
+		t.Error("primary copy kept")
+	}
+	mems["backup"].failing = false
+	remaining, err = s.Delete(context.Background(), remaining)
+	if err != nil || len(remaining) != 0 || len(mems["backup"].objects) != 0 {
+		t.Errorf("Delete() of the remaining copies = %v, %v", remaining, err)
+	}
+}
+
+func TestStoragesOrder(t *testing.T) {
+	s, _ := newTestStorages(t, false)
+	locations := Locations{"zeta": "", "old": "", "backup": "", "main": ""}
+	if got, want := s.order(locations), []string{"main", "backup", "old", "zeta"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("order() = %v, want %v", got, want)
+	}
+}
+
+// names lists the keys of the map sorted, nil when there are none.
+func names[V any](m map[string]V) []string {
+	var list []string
+	for name := range m {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+	return list
+}
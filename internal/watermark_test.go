@@ -0,0 +1,53 @@
+package internal
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"runtime"
+	"testing"
+)
+
+func benchmarkSource(width, height int) *image.RGBA {
+	src := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			src.SetRGBA(x, y, color.RGBA{uint8(x), uint8(y), uint8(x ^ y), 255})
+		}
+	}
+	return src
+}
+
+func BenchmarkFillImageWithWatermarks(b *testing.B) {
+	src := benchmarkSource(4000, 3000)
+	watermark := CombineTextWithLogo(nil, "watermark-service", DefaultInterpolation)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		FillImageWithWatermarks(watermark, src)
+	}
+}
+
+func BenchmarkFillImageWithWatermarksParallel(b *testing.B) {
+	src := benchmarkSource(4000, 3000)
+	watermark := CombineTextWithLogo(nil, "watermark-service", DefaultInterpolation)
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				FillImageWithWatermarksParallel(watermark, src, workers)
+			}
+		})
+	}
+}
+
+func BenchmarkWatermarkBands(b *testing.B) {
+	src := benchmarkSource(4000, 3000)
+	watermark := CombineTextWithLogo(nil, "watermark-service", DefaultInterpolation)
+	rows := BandRows(src.Rect.Dx(), 16<<20)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		img := WatermarkBands(watermark, src, true, LeftTop, image.Point{}, rows, runtime.NumCPU())
+		for y := 0; y < src.Rect.Dy(); y += rows {
+			img.RGBAAt(0, y)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package internal
+
+import (
+	"time"
+
+	uuid "github.com/google/uuid"
+)
+
+// The document lifecycle events sent to the webhooks.
+const (
+	EventDocumentCreated  = "document.created"
+	EventDocumentFinished = "document.finished"
+	EventDocumentFailed   = "document.failed"
+	EventDocumentShared   = "document.shared"
+	EventDocumentRemoved  = "document.removed"
+	EventPing             = "ping"
+	// EventJobProgress is only pushed by Watch, the webhooks aren't called on progress.
+	EventJobProgress = "job.progress"
+)
+
+// Events lists the events a webhook may subscribe to.
+var Events = []string{EventDocumentCreated, EventDocumentFinished, EventDocumentFailed, EventDocumentShared, EventDocumentRemoved}
+
+// Webhook receives the events of its owner, all of them when Events is empty. The secret signing
+// the callbacks is only returned when the webhook is registered.
+type Webhook struct {
+	ID        uuid.UUID `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events,omitempty"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "Pending"
+	DeliveryDelivered DeliveryStatus = "Delivered"
+	DeliveryFailed    DeliveryStatus = "Failed"
+)
+
+// WebhookDelivery is the delivery log entry of an event, ResponseCode and Err describe the last attempt.
+type WebhookDelivery struct {
+	ID           uuid.UUID      `json:"id"`
+	WebhookID    uuid.UUID      `json:"webhook_id"`
+	Event        string         `json:"event"`
+	Status       DeliveryStatus `json:"status"`
+	Attempts     int32          `json:"attempts"`
+	ResponseCode int32          `json:"response_code,omitempty"`
+	Err          string         `json:"error,omitempty"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+}
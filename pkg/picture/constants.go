@@ -1,5 +1,30 @@
 package picture
 
+import "time"
+
 type LogoContextKey string
 
 type ImageContextKey string
+
+type ErrorContextKey string
+
+// DefaultMemoryLimit is the size of a watermarked copy above which images are processed in bands.
+const DefaultMemoryLimit = 256 << 20
+
+const (
+	DefaultCacheEntries = 256
+	DefaultCacheSize    = 64 << 20
+)
+
+// MaxBatchSize limits the number of images in one CreateBatch call.
+const MaxBatchSize = 500
+
+// MaxMessageSize is the gRPC message size accepted by the service and its clients, it is raised
+// from the default 4MB for the batches.
+const MaxMessageSize = 64 << 20
+
+// The requests over the render limit wait in a queue of DefaultQueueSize for at most DefaultQueueTimeout.
+const (
+	DefaultQueueSize    = 64
+	DefaultQueueTimeout = 10 * time.Second
+)
@@ -3,7 +3,6 @@ package endpoints
 import (
 	"context"
 	"errors"
-	"image"
 	"watermark-service/internal"
 	"watermark-service/pkg/picture"
 
@@ -13,12 +12,18 @@ import (
 
 type Set struct {
 	CreateEndpoint        endpoint.Endpoint
+	CreateTileEndpoint    endpoint.Endpoint
+	CreateBatchEndpoint   endpoint.Endpoint
+	ProcessEndpoint       endpoint.Endpoint
 	ServiceStatusEndpoint endpoint.Endpoint
 }
 
 func NewEndpointSet(svc picture.Service) Set {
 	return Set{
 		CreateEndpoint:        MakeCreateEndpoint(svc),
+		CreateTileEndpoint:    MakeCreateTileEndpoint(svc),
+		CreateBatchEndpoint:   MakeCreateBatchEndpoint(svc),
+		ProcessEndpoint:       MakeProcessEndpoint(svc),
 		ServiceStatusEndpoint: MakeServiceStatusEndpoint(svc),
 	}
 }
@@ -26,7 +31,7 @@ func NewEndpointSet(svc picture.Service) Set {
 func MakeCreateEndpoint(svc picture.Service) endpoint.Endpoint {
 	endpoint := func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(CreateRequest)
-		code, err := svc.Create(ctx, req.Image, req.Logo, req.Text, req.Fill, req.Pos)
+		code, err := svc.Create(ctx, req.Image, req.Logo, req.Text, req.Fill, req.Pos, req.LogoOptions)
 		if err != nil {
 			return CreateResponse{code, err.Error()}, nil
 		}
@@ -35,38 +40,110 @@ func MakeCreateEndpoint(svc picture.Service) endpoint.Endpoint {
 	return opentracing.TraceServer(internal.Tracer, "Create method")(endpoint)
 }
 
+func MakeCreateTileEndpoint(svc picture.Service) endpoint.Endpoint {
+	endpoint := func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(CreateTileRequest)
+		img, err := svc.CreateTile(ctx, req.Image, req.Logo, req.Text, req.Fill, req.Pos, req.LogoOptions, req.Tile)
+		if err != nil {
+			return CreateResponse{img, err.Error()}, nil
+		}
+		return CreateResponse{img, ""}, nil
+	}
+	return opentracing.TraceServer(internal.Tracer, "CreateTile method")(endpoint)
+}
+
+func MakeCreateBatchEndpoint(svc picture.Service) endpoint.Endpoint {
+	endpoint := func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(CreateBatchRequest)
+		items, err := svc.CreateBatch(ctx, req.Images, req.Logo, req.Text, req.Fill, req.Pos, req.LogoOptions)
+		if err != nil {
+			return CreateBatchResponse{items, err.Error()}, nil
+		}
+		return CreateBatchResponse{items, ""}, nil
+	}
+	return opentracing.TraceServer(internal.Tracer, "CreateBatch method")(endpoint)
+}
+
+func MakeProcessEndpoint(svc picture.Service) endpoint.Endpoint {
+	endpoint := func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(ProcessRequest)
+		img, err := svc.Process(ctx, req.Image, req.Logo, req.Operations)
+		if err != nil {
+			return ProcessResponse{img, err.Error()}, nil
+		}
+		return ProcessResponse{img, ""}, nil
+	}
+	return opentracing.TraceServer(internal.Tracer, "Process method")(endpoint)
+}
+
 func MakeServiceStatusEndpoint(svc picture.Service) endpoint.Endpoint {
 	endpoint := func(ctx context.Context, request interface{}) (interface{}, error) {
 		_ = request.(ServiceStatusRequest)
-		code, err := svc.ServiceStatus(ctx)
+		code, load, err := svc.ServiceStatus(ctx)
 		if err != nil {
-			return ServiceStatusResponse{Code: code, Err: err.Error()}, nil
+			return ServiceStatusResponse{Code: code, Load: load, Err: err.Error()}, nil
 		}
-		return ServiceStatusResponse{Code: code, Err: ""}, nil
+		return ServiceStatusResponse{Code: code, Load: load, Err: ""}, nil
 	}
 	return opentracing.TraceServer(internal.Tracer, "ServiceStatus method")(endpoint)
 }
 
-func (s *Set) Create(ctx context.Context) (image.Image, error) {
+func (s *Set) Create(ctx context.Context) (internal.Blob, error) {
 	resp, err := s.CreateEndpoint(ctx, CreateRequest{})
 	if err != nil {
-		return nil, err
+		return internal.Blob{}, err
 	}
 	createResp := resp.(CreateResponse)
 	if createResp.Err != "" {
-		return nil, errors.New(createResp.Err)
+		return internal.Blob{}, errors.New(createResp.Err)
 	}
 	return createResp.Image, nil
 }
 
-func (s *Set) ServiceStatus(ctx context.Context) (int64, error) {
+func (s *Set) CreateTile(ctx context.Context) (internal.Blob, error) {
+	resp, err := s.CreateTileEndpoint(ctx, CreateTileRequest{})
+	if err != nil {
+		return internal.Blob{}, err
+	}
+	createResp := resp.(CreateResponse)
+	if createResp.Err != "" {
+		return internal.Blob{}, errors.New(createResp.Err)
+	}
+	return createResp.Image, nil
+}
+
+func (s *Set) CreateBatch(ctx context.Context) ([]internal.BatchItem, error) {
+	resp, err := s.CreateBatchEndpoint(ctx, CreateBatchRequest{})
+	if err != nil {
+		return nil, err
+	}
+	batchResp := resp.(CreateBatchResponse)
+	if batchResp.Err != "" {
+		return nil, errors.New(batchResp.Err)
+	}
+	return batchResp.Items, nil
+}
+
+func (s *Set) Process(ctx context.Context) (internal.Blob, error) {
+	resp, err := s.ProcessEndpoint(ctx, ProcessRequest{})
+	if err != nil {
+		return internal.Blob{}, err
+	}
+	processResp := resp.(ProcessResponse)
+	if processResp.Err != "" {
+		return internal.Blob{}, errors.New(processResp.Err)
+	}
+	return processResp.Image, nil
+}
+
+func (s *Set) ServiceStatus(ctx context.Context) (int64, internal.LoadStats, error) {
 	resp, err := s.ServiceStatusEndpoint(ctx, ServiceStatusRequest{})
 	svcStatusResp := resp.(ServiceStatusResponse)
 	if err != nil {
-		return svcStatusResp.Code, err
+		return svcStatusResp.Code, svcStatusResp.Load, err
 	}
 	if svcStatusResp.Err != "" {
-		return svcStatusResp.Code, errors.New(svcStatusResp.Err)
+		return svcStatusResp.Code, svcStatusResp.Load, errors.New(svcStatusResp.Err)
 	}
-	return svcStatusResp.Code, nil
+	return svcStatusResp.Code, svcStatusResp.Load, nil
 }
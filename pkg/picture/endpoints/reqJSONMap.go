@@ -1,26 +1,65 @@
 package endpoints
 
 import (
-	"image"
 	"watermark-service/internal"
 )
 
 type CreateRequest struct {
-	Image image.Image       `json:"image"`
-	Logo  image.Image       `json:"logo"`
+	Image internal.Blob     `json:"image"`
+	Logo  internal.Blob     `json:"logo"`
 	Text  string            `json:"text"`
 	Fill  bool              `json:"fill"`
 	Pos   internal.Position `json:"position"`
+
+	LogoOptions internal.LogoOptions `json:"logo_options"`
 }
 
 type CreateResponse struct {
-	Image image.Image `json:"image"`
-	Err   string      `json:"err,omitempty"`
+	Image internal.Blob `json:"image"`
+	Err   string        `json:"err,omitempty"`
+}
+
+type CreateTileRequest struct {
+	Image internal.Blob     `json:"image"`
+	Logo  internal.Blob     `json:"logo"`
+	Text  string            `json:"text"`
+	Fill  bool              `json:"fill"`
+	Pos   internal.Position `json:"position"`
+	Tile  internal.Tile     `json:"tile"`
+
+	LogoOptions internal.LogoOptions `json:"logo_options"`
+}
+
+type CreateBatchRequest struct {
+	Images []internal.Blob   `json:"images"`
+	Logo   internal.Blob     `json:"logo"`
+	Text   string            `json:"text"`
+	Fill   bool              `json:"fill"`
+	Pos    internal.Position `json:"position"`
+
+	LogoOptions internal.LogoOptions `json:"logo_options"`
+}
+
+type CreateBatchResponse struct {
+	Items []internal.BatchItem `json:"items"`
+	Err   string               `json:"err,omitempty"`
+}
+
+type ProcessRequest struct {
+	Image      internal.Blob        `json:"image"`
+	Logo       internal.Blob        `json:"logo"`
+	Operations []internal.Operation `json:"operations"`
+}
+
+type ProcessResponse struct {
+	Image internal.Blob `json:"image"`
+	Err   string        `json:"err,omitempty"`
 }
 
 type ServiceStatusRequest struct{}
 
 type ServiceStatusResponse struct {
-	Code int64  `json:"status"`
-	Err  string `json:"err,omitempty"`
+	Code int64              `json:"status"`
+	Load internal.LoadStats `json:"load"`
+	Err  string             `json:"err,omitempty"`
 }
@@ -2,8 +2,10 @@ package picture
 
 import (
 	"context"
-	"image"
 	"watermark-service/internal"
+	"watermark-service/internal/util"
+
+	"go.uber.org/zap"
 )
 
 type Middleware func(Service) Service
@@ -20,10 +22,96 @@ type pictureMiddleware struct {
 	next Service
 }
 
-func (m *pictureMiddleware) Create(ctx context.Context, Image, Logo image.Image, text string, fill bool, pos internal.Position) (image.Image, error) {
-	return m.next.Create(ctx, Image, Logo, text, fill, pos)
+func (m *pictureMiddleware) Create(ctx context.Context, Image, Logo internal.Blob, text string, fill bool, pos internal.Position, logoOpts internal.LogoOptions) (internal.Blob, error) {
+	return m.next.Create(ctx, Image, Logo, text, fill, pos, logoOpts)
+}
+
+func (m *pictureMiddleware) CreateTile(ctx context.Context, Image, Logo internal.Blob, text string, fill bool, pos internal.Position, logoOpts internal.LogoOptions, tile internal.Tile) (internal.Blob, error) {
+	return m.next.CreateTile(ctx, Image, Logo, text, fill, pos, logoOpts, tile)
+}
+
+func (m *pictureMiddleware) CreateBatch(ctx context.Context, images []internal.Blob, Logo internal.Blob, text string, fill bool, pos internal.Position, logoOpts internal.LogoOptions) ([]internal.BatchItem, error) {
+	return m.next.CreateBatch(ctx, images, Logo, text, fill, pos, logoOpts)
+}
+
+func (m *pictureMiddleware) Process(ctx context.Context, Image, Logo internal.Blob, ops []internal.Operation) (internal.Blob, error) {
+	return m.next.Process(ctx, Image, Logo, ops)
 }
 
-func (m *pictureMiddleware) ServiceStatus(ctx context.Context) (int64, error) {
+func (m *pictureMiddleware) ServiceStatus(ctx context.Context) (int64, internal.LoadStats, error) {
 	return m.next.ServiceStatus(ctx)
 }
+
+// LimitMiddleware bounds the number of concurrent renders, the requests over the limit wait
+// in the limiter queue and are rejected with util.OverloadedError when the service is saturated.
+func LimitMiddleware(limiter *internal.Limiter) Middleware {
+	return func(next Service) Service {
+		return &limitMiddleware{
+			next:    next,
+			limiter: limiter,
+			log:     zap.L().With(zap.String("Middleware", "Limit")),
+		}
+	}
+}
+
+type limitMiddleware struct {
+	next    Service
+	limiter *internal.Limiter
+	log     *zap.Logger
+}
+
+func (m *limitMiddleware) acquire(ctx context.Context) (func(), error) {
+	release, ok := m.limiter.Acquire(ctx)
+	if ok {
+		return release, nil
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	err := &util.OverloadedError{RetryAfter: m.limiter.RetryAfter()}
+	stats := m.limiter.Stats()
+	m.log.Warn("Request", zap.String("Status", "Rejected"), zap.Int("InFlight", stats.InFlight), zap.Int("Queued", stats.Queued), zap.Error(err))
+	return nil, err
+}
+
+func (m *limitMiddleware) Create(ctx context.Context, Image, Logo internal.Blob, text string, fill bool, pos internal.Position, logoOpts internal.LogoOptions) (internal.Blob, error) {
+	release, err := m.acquire(ctx)
+	if err != nil {
+		return internal.Blob{}, err
+	}
+	defer release()
+	return m.next.Create(ctx, Image, Logo, text, fill, pos, logoOpts)
+}
+
+func (m *limitMiddleware) CreateTile(ctx context.Context, Image, Logo internal.Blob, text string, fill bool, pos internal.Position, logoOpts internal.LogoOptions, tile internal.Tile) (internal.Blob, error) {
+	release, err := m.acquire(ctx)
+	if err != nil {
+		return internal.Blob{}, err
+	}
+	defer release()
+	return m.next.CreateTile(ctx, Image, Logo, text, fill, pos, logoOpts, tile)
+}
+
+// CreateBatch takes a single slot, the batch is spread over the service workers anyway.
+func (m *limitMiddleware) CreateBatch(ctx context.Context, images []internal.Blob, Logo internal.Blob, text string, fill bool, pos internal.Position, logoOpts internal.LogoOptions) ([]internal.BatchItem, error) {
+	release, err := m.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return m.next.CreateBatch(ctx, images, Logo, text, fill, pos, logoOpts)
+}
+
+func (m *limitMiddleware) Process(ctx context.Context, Image, Logo internal.Blob, ops []internal.Operation) (internal.Blob, error) {
+	release, err := m.acquire(ctx)
+	if err != nil {
+		return internal.Blob{}, err
+	}
+	defer release()
+	return m.next.Process(ctx, Image, Logo, ops)
+}
+
+func (m *limitMiddleware) ServiceStatus(ctx context.Context) (int64, internal.LoadStats, error) {
+	code, _, err := m.next.ServiceStatus(ctx)
+	return code, m.limiter.Stats(), err
+}
@@ -1,45 +1,296 @@
 package picture
 
 import (
+	"bytes"
 	"context"
-	"errors"
 	"image"
 
 	"net/http"
+	"runtime"
 	"watermark-service/internal"
+	"watermark-service/internal/util"
 
 	"go.uber.org/zap"
 )
 
 type pictureService struct {
-	log *zap.Logger
+	interpolation internal.Interpolation
+	memoryLimit   int64
+	workers       int
+	cache         *internal.OverlayCache
+	log           *zap.Logger
 }
 
-func NewService() Service {
+// NewService creates the picture service, watermarked images bigger than memoryLimit bytes
+// are composed in bands instead of being copied as a whole. Fill mode is rendered by the given
+// number of workers, all CPUs are used when it isn't positive. Rendered overlays are kept in cache,
+// a nil cache disables caching.
+func NewService(interpolation internal.Interpolation, memoryLimit int64, workers int, cache *internal.OverlayCache) Service {
+	if interpolation == "" {
+		interpolation = internal.DefaultInterpolation
+	}
+	if memoryLimit <= 0 {
+		memoryLimit = DefaultMemoryLimit
+	}
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
 	return &pictureService{
-		log: zap.L().With(zap.String("Service", "PictureService")),
+		interpolation: interpolation,
+		memoryLimit:   memoryLimit,
+		workers:       workers,
+		cache:         cache,
+		log:           zap.L().With(zap.String("Service", "PictureService")),
 	}
 }
 
-func (w *pictureService) Create(ctx context.Context, Image image.Image, logo image.Image, text string, fill bool, pos internal.Position) (image.Image, error) {
+// Create watermarks the image and encodes the result in its format, vector images result in PNG.
+func (w *pictureService) Create(ctx context.Context, Image internal.Blob, logo internal.Blob, text string, fill bool, pos internal.Position, logoOpts internal.LogoOptions) (internal.Blob, error) {
 	span := internal.StartSpan("picture generation", ctx)
 	defer span.Finish()
-	if text == "" && logo == nil {
-		return nil, errors.New("No data to insert")
+	if text == "" && logo.Empty() {
+		return internal.Blob{}, util.ErrInvalidArg
+	}
+	src, logoSrc, err := w.decodeInputs(Image, logo)
+	if err != nil {
+		return internal.Blob{}, err
+	}
+	res, err := w.watermark(src, logoSrc, text, fill, pos, image.Point{}, logoOpts)
+	if err != nil {
+		return internal.Blob{}, err
+	}
+	return w.encode(res, outputFormat(Image.Format), 0)
+}
+
+// CreateTile watermarks the image as the part of a bigger canvas, the tiles of a huge image
+// are spread over the service replicas this way.
+func (w *pictureService) CreateTile(ctx context.Context, Image internal.Blob, logo internal.Blob, text string, fill bool, pos internal.Position, logoOpts internal.LogoOptions, tile internal.Tile) (internal.Blob, error) {
+	span := internal.StartSpan("tile generation", ctx)
+	defer span.Finish()
+	// the placements are computed for the whole canvas, so it's bounded like an image
+	if err := util.Limits.CheckDimensions(tile.Width, tile.Height); err != nil {
+		w.log.Error("Tile", zap.Int("Width", tile.Width), zap.Int("Height", tile.Height), zap.Error(err))
+		return internal.Blob{}, err
+	}
+	if text == "" && logo.Empty() {
+		return internal.Blob{}, util.ErrInvalidArg
+	}
+	src, logoSrc, err := w.decodeInputs(Image, logo)
+	if err != nil {
+		return internal.Blob{}, err
+	}
+	if !tile.Valid(src.Bounds().Size()) {
+		w.log.Error("Tile", zap.Int("X", tile.X), zap.Int("Y", tile.Y), zap.Int("Width", tile.Width), zap.Int("Height", tile.Height), zap.Error(util.ErrInvalidArg))
+		return internal.Blob{}, util.ErrInvalidArg
+	}
+	watermark, err := w.watermarkOverlay(logoSrc, text, logoOpts)
+	if err != nil {
+		return internal.Blob{}, err
+	}
+	res := internal.WatermarkTile(watermark, src, fill, pos, image.Point{}, tile)
+	return w.encode(res, outputFormat(Image.Format), 0)
+}
+
+func (w *pictureService) watermark(Image image.Image, logo *logoSource, text string, fill bool, pos internal.Position, margin image.Point, logoOpts internal.LogoOptions) (image.Image, error) {
+	watermark, err := w.watermarkOverlay(logo, text, logoOpts)
+	if err != nil {
+		return nil, err
+	}
+	return w.compose(Image, watermark, fill, pos, margin, w.workers), nil
+}
+
+// CreateBatch renders the overlay once and applies it to every image, the images are spread
+// over the workers and each of them is composed by a single one.
+func (w *pictureService) CreateBatch(ctx context.Context, images []internal.Blob, logo internal.Blob, text string, fill bool, pos internal.Position, logoOpts internal.LogoOptions) ([]internal.BatchItem, error) {
+	span := internal.StartSpan("batch generation", ctx)
+	defer span.Finish()
+	if text == "" && logo.Empty() {
+		return nil, util.ErrInvalidArg
+	}
+	if len(images) == 0 || len(images) > MaxBatchSize {
+		w.log.Error("Batch", zap.Int("Images", len(images)), zap.Error(util.ErrInvalidArg))
+		return nil, util.ErrInvalidArg
+	}
+	logoSrc, err := w.logoSource(logo)
+	if err != nil {
+		return nil, err
+	}
+	watermark, err := w.watermarkOverlay(logoSrc, text, logoOpts)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]internal.BatchItem, len(images))
+	internal.ForEach(len(images), w.workers, func(i int) {
+		src, err := w.decode(images[i])
+		if err == nil && src == nil {
+			err = util.ErrInvalidArg
+		}
+		if err != nil {
+			items[i].Err = err.Error()
+			return
+		}
+		res := w.compose(src, watermark, fill, pos, image.Point{}, 1)
+		if items[i].Image, err = w.encode(res, outputFormat(images[i].Format), 0); err != nil {
+			items[i].Err = err.Error()
+		}
+	})
+	w.log.Info("Batch", zap.Int("Images", len(images)), zap.String("Status", "Complete"))
+	return items, nil
+}
+
+// decodeInputs decodes the image to watermark, which is required, and checks the optional logo.
+func (w *pictureService) decodeInputs(Image internal.Blob, logo internal.Blob) (image.Image, *logoSource, error) {
+	logoSrc, err := w.logoSource(logo)
+	if err != nil {
+		return nil, nil, err
+	}
+	src, err := w.decode(Image)
+	if err != nil {
+		return nil, nil, err
+	}
+	if src == nil {
+		return nil, nil, util.ErrInvalidArg
+	}
+	return src, logoSrc, nil
+}
+
+// logoSource is the uploaded logo, it is decoded only when the overlay isn't cached.
+type logoSource struct {
+	blob    internal.Blob
+	img     image.Image
+	err     error
+	decoded bool
+}
+
+// logoSource checks the header of the logo against the limits, so a logo which can't be used
+// is rejected before any work is done. The logo of an empty blob is nil.
+func (w *pictureService) logoSource(logo internal.Blob) (*logoSource, error) {
+	if logo.Empty() {
+		return nil, nil
+	}
+	if _, err := util.CheckImage(logo.Data, logo.Format); err != nil {
+		if err == util.ErrTooLarge {
+			return nil, err
+		}
+		w.log.Error("Logo decoding", zap.String("Format", logo.Format), zap.Error(err))
+		return nil, util.ErrInvalidArg
+	}
+	return &logoSource{blob: logo}, nil
+}
+
+// image decodes the logo once, a nil logo source has no image.
+func (l *logoSource) image(w *pictureService) (image.Image, error) {
+	if l == nil {
+		return nil, nil
+	}
+	if !l.decoded {
+		l.img, l.err = w.decode(l.blob)
+		l.decoded = true
+	}
+	return l.img, l.err
+}
+
+// decode returns nil for an empty blob, the images which can't be decoded are invalid arguments.
+func (w *pictureService) decode(b internal.Blob) (image.Image, error) {
+	if b.Empty() {
+		return nil, nil
+	}
+	img, err := util.DecodeImage(bytes.NewReader(b.Data), b.Format)
+	if err == util.ErrTooLarge {
+		return nil, err
+	}
+	if err != nil {
+		w.log.Error("Image decoding", zap.String("Format", b.Format), zap.Error(err))
+		return nil, util.ErrInvalidArg
+	}
+	return img, nil
+}
+
+func (w *pictureService) encode(img image.Image, format string, quality int) (internal.Blob, error) {
+	data, err := util.EncodeImage(img, format, quality)
+	if err != nil {
+		w.log.Error("Image encoding", zap.String("Format", format), zap.Error(err))
+		return internal.Blob{}, err
+	}
+	return internal.Blob{Data: data, Format: format}, nil
+}
+
+// outputFormat keeps JPEG images in JPEG, the others are encoded in PNG.
+func outputFormat(format string) string {
+	if format == ".jpg" {
+		return format
+	}
+	return ".png"
+}
+
+func (w *pictureService) watermarkOverlay(logo *logoSource, text string, logoOpts internal.LogoOptions) (image.Image, error) {
+	interp, err := internal.ParseInterpolation(logoOpts.Interpolation)
+	if err != nil {
+		w.log.Error("Logo scaling", zap.String("Interpolation", logoOpts.Interpolation), zap.Error(err))
+		return nil, util.ErrInvalidArg
+	}
+	if interp == "" {
+		interp = w.interpolation
+	}
+	return w.overlay(logo, text, logoOpts, interp)
+}
+
+// compose bands the result of the images above the memory limit. Only the result is banded, the source
+// has already been decoded in full, so the limit bounds the extra copy rather than the total memory.
+func (w *pictureService) compose(Image image.Image, watermark image.Image, fill bool, pos internal.Position, margin image.Point, workers int) image.Image {
+	if size := Image.Bounds().Size(); int64(size.X)*int64(size.Y)*4 > w.memoryLimit {
+		rows := internal.BandRows(size.X, w.memoryLimit)
+		w.log.Info("Banded watermarking", zap.Int("Width", size.X), zap.Int("Height", size.Y), zap.Int("Rows", rows))
+		return internal.WatermarkBands(watermark, Image, fill, pos, margin, rows, workers)
 	}
-	watermark := internal.CombineTextWithLogo(logo, text)
-	w.log.Info("Logo creation", zap.String("Status", "Complete"))
 	if fill {
 		w.log.Info("Fill image", zap.String("Status", "Started"))
-		return internal.FillImageWithWatermarks(watermark, Image), nil
+		return internal.FillImageWithWatermarksParallel(watermark, Image, workers)
 	}
 	w.log.Info("Add watermark to image", zap.String("Status", "Started"))
-	return internal.AddWatermarkToImage(watermark, Image, pos), nil
+	return internal.AddWatermarkToImageWithMargin(watermark, Image, pos, margin)
 }
 
-func (w *pictureService) ServiceStatus(ctx context.Context) (int64, error) {
+// overlay returns the watermark made of the preprocessed logo and the text, reusing the cached one when possible.
+// The logo is decoded only on a cache miss.
+func (w *pictureService) overlay(logoSrc *logoSource, text string, logoOpts internal.LogoOptions, interp internal.Interpolation) (image.Image, error) {
+	var key string
+	if w.cache != nil {
+		var blob internal.Blob
+		if logoSrc != nil {
+			blob = logoSrc.blob
+		}
+		key = internal.OverlayKey(blob, text, logoOpts, interp)
+		if watermark, ok := w.cache.Get(key); ok {
+			w.log.Info("Logo creation", zap.String("Status", "Cached"))
+			return watermark, nil
+		}
+	}
+	logo, err := logoSrc.image(w)
+	if err != nil {
+		return nil, err
+	}
+	logo, err = internal.PrepareLogo(logo, logoOpts)
+	if err != nil {
+		w.log.Error("Logo preprocessing", zap.String("Status", "failed"), zap.Error(err))
+		return nil, util.ErrInvalidArg
+	}
+	watermark := internal.CombineTextWithLogo(logo, text, interp)
+	w.log.Info("Logo creation", zap.String("Status", "Complete"))
+	if w.cache != nil {
+		w.cache.Add(key, watermark)
+	}
+	return watermark, nil
+}
+
+// ServiceStatus leaves the load empty, it is filled in by LimitMiddleware.
+func (w *pictureService) ServiceStatus(ctx context.Context) (int64, internal.LoadStats, error) {
 	span := internal.StartSpan("status retrieval", ctx)
 	defer span.Finish()
+	if w.cache != nil {
+		stats := w.cache.Stats()
+		w.log.Info("Overlay cache", zap.Int64("Hits", stats.Hits), zap.Int64("Misses", stats.Misses), zap.Int("Entries", stats.Entries), zap.Int64("Bytes", stats.Bytes))
+	}
 	w.log.Info("Request", zap.Int("Status", http.StatusOK))
-	return http.StatusOK, nil
+	return http.StatusOK, internal.LoadStats{}, nil
 }
@@ -0,0 +1,110 @@
+package picture
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"math"
+	"testing"
+	"watermark-service/internal"
+	"watermark-service/internal/util"
+
+	"github.com/opentracing/opentracing-go"
+)
+
+func pngBlob(t *testing.T, w, h int) internal.Blob {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, w, h))); err != nil {
+		t.Fatal(err)
+	}
+	return internal.Blob{Data: buf.Bytes(), Format: ".png"}
+}
+
+func TestOverlayCacheSkipsLogoDecoding(t *testing.T) {
+	cache := internal.NewOverlayCache(16, 64<<20)
+	w := NewService("", 0, 1, cache).(*pictureService)
+	logo := pngBlob(t, 20, 10)
+
+	first, err := w.logoSource(logo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.watermarkOverlay(first, "text", internal.LogoOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if !first.decoded {
+		t.Error("logo missing from the cache wasn't decoded")
+	}
+	second, _ := w.logoSource(logo)
+	if _, err := w.watermarkOverlay(second, "text", internal.LogoOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if second.decoded {
+		t.Error("cached logo was decoded")
+	}
+	third, _ := w.logoSource(logo)
+	if _, err := w.watermarkOverlay(third, "text", internal.LogoOptions{Grayscale: true}); err != nil {
+		t.Fatal(err)
+	}
+	if !third.decoded {
+		t.Error("logo with other options wasn't decoded")
+	}
+	if stats := cache.Stats(); stats.Hits != 1 || stats.Entries != 2 {
+		t.Errorf("cache stats = %+v", stats)
+	}
+}
+
+func TestLogoSource(t *testing.T) {
+	defer util.SetLimits(util.Limits)
+	util.SetLimits(util.ImageLimits{MaxWidth: 100})
+	w := NewService("", 0, 1, nil).(*pictureService)
+	tests := []struct {
+		name string
+		logo internal.Blob
+		err  error
+		ok   bool
+	}{
+		{"empty", internal.Blob{}, nil, false},
+		{"png", pngBlob(t, 20, 10), nil, true},
+		{"too wide", pngBlob(t, 101, 10), util.ErrTooLarge, false},
+		{"broken", internal.Blob{Data: []byte("not a png"), Format: ".png"}, util.ErrInvalidArg, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src, err := w.logoSource(tt.logo)
+			if err != tt.err {
+				t.Fatalf("logoSource() error = %v, want %v", err, tt.err)
+			}
+			if (src != nil) != tt.ok {
+				t.Errorf("logoSource() = %v, want a logo %v", src, tt.ok)
+			}
+		})
+	}
+}
+
+func TestCreateTileCanvasLimits(t *testing.T) {
+	internal.Tracer = opentracing.NoopTracer{}
+	w := NewService("", 0, 1, nil).(*pictureService)
+	img := pngBlob(t, 10, 10)
+	tests := []struct {
+		name string
+		tile internal.Tile
+		err  error
+	}{
+		{"huge canvas", internal.Tile{Width: math.MaxInt32, Height: math.MaxInt32}, util.ErrTooLarge},
+		{"too many pixels", internal.Tile{Width: 25000, Height: 25000}, util.ErrTooLarge},
+		{"empty canvas", internal.Tile{Width: 0, Height: 10}, util.ErrInvalidArg},
+		{"overflowing offset", internal.Tile{X: math.MaxInt, Width: 100, Height: 100}, util.ErrInvalidArg},
+		{"canvas", internal.Tile{X: 90, Y: 90, Width: 100, Height: 100}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := w.CreateTile(context.Background(), img, internal.Blob{}, "text", true, internal.LeftTop, internal.LogoOptions{}, tt.tile)
+			if err != tt.err {
+				t.Errorf("CreateTile() error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package picture
+
+import (
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"watermark-service/internal"
+	"watermark-service/internal/util"
+
+	"go.uber.org/zap"
+)
+
+// Process runs the operations one after another on the decoded image, the result is encoded
+// only once at the end according to the convert operation.
+func (w *pictureService) Process(ctx context.Context, Image internal.Blob, logo internal.Blob, ops []internal.Operation) (internal.Blob, error) {
+	span := internal.StartSpan("picture processing", ctx)
+	defer span.Finish()
+	enc := internal.DefaultEncoding
+	img, err := w.decode(Image)
+	if err != nil {
+		return internal.Blob{}, err
+	}
+	if img == nil {
+		return internal.Blob{}, util.ErrInvalidArg
+	}
+	logoSrc, err := w.logoSource(logo)
+	if err != nil {
+		return internal.Blob{}, err
+	}
+	if err := internal.ValidateOperations(ops, img.Bounds().Size(), logoSrc != nil); err != nil {
+		w.log.Error("Pipeline validation", zap.Int("Operations", len(ops)), zap.Error(err))
+		if errors.Is(err, util.ErrTooLarge) {
+			return internal.Blob{}, util.ErrTooLarge
+		}
+		return internal.Blob{}, util.ErrInvalidArg
+	}
+	for _, op := range ops {
+		stepSpan := internal.StartSpan("operation "+string(op.Type), ctx)
+		img, err = w.apply(img, logoSrc, op, &enc)
+		stepSpan.Finish()
+		if err != nil {
+			return internal.Blob{}, err
+		}
+		w.log.Info("Operation", zap.String("Type", string(op.Type)), zap.String("Status", "Complete"))
+	}
+	return w.encode(img, enc.Format, enc.Quality)
+}
+
+func (w *pictureService) apply(img image.Image, logo *logoSource, op internal.Operation, enc *internal.Encoding) (image.Image, error) {
+	switch op.Type {
+	case internal.ResizeOperation:
+		size := internal.ResizedSize(img.Bounds().Size(), op.Width, op.Height)
+		interp, _ := internal.ParseInterpolation(op.Interpolation)
+		if interp == "" {
+			interp = w.interpolation
+		}
+		return internal.Resize(img, size.X, size.Y, interp), nil
+	case internal.CropOperation:
+		r := op.Region(img.Bounds().Size())
+		if r.Empty() {
+			return nil, util.ErrInvalidArg
+		}
+		return internal.Crop(img, r), nil
+	case internal.PadOperation:
+		var col color.Color = color.Black
+		if op.Color != "" {
+			col, _ = internal.ParseHexColor(op.Color)
+		}
+		interp, _ := internal.ParseInterpolation(op.Interpolation)
+		if interp == "" {
+			interp = w.interpolation
+		}
+		return internal.Pad(img, op.Width, op.Height, col, interp), nil
+	case internal.SmartCropOperation:
+		aw, ah, _ := internal.ParseAspect(op.Aspect)
+		return internal.SmartCrop(img, aw, ah), nil
+	case internal.RotateOperation:
+		return internal.Rotate(img, op.Angle), nil
+	case internal.FlipOperation:
+		return internal.Flip(img, op.Direction == "horizontal"), nil
+	case internal.AdjustOperation:
+		return internal.Adjust(img, op.Brightness, op.Contrast), nil
+	case internal.WatermarkOperation:
+		margin := image.Pt(op.MarginX, op.MarginY)
+		return w.watermark(img, logo, op.Text, op.Fill, internal.PositionFromString(string(op.Pos)), margin, op.LogoOptions)
+	case internal.RedactOperation:
+		var col color.Color = color.Black
+		if op.Color != "" {
+			col, _ = internal.ParseHexColor(op.Color)
+		}
+		return internal.Redact(img, op.Region(img.Bounds().Size()), op.Mode == "pixelate", col), nil
+	case internal.ConvertOperation:
+		enc.Format, _ = internal.FormatFromString(op.Format)
+		enc.Quality = op.Quality
+		return img, nil
+	}
+	return nil, util.ErrInvalidArg
+}
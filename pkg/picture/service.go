@@ -2,11 +2,13 @@ package picture
 
 import (
 	"context"
-	"image"
 	"watermark-service/internal"
 )
 
 type Service interface {
-	Create(ctx context.Context, Image image.Image, logo image.Image, text string, fill bool, pos internal.Position) (image.Image, error)
-	ServiceStatus(ctx context.Context) (int64, error)
+	Create(ctx context.Context, Image internal.Blob, logo internal.Blob, text string, fill bool, pos internal.Position, logoOpts internal.LogoOptions) (internal.Blob, error)
+	CreateTile(ctx context.Context, Image internal.Blob, logo internal.Blob, text string, fill bool, pos internal.Position, logoOpts internal.LogoOptions, tile internal.Tile) (internal.Blob, error)
+	CreateBatch(ctx context.Context, images []internal.Blob, logo internal.Blob, text string, fill bool, pos internal.Position, logoOpts internal.LogoOptions) ([]internal.BatchItem, error)
+	Process(ctx context.Context, Image internal.Blob, logo internal.Blob, ops []internal.Operation) (internal.Blob, error)
+	ServiceStatus(ctx context.Context) (int64, internal.LoadStats, error)
 }
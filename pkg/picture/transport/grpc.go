@@ -3,63 +3,285 @@ package transport
 import (
 	"bytes"
 	"context"
-	"image"
-	"image/jpeg"
-	"image/png"
+	"errors"
+	"io"
+	"strconv"
 	"watermark-service/api/v1/protos/picture"
 	"watermark-service/internal"
+	"watermark-service/internal/util"
 	"watermark-service/pkg/picture/endpoints"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 )
 
 func decodeGRPCCreateRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
-	var Logo image.Image
 	req := grpcReq.(*picture.CreateRequest)
-	img := req.GetImage()
-	logo := req.GetLogo()
-	if logo != nil {
-		Logo = getImageFromByte(logo.Data, logo.Type)
-	}
 	pos := internal.PositionFromString(req.Pos.String())
-	image := getImageFromByte(img.Data, img.Type)
-	return endpoints.CreateRequest{Image: image, Logo: Logo, Text: req.Text, Fill: req.Fill, Pos: pos}, nil
+	logoOpts, err := LogoOptionsFromProto(req.GetLogoOptions())
+	if err != nil {
+		return nil, err
+	}
+	return endpoints.CreateRequest{
+		Image:       blobFromProto(req.GetImage()),
+		Logo:        blobFromProto(req.GetLogo()),
+		Text:        req.Text,
+		Fill:        req.Fill,
+		Pos:         pos,
+		LogoOptions: logoOpts,
+	}, nil
+}
+
+func decodeGRPCCreateTileRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*picture.CreateTileRequest)
+	tile := req.GetTile()
+	logoOpts, err := LogoOptionsFromProto(req.GetLogoOptions())
+	if err != nil {
+		return nil, err
+	}
+	return endpoints.CreateTileRequest{
+		Image:       blobFromProto(req.GetImage()),
+		Logo:        blobFromProto(req.GetLogo()),
+		Text:        req.Text,
+		Fill:        req.Fill,
+		Pos:         internal.PositionFromString(req.Pos.String()),
+		LogoOptions: logoOpts,
+		Tile: internal.Tile{
+			X:      int(tile.GetX()),
+			Y:      int(tile.GetY()),
+			Width:  int(tile.GetWidth()),
+			Height: int(tile.GetHeight()),
+		},
+	}, nil
+}
+
+func decodeGRPCCreateBatchRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*picture.CreateBatchRequest)
+	images := make([]internal.Blob, len(req.GetImages()))
+	for i, img := range req.GetImages() {
+		images[i] = blobFromProto(img)
+	}
+	logoOpts, err := LogoOptionsFromProto(req.GetLogoOptions())
+	if err != nil {
+		return nil, err
+	}
+	return endpoints.CreateBatchRequest{
+		Images:      images,
+		Logo:        blobFromProto(req.GetLogo()),
+		Text:        req.Text,
+		Fill:        req.Fill,
+		Pos:         internal.PositionFromString(req.Pos.String()),
+		LogoOptions: logoOpts,
+	}, nil
+}
+
+func decodeGRPCProcessRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*picture.ProcessRequest)
+	res := endpoints.ProcessRequest{
+		Image: blobFromProto(req.GetImage()),
+		Logo:  blobFromProto(req.GetLogo()),
+	}
+	for _, op := range req.GetOperations() {
+		logoOpts, err := LogoOptionsFromProto(op.GetLogoOptions())
+		if err != nil {
+			return nil, err
+		}
+		res.Operations = append(res.Operations, internal.Operation{
+			Type:          internal.OperationType(op.GetType()),
+			X:             int(op.GetX()),
+			Y:             int(op.GetY()),
+			Width:         int(op.GetWidth()),
+			Height:        int(op.GetHeight()),
+			Interpolation: op.GetInterpolation(),
+			Aspect:        op.GetAspect(),
+			Angle:         int(op.GetAngle()),
+			Direction:     op.GetDirection(),
+			Brightness:    op.GetBrightness(),
+			Contrast:      op.GetContrast(),
+			Text:          op.GetText(),
+			Fill:          op.GetFill(),
+			Pos:           internal.PositionFromString(op.GetPos().String()),
+			LogoOptions:   logoOpts,
+			Mode:          op.GetMode(),
+			Color:         op.GetColor(),
+			Format:        op.GetFormat(),
+			Quality:       int(op.GetQuality()),
+			MarginX:       int(op.GetMarginX()),
+			MarginY:       int(op.GetMarginY()),
+		})
+	}
+	return res, nil
+}
+
+// LogoOptionsFromProto rejects a key tolerance above 255, the watermark service takes the same options.
+func LogoOptionsFromProto(opts *picture.LogoOptions) (internal.LogoOptions, error) {
+	if opts.GetKeyTolerance() > 255 {
+		return internal.LogoOptions{}, status.Error(codes.InvalidArgument, util.ErrInvalidArg.Error())
+	}
+	return internal.LogoOptions{
+		KeyColor:     opts.GetKeyColor(),
+		KeyTolerance: uint8(opts.GetKeyTolerance()),
+		Tint:         opts.GetTint(),
+		Invert:       opts.GetInvert(),
+		Grayscale:    opts.GetGrayscale(),
+
+		Interpolation: opts.GetInterpolation(),
+	}, nil
+}
+
+func logoOptionsToProto(opts internal.LogoOptions) *picture.LogoOptions {
+	return &picture.LogoOptions{
+		KeyColor:     opts.KeyColor,
+		KeyTolerance: uint32(opts.KeyTolerance),
+		Tint:         opts.Tint,
+		Invert:       opts.Invert,
+		Grayscale:    opts.Grayscale,
+
+		Interpolation: opts.Interpolation,
+	}
 }
 
 func decodeGRPCServiceStatusRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
 	return endpoints.ServiceStatusRequest{}, nil
 }
 
-func encodeGRPCCreateResponse(_ context.Context, grpcResp interface{}) (interface{}, error) {
+func encodeGRPCCreateResponse(ctx context.Context, grpcResp interface{}) (interface{}, error) {
 	response := grpcResp.(endpoints.CreateResponse)
-	buf := new(bytes.Buffer)
-	png.Encode(buf, response.Image)
-	return &picture.CreateResponse{Image: buf.Bytes(), Err: response.Err}, nil
+	if err := overloaded(ctx, response.Err); err != nil {
+		return nil, err
+	}
+	return &picture.CreateResponse{Image: response.Image.Data, Type: response.Image.Format, Err: response.Err}, nil
+}
+
+func encodeGRPCCreateBatchResponse(ctx context.Context, grpcResp interface{}) (interface{}, error) {
+	response := grpcResp.(endpoints.CreateBatchResponse)
+	if err := overloaded(ctx, response.Err); err != nil {
+		return nil, err
+	}
+	items := make([]*picture.BatchItem, len(response.Items))
+	for i, item := range response.Items {
+		items[i] = &picture.BatchItem{Image: item.Image.Data, Type: item.Image.Format, Err: item.Err}
+	}
+	return &picture.CreateBatchResponse{Items: items, Err: response.Err}, nil
+}
+
+func encodeGRPCProcessResponse(ctx context.Context, grpcResp interface{}) (interface{}, error) {
+	response := grpcResp.(endpoints.ProcessResponse)
+	if err := overloaded(ctx, response.Err); err != nil {
+		return nil, err
+	}
+	return &picture.ProcessResponse{Image: blobToProto(response.Image), Err: response.Err}, nil
 }
 
 func encodeGRPCServiceStatusResponse(_ context.Context, grpcResp interface{}) (interface{}, error) {
-	response := grpcResp.(*picture.ServiceStatusResponse)
-	return endpoints.ServiceStatusResponse{Code: response.GetCode(), Err: response.GetErr()}, nil
+	response := grpcResp.(endpoints.ServiceStatusResponse)
+	return &picture.ServiceStatusResponse{
+		Code: response.Code,
+		Err:  response.Err,
+		Load: &picture.LoadStats{
+			InFlight:    int32(response.Load.InFlight),
+			MaxInFlight: int32(response.Load.MaxInFlight),
+			Queued:      int32(response.Load.Queued),
+			MaxQueued:   int32(response.Load.MaxQueued),
+			Rejected:    response.Load.Rejected,
+			AvgWaitMs:   response.Load.AvgWaitMs,
+		},
+	}, nil
 }
 
 func encodeGRPCCreateRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
 	req := grpcReq.(*endpoints.CreateRequest)
 	newReq := &picture.CreateRequest{
-		Text: req.Text,
-		Fill: req.Fill,
-		Pos:  picture.Position(picture.Position_value[string(req.Pos)]),
+		Text:        req.Text,
+		Fill:        req.Fill,
+		Pos:         picture.Position(picture.Position_value[string(req.Pos)]),
+		LogoOptions: logoOptionsToProto(req.LogoOptions),
+		Image:       blobToProto(req.Image),
+		Logo:        blobToProto(req.Logo),
 	}
-	buf := new(bytes.Buffer)
-	if req.Image != nil {
-		png.Encode(buf, req.Image)
-		newReq.Image = &picture.Image{Data: buf.Bytes(), Type: ".png"}
+	return newReq, nil
+}
+
+func encodeGRPCCreateTileRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*endpoints.CreateTileRequest)
+	return &picture.CreateTileRequest{
+		Image:       blobToProto(req.Image),
+		Logo:        blobToProto(req.Logo),
+		Text:        req.Text,
+		Fill:        req.Fill,
+		Pos:         picture.Position(picture.Position_value[string(req.Pos)]),
+		LogoOptions: logoOptionsToProto(req.LogoOptions),
+		Tile: &picture.Tile{
+			X:      int32(req.Tile.X),
+			Y:      int32(req.Tile.Y),
+			Width:  int32(req.Tile.Width),
+			Height: int32(req.Tile.Height),
+		},
+	}, nil
+}
+
+func encodeGRPCCreateBatchRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*endpoints.CreateBatchRequest)
+	images := make([]*picture.Image, len(req.Images))
+	for i, img := range req.Images {
+		// failed uploads are sent empty to keep the items in place
+		images[i] = &picture.Image{Data: img.Data, Type: img.Format}
 	}
-	if req.Logo != nil {
-		buf1 := new(bytes.Buffer)
-		png.Encode(buf1, req.Logo)
-		newReq.Logo = &picture.Image{Data: buf1.Bytes(), Type: ".png"}
+	return &picture.CreateBatchRequest{
+		Images:      images,
+		Logo:        blobToProto(req.Logo),
+		Text:        req.Text,
+		Fill:        req.Fill,
+		Pos:         picture.Position(picture.Position_value[string(req.Pos)]),
+		LogoOptions: logoOptionsToProto(req.LogoOptions),
+	}, nil
+}
+
+func encodeGRPCProcessRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*endpoints.ProcessRequest)
+	newReq := &picture.ProcessRequest{Image: blobToProto(req.Image), Logo: blobToProto(req.Logo)}
+	for _, op := range req.Operations {
+		newReq.Operations = append(newReq.Operations, &picture.Operation{
+			Type:          string(op.Type),
+			X:             int32(op.X),
+			Y:             int32(op.Y),
+			Width:         int32(op.Width),
+			Height:        int32(op.Height),
+			Interpolation: op.Interpolation,
+			Aspect:        op.Aspect,
+			Angle:         int32(op.Angle),
+			Direction:     op.Direction,
+			Brightness:    op.Brightness,
+			Contrast:      op.Contrast,
+			Text:          op.Text,
+			Fill:          op.Fill,
+			Pos:           picture.Position(picture.Position_value[string(op.Pos)]),
+			LogoOptions:   logoOptionsToProto(op.LogoOptions),
+			Mode:          op.Mode,
+			Color:         op.Color,
+			Format:        op.Format,
+			Quality:       int32(op.Quality),
+			MarginX:       int32(op.MarginX),
+			MarginY:       int32(op.MarginY),
+		})
 	}
 	return newReq, nil
 }
 
+func blobToProto(b internal.Blob) *picture.Image {
+	if b.Empty() {
+		return nil
+	}
+	return &picture.Image{Data: b.Data, Type: b.Format}
+}
+
+func blobFromProto(img *picture.Image) internal.Blob {
+	return internal.Blob{Data: img.GetData(), Format: img.GetType()}
+}
+
 func encodeGRPCServiceStatusRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
 	_ = grpcReq.(*endpoints.ServiceStatusRequest)
 	return &picture.ServiceStatusRequest{}, nil
@@ -67,29 +289,173 @@ func encodeGRPCServiceStatusRequest(_ context.Context, grpcReq interface{}) (int
 
 func decodeGRPCCreateResponse(_ context.Context, grpcResp interface{}) (interface{}, error) {
 	resp := grpcResp.(*picture.CreateResponse)
-	return &endpoints.CreateResponse{Image: getImageFromByte(resp.Image, ".png"), Err: resp.Err}, nil
+	return &endpoints.CreateResponse{Image: internal.Blob{Data: resp.Image, Format: resp.Type}, Err: resp.Err}, nil
+}
+
+func decodeGRPCCreateBatchResponse(_ context.Context, grpcResp interface{}) (interface{}, error) {
+	resp := grpcResp.(*picture.CreateBatchResponse)
+	items := make([]internal.BatchItem, len(resp.GetItems()))
+	for i, item := range resp.GetItems() {
+		items[i] = internal.BatchItem{Image: internal.Blob{Data: item.GetImage(), Format: item.GetType()}, Err: item.GetErr()}
+	}
+	return &endpoints.CreateBatchResponse{Items: items, Err: resp.Err}, nil
+}
+
+func decodeGRPCProcessResponse(_ context.Context, grpcResp interface{}) (interface{}, error) {
+	resp := grpcResp.(*picture.ProcessResponse)
+	return &endpoints.ProcessResponse{Image: blobFromProto(resp.GetImage()), Err: resp.Err}, nil
 }
 
 func decodeGRPCServiceStatusResponse(_ context.Context, grpcResp interface{}) (interface{}, error) {
 	resp := grpcResp.(*picture.ServiceStatusResponse)
-	return &endpoints.ServiceStatusResponse{Code: resp.GetCode(), Err: resp.GetErr()}, nil
+	load := resp.GetLoad()
+	return &endpoints.ServiceStatusResponse{
+		Code: resp.GetCode(),
+		Err:  resp.GetErr(),
+		Load: internal.LoadStats{
+			InFlight:    int(load.GetInFlight()),
+			MaxInFlight: int(load.GetMaxInFlight()),
+			Queued:      int(load.GetQueued()),
+			MaxQueued:   int(load.GetMaxQueued()),
+			Rejected:    load.GetRejected(),
+			AvgWaitMs:   load.GetAvgWaitMs(),
+		},
+	}, nil
+}
+
+// overloaded turns the rejection of a saturated service into ResourceExhausted,
+// the retry hint is sent in seconds in the "retry-after" header.
+func overloaded(ctx context.Context, errStr string) error {
+	var o *util.OverloadedError
+	if !errors.As(util.FromString(errStr), &o) {
+		return nil
+	}
+	grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.Itoa(o.Seconds())))
+	return status.Error(codes.ResourceExhausted, errStr)
+}
+
+// fromStatus restores the rejection of a saturated service on the client side.
+func fromStatus(err error) error {
+	if s, ok := status.FromError(err); ok && s.Code() == codes.ResourceExhausted {
+		var o *util.OverloadedError
+		if errors.As(util.FromString(s.Message()), &o) {
+			return o
+		}
+	}
+	return err
+}
+
+// chunkSize keeps the stream messages well below the default 4MB gRPC message limit.
+const chunkSize = 1 << 20
+
+func sendChunks(data []byte, send func(chunk []byte) error) error {
+	for len(data) > 0 {
+		n := min(chunkSize, len(data))
+		if err := send(data[:n]); err != nil {
+			return err
+		}
+		data = data[n:]
+	}
+	return nil
+}
+
+// sendCreateRequest streams the unary request, the header goes first without the image data.
+func sendCreateRequest(stream picture.Picture_CreateStreamClient, req *picture.CreateRequest) error {
+	header := proto.Clone(req).(*picture.CreateRequest)
+	if header.Image != nil {
+		header.Image.Data = nil
+	}
+	if header.Logo != nil {
+		header.Logo.Data = nil
+	}
+	err := stream.Send(&picture.CreateStreamRequest{Part: &picture.CreateStreamRequest_Header{Header: header}})
+	if err != nil {
+		return err
+	}
+	err = sendChunks(req.GetImage().GetData(), func(chunk []byte) error {
+		return stream.Send(&picture.CreateStreamRequest{Part: &picture.CreateStreamRequest_Image{Image: &picture.ImageChunk{Data: chunk}}})
+	})
+	if err != nil {
+		return err
+	}
+	err = sendChunks(req.GetLogo().GetData(), func(chunk []byte) error {
+		return stream.Send(&picture.CreateStreamRequest{Part: &picture.CreateStreamRequest_Logo{Logo: &picture.ImageChunk{Data: chunk}}})
+	})
+	if err != nil {
+		return err
+	}
+	return stream.CloseSend()
 }
 
-func getImageFromByte(image []byte, encoding string) image.Image {
-	switch encoding {
-	case ".png":
-		img, err := png.Decode(bytes.NewReader(image))
+// receiveCreateRequest assembles the unary request from the header and the chunks following it.
+func receiveCreateRequest(stream picture.Picture_CreateStreamServer) (*picture.CreateRequest, error) {
+	msg, err := stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	req := msg.GetHeader()
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "stream must start with the header")
+	}
+	var img, logo bytes.Buffer
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
 		if err != nil {
-			return nil
+			return nil, err
+		}
+		switch part := msg.Part.(type) {
+		case *picture.CreateStreamRequest_Image:
+			err = AppendChunk(&img, part.Image.GetData())
+		case *picture.CreateStreamRequest_Logo:
+			err = AppendChunk(&logo, part.Logo.GetData())
+		default:
+			err = status.Error(codes.InvalidArgument, "unexpected header")
 		}
-		return img
-	case ".jpg":
-		img, err := jpeg.Decode(bytes.NewReader(image))
 		if err != nil {
-			return nil
+			return nil, err
+		}
+	}
+	if req.Image != nil {
+		req.Image.Data = img.Bytes()
+	}
+	if req.Logo != nil {
+		req.Logo.Data = logo.Bytes()
+	}
+	return req, nil
+}
+
+// AppendChunk adds the streamed chunk to the file, refusing it once the file is over the size limit.
+func AppendChunk(buf *bytes.Buffer, chunk []byte) error {
+	if util.Limits.CheckBytes(int64(buf.Len()+len(chunk))) != nil {
+		return status.Error(codes.InvalidArgument, util.ErrTooLarge.Error())
+	}
+	buf.Write(chunk)
+	return nil
+}
+
+// receiveCreateResponse joins the streamed result back into the unary response.
+func receiveCreateResponse(stream picture.Picture_CreateStreamClient) (*picture.CreateResponse, error) {
+	var resp picture.CreateResponse
+	var img bytes.Buffer
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		img.Write(msg.GetChunk())
+		if msg.GetErr() != "" {
+			resp.Err = msg.GetErr()
+		}
+		if msg.GetType() != "" {
+			resp.Type = msg.GetType()
 		}
-		return img
-	default:
-		return nil
 	}
+	resp.Image = img.Bytes()
+	return &resp, nil
 }
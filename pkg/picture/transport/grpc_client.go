@@ -2,7 +2,6 @@ package transport
 
 import (
 	"context"
-	"image"
 	"watermark-service/api/v1/protos/picture"
 	"watermark-service/internal"
 	"watermark-service/internal/util"
@@ -16,27 +15,54 @@ import (
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 )
 
 type grpcClient struct {
 	create        endpoint.Endpoint
+	createTile    endpoint.Endpoint
+	createBatch   endpoint.Endpoint
+	process       endpoint.Endpoint
 	serviceStatus endpoint.Endpoint
 }
 
 func NewGRPCClient(conn *grpc.ClientConn) service.Service {
 	logger := zapkit.NewZapSugarLogger(zap.L(), zapcore.DebugLevel)
 	return &grpcClient{
-		create: grpckit.NewClient(
+		create: makeCreateStreamEndpoint(picture.NewPictureClient(conn)),
+		createTile: grpckit.NewClient(
 			conn,
 			"picture.Picture",
-			"Create",
-			encodeGRPCCreateRequest,
+			"CreateTile",
+			encodeGRPCCreateTileRequest,
 			decodeGRPCCreateResponse,
 			picture.CreateResponse{},
 			grpckit.ClientBefore(
 				opentracing.ContextToGRPC(internal.Tracer, logger),
 			),
 		).Endpoint(),
+		createBatch: grpckit.NewClient(
+			conn,
+			"picture.Picture",
+			"CreateBatch",
+			encodeGRPCCreateBatchRequest,
+			decodeGRPCCreateBatchResponse,
+			picture.CreateBatchResponse{},
+			grpckit.ClientBefore(
+				opentracing.ContextToGRPC(internal.Tracer, logger),
+			),
+		).Endpoint(),
+		process: grpckit.NewClient(
+			conn,
+			"picture.Picture",
+			"Process",
+			encodeGRPCProcessRequest,
+			decodeGRPCProcessResponse,
+			picture.ProcessResponse{},
+			grpckit.ClientBefore(
+				opentracing.ContextToGRPC(internal.Tracer, logger),
+			),
+		).Endpoint(),
 		serviceStatus: grpckit.NewClient(
 			conn,
 			"picture.Picture",
@@ -51,22 +77,97 @@ func NewGRPCClient(conn *grpc.ClientConn) service.Service {
 	}
 }
 
-func (c *grpcClient) Create(ctx context.Context, Image image.Image, logo image.Image, text string, fill bool, pos internal.Position) (image.Image, error) {
-	req := &endpoints.CreateRequest{Image: Image, Logo: logo, Text: text, Fill: fill, Pos: pos}
+// makeCreateStreamEndpoint calls CreateStream so images aren't bound by the gRPC message size limit,
+// the unary codecs are reused on both ends of the stream.
+func makeCreateStreamEndpoint(client picture.PictureClient) endpoint.Endpoint {
+	logger := zapkit.NewZapSugarLogger(zap.L(), zapcore.DebugLevel)
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, err := encodeGRPCCreateRequest(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+		md := metadata.MD{}
+		ctx = opentracing.ContextToGRPC(internal.Tracer, logger)(ctx, &md)
+		stream, err := client.CreateStream(metadata.NewOutgoingContext(ctx, md))
+		if err != nil {
+			return nil, err
+		}
+		if err := sendCreateRequest(stream, req.(*picture.CreateRequest)); err != nil {
+			return nil, err
+		}
+		resp, err := receiveCreateResponse(stream)
+		if err != nil {
+			return nil, err
+		}
+		return decodeGRPCCreateResponse(ctx, resp)
+	}
+}
+
+func (c *grpcClient) Create(ctx context.Context, Image internal.Blob, logo internal.Blob, text string, fill bool, pos internal.Position, logoOpts internal.LogoOptions) (internal.Blob, error) {
+	req := &endpoints.CreateRequest{Image: Image, Logo: logo, Text: text, Fill: fill, Pos: pos, LogoOptions: logoOpts}
 	r, err := c.create(ctx, req)
 	if err != nil {
-		return nil, err
+		return internal.Blob{}, fromStatus(err)
+	}
+	resp := r.(*endpoints.CreateResponse)
+	return resp.Image, util.FromString(resp.Err)
+}
+
+func (c *grpcClient) CreateTile(ctx context.Context, Image internal.Blob, logo internal.Blob, text string, fill bool, pos internal.Position, logoOpts internal.LogoOptions, tile internal.Tile) (internal.Blob, error) {
+	req := &endpoints.CreateTileRequest{Image: Image, Logo: logo, Text: text, Fill: fill, Pos: pos, LogoOptions: logoOpts, Tile: tile}
+	r, err := c.createTile(ctx, req)
+	if err != nil {
+		return internal.Blob{}, fromStatus(err)
 	}
 	resp := r.(*endpoints.CreateResponse)
 	return resp.Image, util.FromString(resp.Err)
 }
 
-func (c *grpcClient) ServiceStatus(ctx context.Context) (int64, error) {
+// CreateBatch splits the images into calls fitting service.MaxMessageSize, half of a message
+// is left for the watermarked images which may come out bigger than the uploaded ones.
+func (c *grpcClient) CreateBatch(ctx context.Context, images []internal.Blob, logo internal.Blob, text string, fill bool, pos internal.Position, logoOpts internal.LogoOptions) ([]internal.BatchItem, error) {
+	items := make([]internal.BatchItem, 0, len(images))
+	for start := 0; start < len(images); {
+		end, size := start, int64(0)
+		for end < len(images) && end-start < service.MaxBatchSize {
+			s := int64(len(images[end].Data))
+			if end > start && size+s > service.MaxMessageSize/2 {
+				break
+			}
+			size += s
+			end++
+		}
+		req := &endpoints.CreateBatchRequest{Images: images[start:end], Logo: logo, Text: text, Fill: fill, Pos: pos, LogoOptions: logoOpts}
+		r, err := c.createBatch(ctx, req)
+		if err != nil {
+			return items, fromStatus(err)
+		}
+		resp := r.(*endpoints.CreateBatchResponse)
+		if err := util.FromString(resp.Err); err != nil {
+			return items, err
+		}
+		items = append(items, resp.Items...)
+		start = end
+	}
+	return items, nil
+}
+
+func (c *grpcClient) Process(ctx context.Context, Image internal.Blob, logo internal.Blob, ops []internal.Operation) (internal.Blob, error) {
+	req := &endpoints.ProcessRequest{Image: Image, Logo: logo, Operations: ops}
+	r, err := c.process(ctx, req)
+	if err != nil {
+		return internal.Blob{}, fromStatus(err)
+	}
+	resp := r.(*endpoints.ProcessResponse)
+	return resp.Image, util.FromString(resp.Err)
+}
+
+func (c *grpcClient) ServiceStatus(ctx context.Context) (int64, internal.LoadStats, error) {
 	req := &endpoints.ServiceStatusRequest{}
 	r, err := c.serviceStatus(ctx, req)
 	if err != nil {
-		return 0, err
+		return 0, internal.LoadStats{}, err
 	}
 	resp := r.(*endpoints.ServiceStatusResponse)
-	return resp.Code, util.FromString(resp.Err)
+	return resp.Code, resp.Load, util.FromString(resp.Err)
 }
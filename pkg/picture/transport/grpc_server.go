@@ -15,6 +15,9 @@ import (
 
 type grpcServer struct {
 	create        grpckit.Handler
+	createTile    grpckit.Handler
+	createBatch   grpckit.Handler
+	process       grpckit.Handler
 	serviceStatus grpckit.Handler
 	picture.UnimplementedPictureServer
 }
@@ -34,6 +37,42 @@ func NewGRPCServer(ep endpoints.Set) picture.PictureServer {
 				),
 			),
 		),
+		createTile: grpckit.NewServer(
+			ep.CreateTileEndpoint,
+			decodeGRPCCreateTileRequest,
+			encodeGRPCCreateResponse,
+			grpckit.ServerBefore(
+				opentracing.GRPCToContext(
+					internal.Tracer,
+					"CreateTile method",
+					logger,
+				),
+			),
+		),
+		createBatch: grpckit.NewServer(
+			ep.CreateBatchEndpoint,
+			decodeGRPCCreateBatchRequest,
+			encodeGRPCCreateBatchResponse,
+			grpckit.ServerBefore(
+				opentracing.GRPCToContext(
+					internal.Tracer,
+					"CreateBatch method",
+					logger,
+				),
+			),
+		),
+		process: grpckit.NewServer(
+			ep.ProcessEndpoint,
+			decodeGRPCProcessRequest,
+			encodeGRPCProcessResponse,
+			grpckit.ServerBefore(
+				opentracing.GRPCToContext(
+					internal.Tracer,
+					"Process method",
+					logger,
+				),
+			),
+		),
 		serviceStatus: grpckit.NewServer(
 			ep.ServiceStatusEndpoint,
 			decodeGRPCServiceStatusRequest,
@@ -57,6 +96,54 @@ func (g *grpcServer) Create(ctx context.Context, r *picture.CreateRequest) (*pic
 	return rep.(*picture.CreateResponse), nil
 }
 
+func (g *grpcServer) CreateTile(ctx context.Context, r *picture.CreateTileRequest) (*picture.CreateResponse, error) {
+	_, rep, err := g.createTile.ServeGRPC(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	return rep.(*picture.CreateResponse), nil
+}
+
+func (g *grpcServer) CreateBatch(ctx context.Context, r *picture.CreateBatchRequest) (*picture.CreateBatchResponse, error) {
+	_, rep, err := g.createBatch.ServeGRPC(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	return rep.(*picture.CreateBatchResponse), nil
+}
+
+// CreateStream runs the unary Create handler on the request assembled from the stream
+// and sends the resulting image back in chunks.
+func (g *grpcServer) CreateStream(stream picture.Picture_CreateStreamServer) error {
+	req, err := receiveCreateRequest(stream)
+	if err != nil {
+		return err
+	}
+	_, rep, err := g.create.ServeGRPC(stream.Context(), req)
+	if err != nil {
+		return err
+	}
+	resp := rep.(*picture.CreateResponse)
+	if resp.Err != "" {
+		return stream.Send(&picture.CreateStreamResponse{Err: resp.Err})
+	}
+	// the format goes with the first chunk
+	format := resp.Type
+	return sendChunks(resp.Image, func(chunk []byte) error {
+		msg := &picture.CreateStreamResponse{Chunk: chunk, Type: format}
+		format = ""
+		return stream.Send(msg)
+	})
+}
+
+func (g *grpcServer) Process(ctx context.Context, r *picture.ProcessRequest) (*picture.ProcessResponse, error) {
+	_, rep, err := g.process.ServeGRPC(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	return rep.(*picture.ProcessResponse), nil
+}
+
 func (g *grpcServer) ServiceStatus(ctx context.Context, r *picture.ServiceStatusRequest) (*picture.ServiceStatusResponse, error) {
 	_, rep, err := g.serviceStatus.ServeGRPC(ctx, r)
 	if err != nil {
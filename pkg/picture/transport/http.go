@@ -3,11 +3,11 @@ package transport
 import (
 	"context"
 	"encoding/json"
-	"image"
-	"image/jpeg"
-	"image/png"
+	"errors"
+	"io"
 	"net/http"
 	"regexp"
+	"strconv"
 	"watermark-service/internal"
 	"watermark-service/internal/util"
 	"watermark-service/pkg/picture"
@@ -27,6 +27,7 @@ func NewHTTPHandler(ep endpoints.Set) http.Handler {
 		ep.CreateEndpoint,
 		decodeHTTPCreateRequest,
 		encodeCreateResponse,
+		httpkit.ServerErrorEncoder(encodeError),
 		httpkit.ServerBefore(
 			extractImages,
 			opentracing.HTTPToContext(
@@ -36,6 +37,20 @@ func NewHTTPHandler(ep endpoints.Set) http.Handler {
 			),
 		),
 	))
+	m.Handle("/process", httpkit.NewServer(
+		ep.ProcessEndpoint,
+		decodeHTTPProcessRequest,
+		encodeProcessResponse,
+		httpkit.ServerErrorEncoder(encodeError),
+		httpkit.ServerBefore(
+			extractImages,
+			opentracing.HTTPToContext(
+				internal.Tracer,
+				"Process method",
+				zapkit.NewZapSugarLogger(zap.L(), zapcore.DebugLevel),
+			),
+		),
+	))
 	m.Handle("/healthz", httpkit.NewServer(
 		ep.ServiceStatusEndpoint,
 		decodeHTTPServiceStatusRequest,
@@ -58,33 +73,94 @@ func decodeHTTPServiceStatusRequest(_ context.Context, _ *http.Request) (interfa
 
 func decodeHTTPCreateRequest(ctx context.Context, r *http.Request) (interface{}, error) {
 	var req endpoints.CreateRequest
-	val := ctx.Value(picture.ImageContextKey("image"))
-	if val == nil {
-		return nil, util.ErrInvalidArg
+	if err, ok := ctx.Value(picture.ErrorContextKey("error")).(error); ok {
+		return nil, err
 	}
-	img, ok := val.(image.Image)
-	if !ok {
+	img, ok := ctx.Value(picture.ImageContextKey("image")).(internal.Blob)
+	if !ok || img.Empty() {
 		return nil, util.ErrInvalidArg
 	}
-	val = ctx.Value(picture.LogoContextKey("logo"))
-	logo, ok := val.(image.Image)
-	if !ok {
-		req.Logo = nil
-	}
 	req.Image = img
-	req.Logo = logo
+	req.Logo, _ = ctx.Value(picture.LogoContextKey("logo")).(internal.Blob)
 	req.Fill = r.FormValue("fill") == "true"
 	req.Text = r.FormValue("text")
 	req.Pos = internal.PositionFromString(r.FormValue("pos"))
+	logoOpts, err := LogoOptionsFromForm(r)
+	if err != nil {
+		return nil, err
+	}
+	req.LogoOptions = logoOpts
 	return req, nil
 }
 
+// decodeHTTPProcessRequest expects the pipeline as a JSON array in the "operations" form field.
+func decodeHTTPProcessRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req endpoints.ProcessRequest
+	if err, ok := ctx.Value(picture.ErrorContextKey("error")).(error); ok {
+		return nil, err
+	}
+	img, ok := ctx.Value(picture.ImageContextKey("image")).(internal.Blob)
+	if !ok || img.Empty() {
+		return nil, util.ErrInvalidArg
+	}
+	req.Image = img
+	req.Logo, _ = ctx.Value(picture.LogoContextKey("logo")).(internal.Blob)
+	if err := json.Unmarshal([]byte(r.FormValue("operations")), &req.Operations); err != nil {
+		return nil, util.ErrInvalidArg
+	}
+	return req, nil
+}
+
+// LogoOptionsFromForm rejects a key_tolerance out of [0, 255], the watermark service takes the same form fields.
+func LogoOptionsFromForm(r *http.Request) (internal.LogoOptions, error) {
+	var tolerance uint64
+	if value := r.FormValue("key_tolerance"); value != "" {
+		var err error
+		if tolerance, err = strconv.ParseUint(value, 10, 8); err != nil {
+			return internal.LogoOptions{}, util.ErrInvalidArg
+		}
+	}
+	return internal.LogoOptions{
+		KeyColor:     r.FormValue("key_color"),
+		KeyTolerance: uint8(tolerance),
+		Tint:         r.FormValue("tint"),
+		Invert:       r.FormValue("invert") == "true",
+		Grayscale:    r.FormValue("grayscale") == "true",
+
+		Interpolation: r.FormValue("interpolation"),
+	}, nil
+}
+
 func encodeCreateResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
-	if resp, ok := response.(endpoints.CreateResponse); ok {
+	resp, ok := response.(endpoints.CreateResponse)
+	if !ok {
+		return encodeResponse(ctx, w, response)
+	}
+	return writeImage(ctx, w, resp.Image, resp.Err)
+}
+
+func encodeProcessResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	resp, ok := response.(endpoints.ProcessResponse)
+	if !ok {
+		return encodeResponse(ctx, w, response)
+	}
+	return writeImage(ctx, w, resp.Image, resp.Err)
+}
+
+// writeImage sends the encoded image as is, or the error when the request failed.
+func writeImage(ctx context.Context, w http.ResponseWriter, img internal.Blob, errStr string) error {
+	if errStr != "" {
+		encodeError(ctx, util.FromString(errStr), w)
+		return nil
+	}
+	switch img.Format {
+	case ".jpg":
+		w.Header().Set("Content-Type", "image/jpeg")
+	default:
 		w.Header().Set("Content-Type", "image/png")
-		return png.Encode(w, resp.Image)
 	}
-	return encodeResponse(ctx, w, response)
+	_, err := w.Write(img.Data)
+	return err
 }
 
 func encodeResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
@@ -97,11 +173,22 @@ func encodeResponse(ctx context.Context, w http.ResponseWriter, response interfa
 
 func encodeError(_ context.Context, err error, w http.ResponseWriter) {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	var overloaded *util.OverloadedError
+	if errors.As(err, &overloaded) {
+		w.Header().Set("Retry-After", strconv.Itoa(overloaded.Seconds()))
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
 	switch err {
 	case util.ErrUnknownArg:
 		w.WriteHeader(http.StatusNotFound)
 	case util.ErrInvalidArg:
 		w.WriteHeader(http.StatusBadRequest)
+	case util.ErrTooLarge:
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
 	default:
 		w.WriteHeader(http.StatusInternalServerError)
 	}
@@ -110,44 +197,56 @@ func encodeError(_ context.Context, err error, w http.ResponseWriter) {
 	})
 }
 
+// extractImages puts the uploaded images in the context, an upload exceeding util.Limits
+// is stored there as an error to be returned by the request decoder.
 func extractImages(ctx context.Context, r *http.Request) (newCtx context.Context) {
+	// the image and the logo with some room for the other fields
+	r.Body = http.MaxBytesReader(nil, r.Body, 2*util.Limits.MaxBytes+1<<20)
 	err := r.ParseMultipartForm(32 << 20)
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return context.WithValue(ctx, picture.ErrorContextKey("error"), util.ErrTooLarge)
+	}
 	if err != nil {
 		return ctx
 	}
-	img := getImageFromFile("image", r)
+	img, err := getImageFromFile("image", r)
+	if err != nil {
+		return context.WithValue(ctx, picture.ErrorContextKey("error"), err)
+	}
 	newCtx = context.WithValue(ctx, picture.ImageContextKey("image"), img)
-	logo := getImageFromFile("logo", r)
+	logo, err := getImageFromFile("logo", r)
+	if err != nil {
+		return context.WithValue(ctx, picture.ErrorContextKey("error"), err)
+	}
 	newCtx = context.WithValue(newCtx, picture.LogoContextKey("logo"), logo)
 	return
 }
 
-func getImageFromFile(name string, r *http.Request) image.Image {
-	var image image.Image
-	var regexp_result []string
-
+// getImageFromFile returns an error only for images exceeding util.Limits, missing or undecodable
+// files result in an empty blob. The upload is kept encoded, only its header is checked.
+func getImageFromFile(name string, r *http.Request) (internal.Blob, error) {
 	res, _ := regexp.Compile(`.[0-9a-z]+$`)
 	file, header, err := r.FormFile(name)
 	if err != nil {
-		return nil
+		return internal.Blob{}, nil
+	}
+	defer file.Close()
+	if err := util.Limits.CheckBytes(header.Size); err != nil {
+		return internal.Blob{}, err
 	}
-	regexp_result = res.FindAllString(header.Filename, -1)
+	regexp_result := res.FindAllString(header.Filename, -1)
 	if len(regexp_result) == 0 {
-		return nil
+		return internal.Blob{}, nil
 	}
-	switch regexp_result[0] {
-	case ".png":
-		image, err = png.Decode(file)
-		if err != nil {
-			image = nil
-		}
-	case ".jpg":
-		image, err = jpeg.Decode(file)
-		if err != nil {
-			image = nil
-		}
-	default:
-		image = nil
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return internal.Blob{}, nil
+	}
+	if _, err := util.CheckImage(data, regexp_result[0]); err == util.ErrTooLarge {
+		return internal.Blob{}, err
+	} else if err != nil {
+		return internal.Blob{}, nil
 	}
-	return image
+	return internal.Blob{Data: data, Format: regexp_result[0]}, nil
 }
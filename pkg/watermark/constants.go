@@ -1,5 +1,34 @@
 package watermark
 
+import "time"
+
 type LogoContextKey string
 
 type ImageContextKey string
+
+// The images above DefaultTilingThreshold megapixels are rendered in tiles of DefaultTileSize megapixels,
+// a tile stays well below the picture service message size.
+const (
+	DefaultTilingThreshold = 100
+	DefaultTileSize        = 8
+)
+
+// Add jobs are rendered by DefaultJobWorkers workers, at most DefaultJobQueueSize jobs wait for them
+// in the database. A failed job is retried DefaultJobMaxAttempts times starting after DefaultJobBackoff.
+const (
+	DefaultJobWorkers     = 4
+	DefaultJobQueueSize   = 1000
+	DefaultJobMaxAttempts = 5
+	DefaultJobLease       = 5 * time.Minute
+	DefaultJobBackoff     = 5 * time.Second
+)
+
+// DefaultLinkTTL is the lifetime of the signed download links.
+const DefaultLinkTTL = time.Hour
+
+// MaxMessageSize is the gRPC message size accepted by the service, a unary AddBatch carries all
+// its images in one message. The HTTP batch uploads are bounded the same way.
+const MaxMessageSize = 512 << 20
+
+// uploadWorkers bounds the concurrent storage uploads of a batch.
+const uploadWorkers = 8
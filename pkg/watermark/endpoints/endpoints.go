@@ -3,7 +3,6 @@ package endpoints
 import (
 	"context"
 	"errors"
-	"image"
 	"watermark-service/internal"
 	"watermark-service/pkg/watermark"
 
@@ -12,16 +11,56 @@ import (
 )
 
 type Set struct {
-	GetEndpoint           endpoint.Endpoint
-	AddEndpoint           endpoint.Endpoint
+	GetEndpoint          endpoint.Endpoint
+	AddEndpoint          endpoint.Endpoint
+	AddBatchEndpoint     endpoint.Endpoint
+	GetStatusEndpoint    endpoint.Endpoint
+	ListDeadJobsEndpoint endpoint.Endpoint
+	RequeueJobEndpoint   endpoint.Endpoint
+
+	RegisterWebhookEndpoint       endpoint.Endpoint
+	ListWebhooksEndpoint          endpoint.Endpoint
+	RemoveWebhookEndpoint         endpoint.Endpoint
+	ListWebhookDeliveriesEndpoint endpoint.Endpoint
+	PingWebhookEndpoint           endpoint.Endpoint
+
+	StreamTokenEndpoint endpoint.Endpoint
+	WatchEndpoint       endpoint.Endpoint
+	DownloadEndpoint    endpoint.Endpoint
+
+	CreateShareLinkEndpoint endpoint.Endpoint
+	ListShareLinksEndpoint  endpoint.Endpoint
+	RevokeShareLinkEndpoint endpoint.Endpoint
+	OpenShareLinkEndpoint   endpoint.Endpoint
+
 	RemoveEndpoint        endpoint.Endpoint
 	ServiceStatusEndpoint endpoint.Endpoint
 }
 
 func NewEndpointSet(svc watermark.Service) Set {
 	return Set{
-		GetEndpoint:           MakeGetEndpoint(svc),
-		AddEndpoint:           MakeAddEndpoint(svc),
+		GetEndpoint:          MakeGetEndpoint(svc),
+		AddEndpoint:          MakeAddEndpoint(svc),
+		AddBatchEndpoint:     MakeAddBatchEndpoint(svc),
+		GetStatusEndpoint:    MakeGetStatusEndpoint(svc),
+		ListDeadJobsEndpoint: MakeListDeadJobsEndpoint(svc),
+		RequeueJobEndpoint:   MakeRequeueJobEndpoint(svc),
+
+		RegisterWebhookEndpoint:       MakeRegisterWebhookEndpoint(svc),
+		ListWebhooksEndpoint:          MakeListWebhooksEndpoint(svc),
+		RemoveWebhookEndpoint:         MakeRemoveWebhookEndpoint(svc),
+		ListWebhookDeliveriesEndpoint: MakeListWebhookDeliveriesEndpoint(svc),
+		PingWebhookEndpoint:           MakePingWebhookEndpoint(svc),
+
+		StreamTokenEndpoint: MakeStreamTokenEndpoint(svc),
+		WatchEndpoint:       MakeWatchEndpoint(svc),
+		DownloadEndpoint:    MakeDownloadEndpoint(svc),
+
+		CreateShareLinkEndpoint: MakeCreateShareLinkEndpoint(svc),
+		ListShareLinksEndpoint:  MakeListShareLinksEndpoint(svc),
+		RevokeShareLinkEndpoint: MakeRevokeShareLinkEndpoint(svc),
+		OpenShareLinkEndpoint:   MakeOpenShareLinkEndpoint(svc),
+
 		RemoveEndpoint:        MakeRemoveEndpoint(svc),
 		ServiceStatusEndpoint: MakeServiceStatusEndpoint(svc),
 	}
@@ -43,15 +82,204 @@ func MakeGetEndpoint(svc watermark.Service) endpoint.Endpoint {
 func MakeAddEndpoint(svc watermark.Service) endpoint.Endpoint {
 	endpoint := func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(AddRequest)
-		ticketID, err := svc.Add(ctx, req.Logo, req.Image, req.Text, req.Fill, req.Pos)
+		ticketID, outputs, err := svc.Add(ctx, req.Logo, req.Image, req.Text, req.Fill, req.Pos, req.LogoOptions, req.Presets)
 		if err != nil {
-			return AddResponse{TicketID: ticketID, Err: err.Error()}, nil
+			return AddResponse{TicketID: ticketID, Outputs: outputs, Err: err.Error()}, nil
 		}
-		return AddResponse{TicketID: ticketID}, nil
+		return AddResponse{TicketID: ticketID, Outputs: outputs}, nil
 	}
 	return opentracing.TraceServer(internal.Tracer, "Add method")(endpoint)
 }
 
+func MakeAddBatchEndpoint(svc watermark.Service) endpoint.Endpoint {
+	endpoint := func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(AddBatchRequest)
+		items, err := svc.AddBatch(ctx, req.Logo, req.Images, req.Text, req.Fill, req.Pos, req.LogoOptions)
+		if err != nil {
+			return AddBatchResponse{Items: items, Err: err.Error()}, nil
+		}
+		return AddBatchResponse{Items: items}, nil
+	}
+	return opentracing.TraceServer(internal.Tracer, "AddBatch method")(endpoint)
+}
+
+func MakeGetStatusEndpoint(svc watermark.Service) endpoint.Endpoint {
+	endpoint := func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(GetStatusRequest)
+		job, err := svc.GetStatus(ctx, req.TicketID)
+		if err != nil {
+			return GetStatusResponse{Job: job, Err: err.Error()}, nil
+		}
+		return GetStatusResponse{Job: job}, nil
+	}
+	return opentracing.TraceServer(internal.Tracer, "GetStatus method")(endpoint)
+}
+
+func MakeListDeadJobsEndpoint(svc watermark.Service) endpoint.Endpoint {
+	endpoint := func(ctx context.Context, request interface{}) (interface{}, error) {
+		_ = request.(ListDeadJobsRequest)
+		jobs, err := svc.ListDeadJobs(ctx)
+		if err != nil {
+			return ListDeadJobsResponse{Jobs: jobs, Err: err.Error()}, nil
+		}
+		return ListDeadJobsResponse{Jobs: jobs}, nil
+	}
+	return opentracing.TraceServer(internal.Tracer, "ListDeadJobs method")(endpoint)
+}
+
+func MakeRequeueJobEndpoint(svc watermark.Service) endpoint.Endpoint {
+	endpoint := func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(RequeueJobRequest)
+		if err := svc.RequeueJob(ctx, req.TicketID); err != nil {
+			return RequeueJobResponse{Err: err.Error()}, nil
+		}
+		return RequeueJobResponse{}, nil
+	}
+	return opentracing.TraceServer(internal.Tracer, "RequeueJob method")(endpoint)
+}
+
+func MakeRegisterWebhookEndpoint(svc watermark.Service) endpoint.Endpoint {
+	endpoint := func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(RegisterWebhookRequest)
+		hook, err := svc.RegisterWebhook(ctx, req.URL, req.Events)
+		if err != nil {
+			return WebhookResponse{Webhook: hook, Err: err.Error()}, nil
+		}
+		return WebhookResponse{Webhook: hook}, nil
+	}
+	return opentracing.TraceServer(internal.Tracer, "RegisterWebhook method")(endpoint)
+}
+
+func MakeListWebhooksEndpoint(svc watermark.Service) endpoint.Endpoint {
+	endpoint := func(ctx context.Context, request interface{}) (interface{}, error) {
+		_ = request.(ListWebhooksRequest)
+		hooks, err := svc.ListWebhooks(ctx)
+		if err != nil {
+			return ListWebhooksResponse{Webhooks: hooks, Err: err.Error()}, nil
+		}
+		return ListWebhooksResponse{Webhooks: hooks}, nil
+	}
+	return opentracing.TraceServer(internal.Tracer, "ListWebhooks method")(endpoint)
+}
+
+func MakeRemoveWebhookEndpoint(svc watermark.Service) endpoint.Endpoint {
+	endpoint := func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(WebhookRequest)
+		if err := svc.RemoveWebhook(ctx, req.WebhookID); err != nil {
+			return RemoveWebhookResponse{Err: err.Error()}, nil
+		}
+		return RemoveWebhookResponse{}, nil
+	}
+	return opentracing.TraceServer(internal.Tracer, "RemoveWebhook method")(endpoint)
+}
+
+func MakeListWebhookDeliveriesEndpoint(svc watermark.Service) endpoint.Endpoint {
+	endpoint := func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(WebhookRequest)
+		deliveries, err := svc.ListWebhookDeliveries(ctx, req.WebhookID)
+		if err != nil {
+			return ListWebhookDeliveriesResponse{Deliveries: deliveries, Err: err.Error()}, nil
+		}
+		return ListWebhookDeliveriesResponse{Deliveries: deliveries}, nil
+	}
+	return opentracing.TraceServer(internal.Tracer, "ListWebhookDeliveries method")(endpoint)
+}
+
+func MakePingWebhookEndpoint(svc watermark.Service) endpoint.Endpoint {
+	endpoint := func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(WebhookRequest)
+		delivery, err := svc.PingWebhook(ctx, req.WebhookID)
+		if err != nil {
+			return PingWebhookResponse{Delivery: delivery, Err: err.Error()}, nil
+		}
+		return PingWebhookResponse{Delivery: delivery}, nil
+	}
+	return opentracing.TraceServer(internal.Tracer, "PingWebhook method")(endpoint)
+}
+
+func MakeStreamTokenEndpoint(svc watermark.Service) endpoint.Endpoint {
+	endpoint := func(ctx context.Context, request interface{}) (interface{}, error) {
+		_ = request.(StreamTokenRequest)
+		token, err := svc.StreamToken(ctx)
+		if err != nil {
+			return StreamTokenResponse{Err: err.Error()}, nil
+		}
+		return StreamTokenResponse{Token: token}, nil
+	}
+	return opentracing.TraceServer(internal.Tracer, "StreamToken method")(endpoint)
+}
+
+func MakeWatchEndpoint(svc watermark.Service) endpoint.Endpoint {
+	endpoint := func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(WatchRequest)
+		updates, err := svc.Watch(ctx, req.StreamToken)
+		if err != nil {
+			return WatchResponse{Err: err.Error()}, nil
+		}
+		return WatchResponse{Updates: updates}, nil
+	}
+	return opentracing.TraceServer(internal.Tracer, "Watch method")(endpoint)
+}
+
+func MakeDownloadEndpoint(svc watermark.Service) endpoint.Endpoint {
+	endpoint := func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(DownloadRequest)
+		download, err := svc.Download(ctx, req.DocumentID, req.Expires, req.Signature)
+		if err != nil {
+			return DownloadResponse{Err: err.Error()}, nil
+		}
+		return DownloadResponse{Download: download}, nil
+	}
+	return opentracing.TraceServer(internal.Tracer, "Download method")(endpoint)
+}
+
+func MakeCreateShareLinkEndpoint(svc watermark.Service) endpoint.Endpoint {
+	endpoint := func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(CreateShareLinkRequest)
+		link, err := svc.CreateShareLink(ctx, req.DocumentID, req.ExpiresAt, req.Password, req.MaxViews)
+		if err != nil {
+			return ShareLinkResponse{Link: link, Err: err.Error()}, nil
+		}
+		return ShareLinkResponse{Link: link}, nil
+	}
+	return opentracing.TraceServer(internal.Tracer, "CreateShareLink method")(endpoint)
+}
+
+func MakeListShareLinksEndpoint(svc watermark.Service) endpoint.Endpoint {
+	endpoint := func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(ListShareLinksRequest)
+		links, err := svc.ListShareLinks(ctx, req.DocumentID)
+		if err != nil {
+			return ListShareLinksResponse{Links: links, Err: err.Error()}, nil
+		}
+		return ListShareLinksResponse{Links: links}, nil
+	}
+	return opentracing.TraceServer(internal.Tracer, "ListShareLinks method")(endpoint)
+}
+
+func MakeRevokeShareLinkEndpoint(svc watermark.Service) endpoint.Endpoint {
+	endpoint := func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(ShareLinkRequest)
+		if err := svc.RevokeShareLink(ctx, req.LinkID); err != nil {
+			return RevokeShareLinkResponse{Err: err.Error()}, nil
+		}
+		return RevokeShareLinkResponse{}, nil
+	}
+	return opentracing.TraceServer(internal.Tracer, "RevokeShareLink method")(endpoint)
+}
+
+func MakeOpenShareLinkEndpoint(svc watermark.Service) endpoint.Endpoint {
+	endpoint := func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(OpenShareLinkRequest)
+		download, err := svc.OpenShareLink(ctx, req.LinkID, req.Password)
+		if err != nil {
+			return DownloadResponse{Err: err.Error()}, nil
+		}
+		return DownloadResponse{Download: download}, nil
+	}
+	return opentracing.TraceServer(internal.Tracer, "OpenShareLink method")(endpoint)
+}
+
 func MakeRemoveEndpoint(svc watermark.Service) endpoint.Endpoint {
 	endpoint := func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(RemoveRequest)
@@ -88,16 +316,208 @@ func (s *Set) Get(ctx context.Context, filters ...internal.Filter) ([]internal.D
 	return getResp.Documents, nil
 }
 
-func (s *Set) Add(ctx context.Context, logo image.Image, image image.Image, text string, fill bool, pos internal.Position) (string, error) {
-	resp, err := s.AddEndpoint(ctx, AddRequest{Logo: logo, Image: image, Text: text, Fill: fill, Pos: pos})
+func (s *Set) Add(ctx context.Context, logo internal.Blob, image internal.Blob, text string, fill bool, pos internal.Position, logoOpts internal.LogoOptions, presets []string) (string, map[string]string, error) {
+	resp, err := s.AddEndpoint(ctx, AddRequest{Logo: logo, Image: image, Text: text, Fill: fill, Pos: pos, LogoOptions: logoOpts, Presets: presets})
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 	addResp := resp.(AddResponse)
 	if addResp.Err != "" {
-		return "", errors.New(addResp.Err)
+		return "", nil, errors.New(addResp.Err)
+	}
+	return addResp.TicketID, addResp.Outputs, nil
+}
+
+func (s *Set) AddBatch(ctx context.Context, logo internal.Blob, images []internal.Blob, text string, fill bool, pos internal.Position, logoOpts internal.LogoOptions) ([]internal.BatchItem, error) {
+	resp, err := s.AddBatchEndpoint(ctx, AddBatchRequest{Logo: logo, Images: images, Text: text, Fill: fill, Pos: pos, LogoOptions: logoOpts})
+	if err != nil {
+		return nil, err
+	}
+	batchResp := resp.(AddBatchResponse)
+	if batchResp.Err != "" {
+		return batchResp.Items, errors.New(batchResp.Err)
+	}
+	return batchResp.Items, nil
+}
+
+func (s *Set) GetStatus(ctx context.Context, ticketID string) (internal.Job, error) {
+	resp, err := s.GetStatusEndpoint(ctx, GetStatusRequest{TicketID: ticketID})
+	if err != nil {
+		return internal.Job{}, err
+	}
+	statusResp := resp.(GetStatusResponse)
+	if statusResp.Err != "" {
+		return statusResp.Job, errors.New(statusResp.Err)
+	}
+	return statusResp.Job, nil
+}
+
+func (s *Set) ListDeadJobs(ctx context.Context) ([]internal.Job, error) {
+	resp, err := s.ListDeadJobsEndpoint(ctx, ListDeadJobsRequest{})
+	if err != nil {
+		return nil, err
+	}
+	listResp := resp.(ListDeadJobsResponse)
+	if listResp.Err != "" {
+		return nil, errors.New(listResp.Err)
+	}
+	return listResp.Jobs, nil
+}
+
+func (s *Set) RequeueJob(ctx context.Context, ticketID string) error {
+	resp, err := s.RequeueJobEndpoint(ctx, RequeueJobRequest{TicketID: ticketID})
+	if err != nil {
+		return err
+	}
+	requeueResp := resp.(RequeueJobResponse)
+	if requeueResp.Err != "" {
+		return errors.New(requeueResp.Err)
+	}
+	return nil
+}
+
+func (s *Set) RegisterWebhook(ctx context.Context, url string, events []string) (internal.Webhook, error) {
+	resp, err := s.RegisterWebhookEndpoint(ctx, RegisterWebhookRequest{URL: url, Events: events})
+	if err != nil {
+		return internal.Webhook{}, err
+	}
+	hookResp := resp.(WebhookResponse)
+	if hookResp.Err != "" {
+		return internal.Webhook{}, errors.New(hookResp.Err)
+	}
+	return hookResp.Webhook, nil
+}
+
+func (s *Set) ListWebhooks(ctx context.Context) ([]internal.Webhook, error) {
+	resp, err := s.ListWebhooksEndpoint(ctx, ListWebhooksRequest{})
+	if err != nil {
+		return nil, err
+	}
+	listResp := resp.(ListWebhooksResponse)
+	if listResp.Err != "" {
+		return nil, errors.New(listResp.Err)
+	}
+	return listResp.Webhooks, nil
+}
+
+func (s *Set) RemoveWebhook(ctx context.Context, webhookID string) error {
+	resp, err := s.RemoveWebhookEndpoint(ctx, WebhookRequest{WebhookID: webhookID})
+	if err != nil {
+		return err
+	}
+	removeResp := resp.(RemoveWebhookResponse)
+	if removeResp.Err != "" {
+		return errors.New(removeResp.Err)
+	}
+	return nil
+}
+
+func (s *Set) ListWebhookDeliveries(ctx context.Context, webhookID string) ([]internal.WebhookDelivery, error) {
+	resp, err := s.ListWebhookDeliveriesEndpoint(ctx, WebhookRequest{WebhookID: webhookID})
+	if err != nil {
+		return nil, err
+	}
+	listResp := resp.(ListWebhookDeliveriesResponse)
+	if listResp.Err != "" {
+		return nil, errors.New(listResp.Err)
+	}
+	return listResp.Deliveries, nil
+}
+
+func (s *Set) PingWebhook(ctx context.Context, webhookID string) (internal.WebhookDelivery, error) {
+	resp, err := s.PingWebhookEndpoint(ctx, WebhookRequest{WebhookID: webhookID})
+	if err != nil {
+		return internal.WebhookDelivery{}, err
+	}
+	pingResp := resp.(PingWebhookResponse)
+	if pingResp.Err != "" {
+		return internal.WebhookDelivery{}, errors.New(pingResp.Err)
+	}
+	return pingResp.Delivery, nil
+}
+
+func (s *Set) StreamToken(ctx context.Context) (internal.StreamToken, error) {
+	resp, err := s.StreamTokenEndpoint(ctx, StreamTokenRequest{})
+	if err != nil {
+		return internal.StreamToken{}, err
+	}
+	tokenResp := resp.(StreamTokenResponse)
+	if tokenResp.Err != "" {
+		return internal.StreamToken{}, errors.New(tokenResp.Err)
+	}
+	return tokenResp.Token, nil
+}
+
+func (s *Set) Watch(ctx context.Context, streamToken string) (<-chan internal.Update, error) {
+	resp, err := s.WatchEndpoint(ctx, WatchRequest{StreamToken: streamToken})
+	if err != nil {
+		return nil, err
+	}
+	watchResp := resp.(WatchResponse)
+	if watchResp.Err != "" {
+		return nil, errors.New(watchResp.Err)
+	}
+	return watchResp.Updates, nil
+}
+
+func (s *Set) Download(ctx context.Context, documentID string, expires int64, signature string) (internal.Download, error) {
+	resp, err := s.DownloadEndpoint(ctx, DownloadRequest{DocumentID: documentID, Expires: expires, Signature: signature})
+	if err != nil {
+		return internal.Download{}, err
+	}
+	downloadResp := resp.(DownloadResponse)
+	if downloadResp.Err != "" {
+		return internal.Download{}, errors.New(downloadResp.Err)
+	}
+	return downloadResp.Download, nil
+}
+
+func (s *Set) CreateShareLink(ctx context.Context, documentID string, expiresAt int64, password string, maxViews int32) (internal.ShareLink, error) {
+	resp, err := s.CreateShareLinkEndpoint(ctx, CreateShareLinkRequest{DocumentID: documentID, ExpiresAt: expiresAt, Password: password, MaxViews: maxViews})
+	if err != nil {
+		return internal.ShareLink{}, err
+	}
+	linkResp := resp.(ShareLinkResponse)
+	if linkResp.Err != "" {
+		return internal.ShareLink{}, errors.New(linkResp.Err)
+	}
+	return linkResp.Link, nil
+}
+
+func (s *Set) ListShareLinks(ctx context.Context, documentID string) ([]internal.ShareLink, error) {
+	resp, err := s.ListShareLinksEndpoint(ctx, ListShareLinksRequest{DocumentID: documentID})
+	if err != nil {
+		return nil, err
+	}
+	listResp := resp.(ListShareLinksResponse)
+	if listResp.Err != "" {
+		return nil, errors.New(listResp.Err)
+	}
+	return listResp.Links, nil
+}
+
+func (s *Set) RevokeShareLink(ctx context.Context, linkID string) error {
+	resp, err := s.RevokeShareLinkEndpoint(ctx, ShareLinkRequest{LinkID: linkID})
+	if err != nil {
+		return err
+	}
+	revokeResp := resp.(RevokeShareLinkResponse)
+	if revokeResp.Err != "" {
+		return errors.New(revokeResp.Err)
+	}
+	return nil
+}
+
+func (s *Set) OpenShareLink(ctx context.Context, linkID string, password string) (internal.Download, error) {
+	resp, err := s.OpenShareLinkEndpoint(ctx, OpenShareLinkRequest{LinkID: linkID, Password: password})
+	if err != nil {
+		return internal.Download{}, err
+	}
+	downloadResp := resp.(DownloadResponse)
+	if downloadResp.Err != "" {
+		return internal.Download{}, errors.New(downloadResp.Err)
 	}
-	return addResp.TicketID, nil
+	return downloadResp.Download, nil
 }
 
 func (s *Set) Remove(ctx context.Context, ticketID string) (int, error) {
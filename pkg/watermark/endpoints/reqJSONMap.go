@@ -1,7 +1,6 @@
 package endpoints
 
 import (
-	"image"
 	"watermark-service/internal"
 )
 
@@ -15,16 +14,159 @@ type GetResponse struct {
 }
 
 type AddRequest struct {
-	Logo  image.Image       `json:"logo"`
-	Image image.Image       `json:"image"`
+	Logo  internal.Blob     `json:"logo"`
+	Image internal.Blob     `json:"image"`
 	Text  string            `json:"text"`
 	Fill  bool              `json:"fill"`
 	Pos   internal.Position `json:"pos"`
+
+	LogoOptions internal.LogoOptions `json:"logo_options"`
+	Presets     []string             `json:"presets,omitempty"`
 }
 
 type AddResponse struct {
+	TicketID string            `json:"ticketID"`
+	Outputs  map[string]string `json:"outputs,omitempty"`
+	Err      string            `json:"err,omitempty"`
+}
+
+type AddBatchRequest struct {
+	Logo   internal.Blob     `json:"logo"`
+	Images []internal.Blob   `json:"images"`
+	Text   string            `json:"text"`
+	Fill   bool              `json:"fill"`
+	Pos    internal.Position `json:"pos"`
+
+	LogoOptions internal.LogoOptions `json:"logo_options"`
+}
+
+type AddBatchResponse struct {
+	Items []internal.BatchItem `json:"items"`
+	Err   string               `json:"err,omitempty"`
+}
+
+type GetStatusRequest struct {
 	TicketID string `json:"ticketID"`
-	Err      string `json:"err,omitempty"`
+}
+
+type GetStatusResponse struct {
+	Job internal.Job `json:"job"`
+	Err string       `json:"err,omitempty"`
+}
+
+type ListDeadJobsRequest struct{}
+
+type ListDeadJobsResponse struct {
+	Jobs []internal.Job `json:"jobs"`
+	Err  string         `json:"err,omitempty"`
+}
+
+type RequeueJobRequest struct {
+	TicketID string `json:"ticketID"`
+}
+
+type RequeueJobResponse struct {
+	Err string `json:"err,omitempty"`
+}
+
+type RegisterWebhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events,omitempty"`
+}
+
+type WebhookResponse struct {
+	Webhook internal.Webhook `json:"webhook"`
+	Err     string           `json:"err,omitempty"`
+}
+
+type ListWebhooksRequest struct{}
+
+type ListWebhooksResponse struct {
+	Webhooks []internal.Webhook `json:"webhooks"`
+	Err      string             `json:"err,omitempty"`
+}
+
+type WebhookRequest struct {
+	WebhookID string `json:"webhookID"`
+}
+
+type RemoveWebhookResponse struct {
+	Err string `json:"err,omitempty"`
+}
+
+type ListWebhookDeliveriesResponse struct {
+	Deliveries []internal.WebhookDelivery `json:"deliveries"`
+	Err        string                     `json:"err,omitempty"`
+}
+
+type PingWebhookResponse struct {
+	Delivery internal.WebhookDelivery `json:"delivery"`
+	Err      string                   `json:"err,omitempty"`
+}
+
+type StreamTokenRequest struct{}
+
+type StreamTokenResponse struct {
+	Token internal.StreamToken `json:"token"`
+	Err   string               `json:"err,omitempty"`
+}
+
+// WatchRequest identifies the user by the stream token when it's set, by the request token otherwise.
+type WatchRequest struct {
+	StreamToken string `json:"stream_token"`
+}
+
+// WatchResponse carries the updates until the context of the request is done.
+type WatchResponse struct {
+	Updates <-chan internal.Update `json:"-"`
+	Err     string                 `json:"err,omitempty"`
+}
+
+type DownloadRequest struct {
+	DocumentID string `json:"documentID"`
+	Expires    int64  `json:"expires"`
+	Signature  string `json:"signature"`
+}
+
+// DownloadResponse carries the content of the document, the transport closes it.
+type DownloadResponse struct {
+	Download internal.Download `json:"-"`
+	Err      string            `json:"err,omitempty"`
+}
+
+type CreateShareLinkRequest struct {
+	DocumentID string `json:"documentID"`
+	ExpiresAt  int64  `json:"expires_at"`
+	Password   string `json:"password"`
+	MaxViews   int32  `json:"max_views"`
+}
+
+type ShareLinkResponse struct {
+	Link internal.ShareLink `json:"link"`
+	Err  string             `json:"err,omitempty"`
+}
+
+type ListShareLinksRequest struct {
+	DocumentID string `json:"documentID"`
+}
+
+type ListShareLinksResponse struct {
+	Links []internal.ShareLink `json:"links"`
+	Err   string               `json:"err,omitempty"`
+}
+
+type ShareLinkRequest struct {
+	LinkID string `json:"linkID"`
+}
+
+type RevokeShareLinkResponse struct {
+	Err string `json:"err,omitempty"`
+}
+
+// OpenShareLinkRequest is answered with a DownloadResponse.
+type OpenShareLinkRequest struct {
+	LinkID   string `json:"linkID"`
+	Password string `json:"password"`
 }
 
 type RemoveRequest struct {
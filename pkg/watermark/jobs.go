@@ -0,0 +1,454 @@
+package watermark
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"time"
+	"watermark-service/internal"
+	"watermark-service/internal/util"
+	"watermark-service/internal/watermark"
+
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Jobs configures the background processing of Add. Workers render the jobs concurrently and at most
+// QueueSize jobs wait for them. A failed job is retried after Backoff doubled on every attempt, after
+// MaxAttempts it is dead-lettered until one of the Admins requeues it. A claimed job is held for Lease,
+// the jobs of a crashed instance are taken over once their lease expires.
+type Jobs struct {
+	Workers     int
+	QueueSize   int
+	MaxAttempts int
+	Lease       time.Duration
+	Backoff     time.Duration
+	Admins      []string
+}
+
+// job is an Add request claimed by a worker, id is the ticket returned to the client.
+type job struct {
+	id       uuid.UUID
+	user     *internal.User
+	logo     internal.Blob
+	image    internal.Blob
+	text     string
+	fill     bool
+	pos      internal.Position
+	logoOpts internal.LogoOptions
+	presets  []string
+}
+
+const (
+	// queueRetryAfter is suggested to the clients when the job queue is full.
+	queueRetryAfter = 5 * time.Second
+	// jobPollInterval bounds the delay before a worker notices a job queued by another instance or a retry.
+	jobPollInterval = time.Second
+	maxBackoff      = 10 * time.Minute
+)
+
+var (
+	errLeaseExpired = errors.New("job lease expired")
+	// errLeaseLost is logged by a worker whose job was claimed again by another one
+	errLeaseLost = errors.New("job claimed by another worker")
+)
+
+func (d *watermarkService) startWorkers() {
+	for i := 0; i < d.jobs.Workers; i++ {
+		go d.work()
+	}
+}
+
+func (d *watermarkService) work() {
+	ticker := time.NewTicker(jobPollInterval)
+	defer ticker.Stop()
+	for {
+		if d.DBAvailable {
+			if row, ok := d.claim(); ok {
+				d.process(row)
+				continue
+			}
+		}
+		select {
+		case <-d.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// enqueue persists the job as Pending with its request, the job is rejected when the queue is full.
+// The images are staged in the storage backends rather than in the database.
+func (d *watermarkService) enqueue(ctx context.Context, j *job) error {
+	if !d.DBAvailable {
+		return util.ErrDatabaseServiceUnavailable
+	}
+	var pending int64
+	res := d.ORMInstance.Model(&watermark.Job{}).Where("status = ?", string(internal.Pending)).Count(&pending)
+	if res.Error != nil {
+		d.log.Error("Job", zap.String("Count", "failed"), zap.Error(res.Error))
+		return res.Error
+	}
+	if pending >= int64(d.jobs.QueueSize) {
+		return &util.OverloadedError{RetryAfter: queueRetryAfter}
+	}
+	trace := map[string]string{}
+	if span := opentracing.SpanFromContext(ctx); span != nil {
+		internal.Tracer.Inject(span.Context(), opentracing.TextMap, opentracing.TextMapCarrier(trace))
+	}
+	imageLocations, err := d.stage(ctx, j.image)
+	if err != nil {
+		return err
+	}
+	logoLocations, err := d.stage(ctx, j.logo)
+	if err != nil {
+		d.unstage(ctx, imageLocations)
+		return err
+	}
+	row := watermark.Job{
+		AuthorId:       j.user.ID,
+		Status:         string(internal.Pending),
+		RunAt:          time.Now(),
+		ImageLocations: imageLocations,
+		ImageFormat:    j.image.Format,
+		LogoLocations:  logoLocations,
+		LogoFormat:     j.logo.Format,
+		Params: watermark.JobParams{
+			Text:        j.text,
+			Fill:        j.fill,
+			Pos:         j.pos,
+			LogoOptions: j.logoOpts,
+			Presets:     j.presets,
+		},
+		Trace: trace,
+	}
+	if err := d.ORMInstance.Create(&row).Error; err != nil {
+		d.log.Error("Job", zap.String("Create", "failed"), zap.Error(err))
+		d.unstage(ctx, imageLocations)
+		d.unstage(ctx, logoLocations)
+		return err
+	}
+	j.id = row.ID
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// claim leases the next runnable job, the rows locked by the other workers are skipped.
+func (d *watermarkService) claim() (*watermark.Job, bool) {
+	var row watermark.Job
+	err := d.ORMInstance.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		res := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("(status = ? AND run_at <= ?) OR (status IN ? AND lease_until < ?)",
+				string(internal.Pending), now, []string{string(internal.Started), string(internal.InProgress)}, now).
+			Order("run_at").
+			Limit(1).
+			Find(&row)
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		lease := now.Add(d.jobs.Lease)
+		row.Attempts++
+		return tx.Model(&row).Updates(map[string]interface{}{
+			"status":      string(internal.Started),
+			"attempts":    row.Attempts,
+			"lease_until": lease,
+		}).Error
+	})
+	if err != nil {
+		d.log.Error("Job", zap.String("Claim", "failed"), zap.Error(err))
+		return nil, false
+	}
+	return &row, row.ID != uuid.Nil
+}
+
+func (d *watermarkService) process(row *watermark.Job) {
+	var opts []opentracing.StartSpanOption
+	if parent, err := internal.Tracer.Extract(opentracing.TextMap, opentracing.TextMapCarrier(row.Trace)); err == nil {
+		opts = append(opts, opentracing.FollowsFrom(parent))
+	}
+	span := internal.Tracer.StartSpan("Add job", opts...)
+	defer span.Finish()
+	ctx := opentracing.ContextWithSpan(context.Background(), span)
+
+	// the attempts of a job whose lease expired are counted as failed
+	if int(row.Attempts) > d.jobs.MaxAttempts {
+		d.retry(row, errLeaseExpired)
+		return
+	}
+	stop := d.keepLease(row)
+	j, err := d.loadJob(ctx, row)
+	if err != nil {
+		stop()
+		d.log.Error("Job", zap.String("ID", row.ID.String()), zap.String("Staged images", "failed"), zap.Error(err))
+		d.retry(row, err)
+		return
+	}
+	url, outputs, err := d.render(ctx, j, func(progress int32) {
+		d.updateJob(row, map[string]interface{}{"status": string(internal.InProgress), "progress": progress})
+		d.notify(row.AuthorId, internal.Update{
+			Event:    internal.EventJobProgress,
+			TicketID: row.ID.String(),
+			Status:   internal.InProgress,
+			Progress: progress,
+		})
+	})
+	stop()
+	if err != nil {
+		d.log.Error("Job", zap.String("ID", row.ID.String()), zap.Int32("Attempt", row.Attempts), zap.Error(err))
+		d.retry(row, err)
+		return
+	}
+	res := d.ORMInstance.Model(row).Where("attempts = ?", row.Attempts).
+		Select("status", "progress", "error", "image_url", "outputs", "lease_until", "image_locations", "logo_locations").
+		Updates(watermark.Job{
+			Status:   string(internal.Finished),
+			Progress: 100,
+			ImageUrl: url,
+			Outputs:  outputs,
+		})
+	switch {
+	case res.Error != nil:
+		d.log.Error("Job", zap.String("ID", row.ID.String()), zap.Error(res.Error))
+	case res.RowsAffected == 0:
+		// the results are stored once per preset, the worker holding the lease now reports them
+		d.log.Warn("Job", zap.String("ID", row.ID.String()), zap.Int32("Attempt", row.Attempts), zap.Error(errLeaseLost))
+		return
+	default:
+		d.unstage(ctx, row.ImageLocations)
+		d.unstage(ctx, row.LogoLocations)
+	}
+	job := internal.Job{ID: row.ID, AuthorId: row.AuthorId, ImageUrl: url, Outputs: outputs}
+	d.signJob(&job)
+	d.emit(row.AuthorId, internal.Update{
+		Event:    internal.EventDocumentFinished,
+		TicketID: row.ID.String(),
+		Status:   internal.Finished,
+		ImageUrl: job.ImageUrl,
+		Outputs:  job.Outputs,
+	})
+}
+
+// retry schedules the failed job with an exponential backoff, the job is dead-lettered after MaxAttempts.
+// The invalid requests fail at once, they can't succeed on a retry.
+func (d *watermarkService) retry(row *watermark.Job, err error) {
+	fields := map[string]interface{}{"error": err.Error(), "lease_until": nil}
+	failed := err == util.ErrInvalidArg || err == util.ErrTooLarge
+	switch {
+	case failed:
+		fields["status"] = string(internal.Failed)
+		fields["image_locations"] = nil
+		fields["logo_locations"] = nil
+	case int(row.Attempts) >= d.jobs.MaxAttempts:
+		fields["status"] = string(internal.DeadLetter)
+		d.log.Warn("Job", zap.String("ID", row.ID.String()), zap.String("Status", "dead-lettered"), zap.Error(err))
+	default:
+		fields["status"] = string(internal.Pending)
+		fields["run_at"] = time.Now().Add(min(d.jobs.Backoff<<min(row.Attempts-1, 16), maxBackoff))
+	}
+	if !d.updateJob(row, fields) {
+		d.log.Warn("Job", zap.String("ID", row.ID.String()), zap.Int32("Attempt", row.Attempts), zap.Error(errLeaseLost))
+		return
+	}
+	if failed {
+		d.unstage(context.Background(), row.ImageLocations)
+		d.unstage(context.Background(), row.LogoLocations)
+	}
+	if status := internal.Status(fields["status"].(string)); status != internal.Pending {
+		d.emit(row.AuthorId, internal.Update{Event: internal.EventDocumentFailed, TicketID: row.ID.String(), Status: status, Err: err.Error()})
+	}
+}
+
+// keepLease extends the lease of the job being rendered until the returned function is called.
+func (d *watermarkService) keepLease(row *watermark.Job) func() {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(d.jobs.Lease / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				d.updateJob(row, map[string]interface{}{"lease_until": time.Now().Add(d.jobs.Lease)})
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// updateJob updates the job claimed as row, it reports false when the job was claimed again
+// since, a worker whose lease expired doesn't overwrite the attempt holding the lease now.
+func (d *watermarkService) updateJob(row *watermark.Job, fields map[string]interface{}) bool {
+	res := d.ORMInstance.Model(&watermark.Job{}).Where("id = ? AND attempts = ?", row.ID, row.Attempts).Updates(fields)
+	if res.Error != nil {
+		d.log.Error("Job", zap.String("ID", row.ID.String()), zap.Error(res.Error))
+		return false
+	}
+	return res.RowsAffected > 0
+}
+
+// stage uploads an image of a job request, the locations of an empty image are nil.
+func (d *watermarkService) stage(ctx context.Context, img internal.Blob) (watermark.Locations, error) {
+	if img.Empty() {
+		return nil, nil
+	}
+	placement, err := d.upload(ctx, "job"+img.Format, bytes.NewReader(img.Data))
+	return placement.Locations, err
+}
+
+// unstage deletes a staged image, the copies which can't be deleted are only logged.
+func (d *watermarkService) unstage(ctx context.Context, locations watermark.Locations) {
+	if len(locations) == 0 {
+		return
+	}
+	if remaining, err := d.storage.Delete(ctx, locations); err != nil {
+		d.log.Warn("Storage", zap.String("Staged image removal", "failed"), zap.Any("Locations", remaining), zap.Error(err))
+	}
+}
+
+// readStaged downloads a staged image, nil locations are an empty image.
+func (d *watermarkService) readStaged(ctx context.Context, locations watermark.Locations) ([]byte, error) {
+	if len(locations) == 0 {
+		return nil, nil
+	}
+	r, err := d.storage.Open(ctx, locations)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(io.LimitReader(r, util.Limits.MaxBytes+1))
+}
+
+// loadJob reads the request of the job with its staged images, a job without an image can't succeed.
+func (d *watermarkService) loadJob(ctx context.Context, row *watermark.Job) (*job, error) {
+	if len(row.ImageLocations) == 0 {
+		return nil, util.ErrInvalidArg
+	}
+	j := jobFromRow(row)
+	var err error
+	if j.image.Data, err = d.readStaged(ctx, row.ImageLocations); err == nil {
+		j.logo.Data, err = d.readStaged(ctx, row.LogoLocations)
+	}
+	// a job whose staged images are gone can't succeed on a retry
+	if err == watermark.ErrNotFound {
+		return nil, util.ErrInvalidArg
+	}
+	if err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// jobFromRow restores the request of the job, the staged images are read by loadJob.
+func jobFromRow(row *watermark.Job) *job {
+	return &job{
+		id:       row.ID,
+		user:     &internal.User{ID: row.AuthorId},
+		logo:     internal.Blob{Format: row.LogoFormat},
+		image:    internal.Blob{Format: row.ImageFormat},
+		text:     row.Params.Text,
+		fill:     row.Params.Fill,
+		pos:      row.Params.Pos,
+		logoOpts: row.Params.LogoOptions,
+		presets:  row.Params.Presets,
+	}
+}
+
+func jobToInternal(row watermark.Job) internal.Job {
+	return internal.Job{
+		ID:       row.ID,
+		Status:   internal.Status(row.Status),
+		Progress: row.Progress,
+		Attempts: row.Attempts,
+		AuthorId: row.AuthorId,
+		Err:      row.Error,
+		ImageUrl: row.ImageUrl,
+		Outputs:  row.Outputs,
+	}
+}
+
+// GetStatus reports the job of the ticket returned by Add, the jobs of other users are unknown.
+func (d *watermarkService) GetStatus(ctx context.Context, ticketID string) (internal.Job, error) {
+	claimedUser, ok := ctx.Value("user").(*internal.User)
+	if !ok {
+		return internal.Job{}, nil
+	}
+	id, err := uuid.Parse(ticketID)
+	if err != nil {
+		return internal.Job{}, util.ErrInvalidArg
+	}
+	var row watermark.Job
+	res := d.ORMInstance.Limit(1).Find(&row, "id = ? AND author_id = ?", id, claimedUser.ID)
+	if res.Error != nil {
+		return internal.Job{}, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return internal.Job{}, util.ErrUnknownArg
+	}
+	job := jobToInternal(row)
+	if job.Status == internal.Finished {
+		d.signJob(&job)
+	}
+	return job, nil
+}
+
+func (d *watermarkService) isAdmin(ctx context.Context) bool {
+	claimedUser, ok := ctx.Value("user").(*internal.User)
+	return ok && slices.Contains(d.jobs.Admins, claimedUser.Email)
+}
+
+// ListDeadJobs reports the dead-lettered jobs of all the users to the admins.
+func (d *watermarkService) ListDeadJobs(ctx context.Context) ([]internal.Job, error) {
+	if !d.isAdmin(ctx) {
+		return nil, util.ErrForbidden
+	}
+	var rows []watermark.Job
+	res := d.ORMInstance.Order("updated_at").Find(&rows, "status = ?", string(internal.DeadLetter))
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	jobs := make([]internal.Job, len(rows))
+	for i, row := range rows {
+		jobs[i] = jobToInternal(row)
+	}
+	return jobs, nil
+}
+
+// RequeueJob gives a dead-lettered job another MaxAttempts attempts.
+func (d *watermarkService) RequeueJob(ctx context.Context, ticketID string) error {
+	if !d.isAdmin(ctx) {
+		return util.ErrForbidden
+	}
+	id, err := uuid.Parse(ticketID)
+	if err != nil {
+		return util.ErrInvalidArg
+	}
+	res := d.ORMInstance.Model(&watermark.Job{}).
+		Where("id = ? AND status = ?", id, string(internal.DeadLetter)).
+		Updates(map[string]interface{}{
+			"status":   string(internal.Pending),
+			"attempts": 0,
+			"progress": 0,
+			"error":    "",
+			"run_at":   time.Now(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return util.ErrUnknownArg
+	}
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
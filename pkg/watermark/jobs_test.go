@@ -0,0 +1,224 @@
+package watermark
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"watermark-service/internal"
+	"watermark-service/internal/util"
+	"watermark-service/internal/watermark"
+
+	"github.com/google/uuid"
+)
+
+func TestJobStaging(t *testing.T) {
+	d := newTestService(t)
+	ctx := context.Background()
+	j := &job{
+		user:  &internal.User{ID: 1},
+		image: internal.Blob{Data: []byte("image"), Format: ".png"},
+		logo:  internal.Blob{Data: []byte("logo"), Format: ".jpg"},
+		text:  "text",
+	}
+	if err := d.enqueue(ctx, j); err != nil {
+		t.Fatal(err)
+	}
+	row, ok := d.claim()
+	if !ok || row.ID != j.id {
+		t.Fatal("job not claimed")
+	}
+	if len(row.ImageLocations) != 1 || len(row.LogoLocations) != 1 {
+		t.Fatalf("images not staged: %v %v", row.ImageLocations, row.LogoLocations)
+	}
+	loaded, err := d.loadJob(ctx, row)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(loaded.image.Data) != "image" || loaded.image.Format != ".png" || string(loaded.logo.Data) != "logo" || loaded.text != "text" {
+		t.Errorf("loaded job = %+v", loaded)
+	}
+
+	d.retry(row, util.ErrInvalidArg)
+	var failed watermark.Job
+	d.ORMInstance.First(&failed, "id = ?", row.ID)
+	if failed.Status != string(internal.Failed) || len(failed.ImageLocations) != 0 || len(failed.LogoLocations) != 0 {
+		t.Errorf("failed job = %s %v %v", failed.Status, failed.ImageLocations, failed.LogoLocations)
+	}
+	if _, err := d.storage.Open(ctx, row.ImageLocations); err == nil {
+		t.Error("staged image of a failed job kept")
+	}
+	if _, err := d.loadJob(ctx, &failed); err != util.ErrInvalidArg {
+		t.Errorf("loadJob() without an image = %v", err)
+	}
+}
+
+func TestStoreOnce(t *testing.T) {
+	d := newTestService(t)
+	ctx := context.Background()
+	j := &job{id: uuid.New(), user: &internal.User{ID: 1}}
+	renders := 0
+	render := func() (internal.Blob, error) {
+		renders++
+		return internal.Blob{Data: []byte("result"), Format: ".png"}, nil
+	}
+	tests := []struct {
+		name    string
+		preset  string
+		renders int
+		same    string
+	}{
+		{"first attempt", "", 1, ""},
+		{"retry", "", 1, "first attempt"},
+		{"preset", "thumbnail", 2, ""},
+		{"preset retry", "thumbnail", 2, "preset"},
+	}
+	docs := map[string]watermark.Document{}
+	for _, tt := range tests {
+		doc, err := d.storeOnce(ctx, j, tt.preset, "title", "name", render)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if renders != tt.renders {
+			t.Errorf("%s: rendered %d times, want %d", tt.name, renders, tt.renders)
+		}
+		if prev, ok := docs[tt.same]; ok && prev.ID != doc.ID {
+			t.Errorf("%s: stored another document", tt.name)
+		}
+		docs[tt.name] = doc
+	}
+
+	// a worker storing the result concurrently wins, the copy of the other one is dropped
+	var winner watermark.Document
+	doc, err := d.storeOnce(ctx, j, "raced", "title", "name", func() (internal.Blob, error) {
+		winner = watermark.Document{AuthorId: 1, Title: "winner", ImageUrl: "http://files.test/winner", JobID: &j.id, Preset: "raced"}
+		if err := d.ORMInstance.Create(&winner).Error; err != nil {
+			t.Fatal(err)
+		}
+		return render()
+	})
+	if err != nil || doc.ID != winner.ID {
+		t.Fatalf("storeOnce() = %v, %v, want the document of the winner", doc.ID, err)
+	}
+	var count int64
+	d.ORMInstance.Model(&watermark.Document{}).Where("job_id = ?", j.id).Count(&count)
+	if count != 3 {
+		t.Errorf("%d documents stored, want 3", count)
+	}
+
+	// the documents of AddBatch have no job and aren't unique
+	for i := 0; i < 2; i++ {
+		if _, err := d.store(ctx, j.user, "title", "name.png", strings.NewReader("result")); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestStaleWorker(t *testing.T) {
+	d := newTestService(t)
+	ctx := context.Background()
+	j := &job{user: &internal.User{ID: 1}, image: internal.Blob{Data: []byte("image"), Format: ".png"}, text: "text"}
+	if err := d.enqueue(ctx, j); err != nil {
+		t.Fatal(err)
+	}
+	stale, ok := d.claim()
+	if !ok {
+		t.Fatal("job not claimed")
+	}
+	d.ORMInstance.Model(&watermark.Job{}).Where("id = ?", j.id).Update("lease_until", time.Now().Add(-time.Second))
+	current, ok := d.claim()
+	if !ok || current.Attempts != stale.Attempts+1 {
+		t.Fatal("job with an expired lease not claimed again")
+	}
+
+	if d.updateJob(stale, map[string]interface{}{"progress": 50}) {
+		t.Error("stale worker updated the job")
+	}
+	d.retry(stale, util.ErrInvalidArg)
+	var row watermark.Job
+	d.ORMInstance.First(&row, "id = ?", j.id)
+	if row.Status != string(internal.Started) || row.Progress != 0 || len(row.ImageLocations) == 0 {
+		t.Errorf("job after a stale retry = %s %d %v", row.Status, row.Progress, row.ImageLocations)
+	}
+	if _, err := d.loadJob(ctx, current); err != nil {
+		t.Errorf("staged image removed by a stale retry: %v", err)
+	}
+
+	d.retry(current, util.ErrInvalidArg)
+	d.ORMInstance.First(&row, "id = ?", j.id)
+	if row.Status != string(internal.Failed) {
+		t.Errorf("job after a retry = %s", row.Status)
+	}
+}
+
+func TestGetStatusAfterMigration(t *testing.T) {
+	d := newTestService(t)
+	ctx := context.Background()
+	j := &job{user: &internal.User{ID: 1}, image: internal.Blob{Data: []byte("image"), Format: ".png"}, text: "text", presets: []string{"story", "twitter"}}
+	if err := d.enqueue(ctx, j); err != nil {
+		t.Fatal(err)
+	}
+	row, ok := d.claim()
+	if !ok {
+		t.Fatal("job not claimed")
+	}
+	outputs := map[string]string{}
+	for _, preset := range j.presets {
+		doc, err := d.storeOnce(ctx, j, preset, preset, preset+".jpg", func() (internal.Blob, error) {
+			return internal.Blob{Data: []byte(preset), Format: ".jpg"}, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		outputs[preset] = doc.ImageUrl
+	}
+	d.ORMInstance.Model(row).Select("status", "image_url", "outputs").
+		Updates(watermark.Job{Status: string(internal.Finished), ImageUrl: outputs["story"], Outputs: outputs})
+
+	// the migration moves the documents to new URLs, the job keeps the old ones
+	var docs []watermark.Document
+	d.ORMInstance.Find(&docs, "job_id = ?", j.id)
+	for _, doc := range docs {
+		r, err := d.storage.Open(ctx, d.locations(doc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		placement, err := d.storage.Upload(ctx, doc.Preset+".jpg", r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		d.ORMInstance.Model(&watermark.Document{}).Where("id = ?", doc.ID).
+			Select("image_url", "locations").
+			Updates(&watermark.Document{ImageUrl: placement.URL, Locations: placement.Locations})
+		d.storage.Delete(ctx, d.locations(doc))
+	}
+
+	status, err := d.GetStatus(userContext(1), j.id.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(status.Outputs) != 2 || status.ImageUrl != status.Outputs["story"] {
+		t.Fatalf("GetStatus() = %+v", status)
+	}
+	for preset, link := range status.Outputs {
+		u, err := url.Parse(link)
+		if err != nil {
+			t.Fatal(err)
+		}
+		id := strings.TrimPrefix(u.Path, DownloadPath)
+		expires, _ := strconv.ParseInt(u.Query().Get("expires"), 10, 64)
+		download, err := d.Download(ctx, id, expires, u.Query().Get("signature"))
+		if err != nil {
+			t.Fatalf("%s: %v", preset, err)
+		}
+		data, _ := io.ReadAll(download.Content)
+		download.Content.Close()
+		if string(data) != preset {
+			t.Errorf("%s: downloaded %q", preset, data)
+		}
+	}
+}
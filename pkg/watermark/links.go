@@ -0,0 +1,183 @@
+package watermark
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"mime"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+	"watermark-service/internal"
+	"watermark-service/internal/util"
+	"watermark-service/internal/watermark"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Links configures the download links of the documents served under DownloadPath of BaseURL.
+// A link is signed with the first of Secrets and expires after TTL, the links signed with any of Secrets
+// are accepted so a new secret is put first and the old one is dropped once its links expired.
+type Links struct {
+	BaseURL string
+	Secrets []string
+	TTL     time.Duration
+}
+
+// DownloadPath is the HTTP path the documents are downloaded from, followed by the document ID.
+const DownloadPath = "/download/"
+
+func (l *Links) init(log *zap.Logger) error {
+	if l.TTL <= 0 {
+		l.TTL = DefaultLinkTTL
+	}
+	l.BaseURL = strings.TrimSuffix(l.BaseURL, "/")
+	if len(l.Secrets) == 0 {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return err
+		}
+		l.Secrets = []string{hex.EncodeToString(secret)}
+		log.Warn("Links", zap.String("Secret", "generated, the links are only valid on this instance until it restarts"))
+	}
+	return nil
+}
+
+func signLink(secret, documentID string, expires int64) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(documentID + "." + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// link is the signed download link of the document.
+func (d *watermarkService) link(documentID uuid.UUID) string {
+	expires := time.Now().Add(d.links.TTL).Unix()
+	query := url.Values{
+		"expires":   {strconv.FormatInt(expires, 10)},
+		"signature": {signLink(d.links.Secrets[0], documentID.String(), expires)},
+	}
+	return d.links.BaseURL + DownloadPath + documentID.String() + "?" + query.Encode()
+}
+
+func (d *watermarkService) validLink(documentID string, expires int64, signature string) bool {
+	if time.Now().Unix() > expires {
+		return false
+	}
+	for _, secret := range d.links.Secrets {
+		if hmac.Equal([]byte(signLink(secret, documentID, expires)), []byte(signature)) {
+			return true
+		}
+	}
+	return false
+}
+
+// documentRef finds the document identified by the ticket, a document ID, its download link or its storage URL.
+func documentRef(ticket string) (string, interface{}) {
+	if _, rest, ok := strings.Cut(ticket, DownloadPath); ok {
+		ticket, _, _ = strings.Cut(rest, "?")
+	}
+	if id, err := uuid.Parse(ticket); err == nil {
+		return "id = ?", id
+	}
+	return "image_url = ?", ticket
+}
+
+// signJob replaces the storage URLs of the job results with the download links of their documents,
+// the URLs which can't be signed are dropped so the storage URLs are never handed out. The documents
+// are found by the job and the preset, as the migrations move them to new URLs, the ones stored
+// before the documents recorded their job by the URLs.
+func (d *watermarkService) signJob(job *internal.Job) {
+	urls := []string{job.ImageUrl}
+	for _, url := range job.Outputs {
+		urls = append(urls, url)
+	}
+	var docs []watermark.Document
+	err := d.ORMInstance.Select("id", "image_url", "job_id", "preset").
+		Find(&docs, "author_id = ? AND (job_id = ? OR image_url IN ?)", job.AuthorId, job.ID, urls).Error
+	if err != nil {
+		d.log.Error("Links", zap.String("ID", job.ID.String()), zap.Error(err))
+	}
+	byPreset := make(map[string]string, len(docs))
+	byURL := make(map[string]string, len(docs))
+	for _, doc := range docs {
+		if doc.JobID != nil && *doc.JobID == job.ID {
+			byPreset[doc.Preset] = d.link(doc.ID)
+		} else {
+			byURL[doc.ImageUrl] = d.link(doc.ID)
+		}
+	}
+	link := func(preset, url string) string {
+		if link, ok := byPreset[preset]; ok {
+			return link
+		}
+		return byURL[url]
+	}
+	// the main result of a job with presets is the document of its first preset
+	main := ""
+	for name, url := range job.Outputs {
+		if url == job.ImageUrl {
+			main = name
+		}
+		if link := link(name, url); link != "" {
+			job.Outputs[name] = link
+		} else {
+			delete(job.Outputs, name)
+		}
+	}
+	job.ImageUrl = link(main, job.ImageUrl)
+}
+
+// Download streams the document to its owner or to anyone with a valid signed link.
+func (d *watermarkService) Download(ctx context.Context, documentID string, expires int64, signature string) (internal.Download, error) {
+	id, err := uuid.Parse(documentID)
+	if err != nil {
+		return internal.Download{}, util.ErrInvalidArg
+	}
+	query := d.ORMInstance.Where("id = ?", id)
+	if signature != "" {
+		if !d.validLink(id.String(), expires, signature) {
+			return internal.Download{}, util.ErrForbidden
+		}
+	} else {
+		claimedUser, ok := ctx.Value("user").(*internal.User)
+		if !ok {
+			return internal.Download{}, util.ErrForbidden
+		}
+		query = query.Where("author_id = ?", claimedUser.ID)
+	}
+	var doc watermark.Document
+	res := query.Limit(1).Find(&doc)
+	if res.Error != nil {
+		return internal.Download{}, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return internal.Download{}, util.ErrUnknownArg
+	}
+	return d.open(ctx, doc)
+}
+
+func (d *watermarkService) open(ctx context.Context, doc watermark.Document) (internal.Download, error) {
+	content, err := d.storage.Open(ctx, d.locations(doc))
+	if err == watermark.ErrNotFound {
+		d.log.Error("Storage", zap.String("Document", doc.ID.String()), zap.Error(err))
+		return internal.Download{}, util.ErrUnknownArg
+	}
+	if err != nil {
+		d.log.Error("Storage", zap.String("Document", doc.ID.String()), zap.Error(err))
+		return internal.Download{}, err
+	}
+	ext := path.Ext(doc.ImageUrl)
+	if u, err := url.Parse(doc.ImageUrl); err == nil {
+		ext = path.Ext(u.Path)
+	}
+	return internal.Download{
+		Name:        doc.Title + ext,
+		ContentType: mime.TypeByExtension(ext),
+		Content:     content,
+	}, nil
+}
@@ -0,0 +1,158 @@
+package watermark
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"watermark-service/internal"
+	"watermark-service/internal/util"
+
+	"github.com/google/uuid"
+)
+
+func TestValidLink(t *testing.T) {
+	d := newTestService(t)
+	d.links.Secrets = []string{"new", "old"}
+	id := uuid.NewString()
+	future := time.Now().Add(time.Hour).Unix()
+	past := time.Now().Add(-time.Second).Unix()
+	tests := []struct {
+		name      string
+		id        string
+		expires   int64
+		signature string
+		want      bool
+	}{
+		{"current secret", id, future, signLink("new", id, future), true},
+		{"rotated secret", id, future, signLink("old", id, future), true},
+		{"unknown secret", id, future, signLink("other", id, future), false},
+		{"expired", id, past, signLink("new", id, past), false},
+		{"extended expiry", id, future + 1, signLink("new", id, future), false},
+		{"other document", uuid.NewString(), future, signLink("new", id, future), false},
+		{"empty signature", id, future, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := d.validLink(tt.id, tt.expires, tt.signature); got != tt.want {
+				t.Errorf("validLink() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLink(t *testing.T) {
+	d := newTestService(t)
+	id := uuid.New()
+	u, err := url.Parse(d.link(id))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Host != "watermark.test" || u.Path != DownloadPath+id.String() {
+		t.Errorf("link = %s", u)
+	}
+	expires, _ := strconv.ParseInt(u.Query().Get("expires"), 10, 64)
+	if !d.validLink(id.String(), expires, u.Query().Get("signature")) {
+		t.Errorf("link %s isn't valid", u)
+	}
+}
+
+func TestDocumentRef(t *testing.T) {
+	id := uuid.New()
+	tests := []struct {
+		ticket string
+		query  string
+		arg    interface{}
+	}{
+		{id.String(), "id = ?", id},
+		{"http://watermark.test" + DownloadPath + id.String() + "?expires=1&signature=abc", "id = ?", id},
+		{"http://files.test/files/ab/cd/file.png", "image_url = ?", "http://files.test/files/ab/cd/file.png"},
+	}
+	for _, tt := range tests {
+		query, arg := documentRef(tt.ticket)
+		if query != tt.query || arg != tt.arg {
+			t.Errorf("documentRef(%q) = %q, %v, want %q, %v", tt.ticket, query, arg, tt.query, tt.arg)
+		}
+	}
+}
+
+func TestSignJob(t *testing.T) {
+	d := newTestService(t)
+	ctx := context.Background()
+	user := &internal.User{ID: 1}
+	doc, err := d.store(ctx, user, "title", "name.png", bytes.NewReader([]byte("result")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := d.store(ctx, &internal.User{ID: 2}, "title", "name.png", bytes.NewReader([]byte("other")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	job := internal.Job{
+		ID:       uuid.New(),
+		AuthorId: user.ID,
+		ImageUrl: doc.ImageUrl,
+		Outputs:  map[string]string{"own": doc.ImageUrl, "other": other.ImageUrl, "unknown": "http://files.test/unknown"},
+	}
+	d.signJob(&job)
+	if !strings.HasPrefix(job.ImageUrl, "http://watermark.test"+DownloadPath+doc.ID.String()+"?") {
+		t.Errorf("ImageUrl = %q", job.ImageUrl)
+	}
+	if len(job.Outputs) != 1 || job.Outputs["own"] == "" || job.Outputs["own"] == doc.ImageUrl {
+		t.Errorf("Outputs = %v", job.Outputs)
+	}
+}
+
+func TestDownload(t *testing.T) {
+	d := newTestService(t)
+	ctx := context.Background()
+	doc, err := d.store(ctx, &internal.User{ID: 1}, "title", "name.png", bytes.NewReader([]byte("result")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	link, _ := url.Parse(d.link(doc.ID))
+	expires, _ := strconv.ParseInt(link.Query().Get("expires"), 10, 64)
+	signature := link.Query().Get("signature")
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		id        string
+		signature string
+		err       error
+	}{
+		{"owner", userContext(1), doc.ID.String(), "", nil},
+		{"signed link", ctx, doc.ID.String(), signature, nil},
+		{"signed link of a stranger", userContext(2), doc.ID.String(), signature, nil},
+		{"stranger", userContext(2), doc.ID.String(), "", util.ErrUnknownArg},
+		{"anonymous", ctx, doc.ID.String(), "", util.ErrForbidden},
+		{"bad signature", ctx, doc.ID.String(), "abc", util.ErrForbidden},
+		{"invalid id", userContext(1), "file.png", "", util.ErrInvalidArg},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			download, err := d.Download(tt.ctx, tt.id, expires, tt.signature)
+			if err != tt.err {
+				t.Fatalf("Download() error = %v, want %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			defer download.Content.Close()
+			data, _ := io.ReadAll(download.Content)
+			if string(data) != "result" || download.Name != "title.png" || download.ContentType != "image/png" {
+				t.Errorf("Download() = %q %q %q", data, download.Name, download.ContentType)
+			}
+		})
+	}
+
+	if _, err := d.storage.Delete(ctx, d.locations(doc)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.Download(userContext(1), doc.ID.String(), 0, ""); err != util.ErrUnknownArg {
+		t.Errorf("Download() of a missing file = %v", err)
+	}
+}
@@ -3,7 +3,6 @@ package watermark
 import (
 	"context"
 	"errors"
-	"image"
 	"net/http"
 	"watermark-service/internal"
 	authService "watermark-service/pkg/authentication"
@@ -58,13 +57,116 @@ func (m *authMiddleware) verifyUser(ctx context.Context) (*internal.User, error)
 	return user, nil
 }
 
-func (m *authMiddleware) Add(ctx context.Context, logo image.Image, image image.Image, text string, fill bool, pos internal.Position) (string, error) {
+func (m *authMiddleware) Add(ctx context.Context, logo internal.Blob, image internal.Blob, text string, fill bool, pos internal.Position, logoOpts internal.LogoOptions, presets []string) (string, map[string]string, error) {
 	user, err := m.verifyUser(ctx)
 	if err != nil {
 		m.log.Error("Incoming Request", zap.String("Add", "Verification"), zap.Error(err))
-		return "", err
+		return "", nil, err
 	}
-	return m.next.Add(context.WithValue(ctx, "user", user), logo, image, text, fill, pos)
+	return m.next.Add(context.WithValue(ctx, "user", user), logo, image, text, fill, pos, logoOpts, presets)
+}
+
+func (m *authMiddleware) AddBatch(ctx context.Context, logo internal.Blob, images []internal.Blob, text string, fill bool, pos internal.Position, logoOpts internal.LogoOptions) ([]internal.BatchItem, error) {
+	user, err := m.verifyUser(ctx)
+	if err != nil {
+		m.log.Error("Incoming Request", zap.String("AddBatch", "Verification"), zap.Error(err))
+		return nil, err
+	}
+	return m.next.AddBatch(context.WithValue(ctx, "user", user), logo, images, text, fill, pos, logoOpts)
+}
+
+func (m *authMiddleware) GetStatus(ctx context.Context, ticketID string) (internal.Job, error) {
+	user, err := m.verifyUser(ctx)
+	if err != nil {
+		m.log.Error("Incoming Request", zap.String("GetStatus", "Verification"), zap.Error(err))
+		return internal.Job{}, err
+	}
+	return m.next.GetStatus(context.WithValue(ctx, "user", user), ticketID)
+}
+
+func (m *authMiddleware) ListDeadJobs(ctx context.Context) ([]internal.Job, error) {
+	user, err := m.verifyUser(ctx)
+	if err != nil {
+		m.log.Error("Incoming Request", zap.String("ListDeadJobs", "Verification"), zap.Error(err))
+		return nil, err
+	}
+	return m.next.ListDeadJobs(context.WithValue(ctx, "user", user))
+}
+
+func (m *authMiddleware) RequeueJob(ctx context.Context, ticketID string) error {
+	user, err := m.verifyUser(ctx)
+	if err != nil {
+		m.log.Error("Incoming Request", zap.String("RequeueJob", "Verification"), zap.Error(err))
+		return err
+	}
+	return m.next.RequeueJob(context.WithValue(ctx, "user", user), ticketID)
+}
+
+func (m *authMiddleware) RegisterWebhook(ctx context.Context, url string, events []string) (internal.Webhook, error) {
+	user, err := m.verifyUser(ctx)
+	if err != nil {
+		m.log.Error("Incoming Request", zap.String("RegisterWebhook", "Verification"), zap.Error(err))
+		return internal.Webhook{}, err
+	}
+	return m.next.RegisterWebhook(context.WithValue(ctx, "user", user), url, events)
+}
+
+func (m *authMiddleware) ListWebhooks(ctx context.Context) ([]internal.Webhook, error) {
+	user, err := m.verifyUser(ctx)
+	if err != nil {
+		m.log.Error("Incoming Request", zap.String("ListWebhooks", "Verification"), zap.Error(err))
+		return nil, err
+	}
+	return m.next.ListWebhooks(context.WithValue(ctx, "user", user))
+}
+
+func (m *authMiddleware) RemoveWebhook(ctx context.Context, webhookID string) error {
+	user, err := m.verifyUser(ctx)
+	if err != nil {
+		m.log.Error("Incoming Request", zap.String("RemoveWebhook", "Verification"), zap.Error(err))
+		return err
+	}
+	return m.next.RemoveWebhook(context.WithValue(ctx, "user", user), webhookID)
+}
+
+func (m *authMiddleware) ListWebhookDeliveries(ctx context.Context, webhookID string) ([]internal.WebhookDelivery, error) {
+	user, err := m.verifyUser(ctx)
+	if err != nil {
+		m.log.Error("Incoming Request", zap.String("ListWebhookDeliveries", "Verification"), zap.Error(err))
+		return nil, err
+	}
+	return m.next.ListWebhookDeliveries(context.WithValue(ctx, "user", user), webhookID)
+}
+
+func (m *authMiddleware) PingWebhook(ctx context.Context, webhookID string) (internal.WebhookDelivery, error) {
+	user, err := m.verifyUser(ctx)
+	if err != nil {
+		m.log.Error("Incoming Request", zap.String("PingWebhook", "Verification"), zap.Error(err))
+		return internal.WebhookDelivery{}, err
+	}
+	return m.next.PingWebhook(context.WithValue(ctx, "user", user), webhookID)
+}
+
+func (m *authMiddleware) StreamToken(ctx context.Context) (internal.StreamToken, error) {
+	user, err := m.verifyUser(ctx)
+	if err != nil {
+		m.log.Error("Incoming Request", zap.String("StreamToken", "Verification"), zap.Error(err))
+		return internal.StreamToken{}, err
+	}
+	return m.next.StreamToken(context.WithValue(ctx, "user", user))
+}
+
+// Watch passes a stream token to the service as is, it identifies the user by itself.
+func (m *authMiddleware) Watch(ctx context.Context, streamToken string) (<-chan internal.Update, error) {
+	if streamToken != "" {
+		return m.next.Watch(ctx, streamToken)
+	}
+	user, err := m.verifyUser(ctx)
+	if err != nil {
+		m.log.Error("Incoming Request", zap.String("Watch", "Verification"), zap.Error(err))
+		return nil, err
+	}
+	return m.next.Watch(context.WithValue(ctx, "user", user), "")
 }
 
 func (m *authMiddleware) Get(ctx context.Context, filters ...internal.Filter) ([]internal.Document, error) {
@@ -76,6 +178,52 @@ func (m *authMiddleware) Get(ctx context.Context, filters ...internal.Filter) ([
 	return m.next.Get(context.WithValue(ctx, "user", user), filters...)
 }
 
+// Download passes the owner to the service when the request carries a token, a signed link needs none.
+func (m *authMiddleware) Download(ctx context.Context, documentID string, expires int64, signature string) (internal.Download, error) {
+	if token, _ := ctx.Value("token").(string); token != "" {
+		user, err := m.verifyUser(ctx)
+		if err == nil {
+			ctx = context.WithValue(ctx, "user", user)
+		} else if signature == "" {
+			m.log.Error("Incoming Request", zap.String("Download", "Verification"), zap.Error(err))
+			return internal.Download{}, err
+		}
+	}
+	return m.next.Download(ctx, documentID, expires, signature)
+}
+
+func (m *authMiddleware) CreateShareLink(ctx context.Context, documentID string, expiresAt int64, password string, maxViews int32) (internal.ShareLink, error) {
+	user, err := m.verifyUser(ctx)
+	if err != nil {
+		m.log.Error("Incoming Request", zap.String("CreateShareLink", "Verification"), zap.Error(err))
+		return internal.ShareLink{}, err
+	}
+	return m.next.CreateShareLink(context.WithValue(ctx, "user", user), documentID, expiresAt, password, maxViews)
+}
+
+func (m *authMiddleware) ListShareLinks(ctx context.Context, documentID string) ([]internal.ShareLink, error) {
+	user, err := m.verifyUser(ctx)
+	if err != nil {
+		m.log.Error("Incoming Request", zap.String("ListShareLinks", "Verification"), zap.Error(err))
+		return nil, err
+	}
+	return m.next.ListShareLinks(context.WithValue(ctx, "user", user), documentID)
+}
+
+func (m *authMiddleware) RevokeShareLink(ctx context.Context, linkID string) error {
+	user, err := m.verifyUser(ctx)
+	if err != nil {
+		m.log.Error("Incoming Request", zap.String("RevokeShareLink", "Verification"), zap.Error(err))
+		return err
+	}
+	return m.next.RevokeShareLink(context.WithValue(ctx, "user", user), linkID)
+}
+
+// OpenShareLink needs no account, the link is the credential.
+func (m *authMiddleware) OpenShareLink(ctx context.Context, linkID string, password string) (internal.Download, error) {
+	return m.next.OpenShareLink(ctx, linkID, password)
+}
+
 func (m *authMiddleware) Remove(ctx context.Context, ticketID string) (int, error) {
 	user, err := m.verifyUser(ctx)
 	if err != nil {
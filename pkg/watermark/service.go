@@ -2,13 +2,28 @@ package watermark
 
 import (
 	"context"
-	"image"
 	"watermark-service/internal"
 )
 
 type Service interface {
-	Add(ctx context.Context, logo image.Image, image image.Image, text string, fill bool, pos internal.Position) (string, error)
+	Add(ctx context.Context, logo internal.Blob, image internal.Blob, text string, fill bool, pos internal.Position, logoOpts internal.LogoOptions, presets []string) (string, map[string]string, error)
+	AddBatch(ctx context.Context, logo internal.Blob, images []internal.Blob, text string, fill bool, pos internal.Position, logoOpts internal.LogoOptions) ([]internal.BatchItem, error)
+	GetStatus(ctx context.Context, ticketID string) (internal.Job, error)
+	ListDeadJobs(ctx context.Context) ([]internal.Job, error)
+	RequeueJob(ctx context.Context, ticketID string) error
+	RegisterWebhook(ctx context.Context, url string, events []string) (internal.Webhook, error)
+	ListWebhooks(ctx context.Context) ([]internal.Webhook, error)
+	RemoveWebhook(ctx context.Context, webhookID string) error
+	ListWebhookDeliveries(ctx context.Context, webhookID string) ([]internal.WebhookDelivery, error)
+	PingWebhook(ctx context.Context, webhookID string) (internal.WebhookDelivery, error)
+	StreamToken(ctx context.Context) (internal.StreamToken, error)
+	Watch(ctx context.Context, streamToken string) (<-chan internal.Update, error)
 	Get(ctx context.Context, filters ...internal.Filter) ([]internal.Document, error)
+	Download(ctx context.Context, documentID string, expires int64, signature string) (internal.Download, error)
+	CreateShareLink(ctx context.Context, documentID string, expiresAt int64, password string, maxViews int32) (internal.ShareLink, error)
+	ListShareLinks(ctx context.Context, documentID string) ([]internal.ShareLink, error)
+	RevokeShareLink(ctx context.Context, linkID string) error
+	OpenShareLink(ctx context.Context, linkID string, password string) (internal.Download, error)
 	Remove(ctx context.Context, ticketID string) (int, error)
 	ServiceStatus(ctx context.Context) (int, error)
 }
@@ -0,0 +1,201 @@
+package watermark
+
+import (
+	"context"
+	"time"
+	"watermark-service/internal"
+	"watermark-service/internal/util"
+	"watermark-service/internal/watermark"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// SharePath is the HTTP path the share links are opened at, followed by the link ID.
+const SharePath = "/share/"
+
+// sharePasswordCost is the bcrypt cost of the share link passwords.
+const sharePasswordCost = 8
+
+// A share link is locked for shareLockout after shareMaxFailures wrong passwords in a row.
+const (
+	shareMaxFailures = 5
+	shareLockout     = 15 * time.Minute
+)
+
+func (d *watermarkService) shareLinkToInternal(link watermark.ShareLink) internal.ShareLink {
+	return internal.ShareLink{
+		ID:         link.ID,
+		DocumentID: link.DocumentID,
+		URL:        d.links.BaseURL + SharePath + link.ID.String(),
+		ExpiresAt:  link.ExpiresAt,
+		MaxViews:   link.MaxViews,
+		Views:      link.Views,
+		Protected:  link.PasswordHash != "",
+		Revoked:    link.RevokedAt != nil,
+		CreatedAt:  link.CreatedAt,
+	}
+}
+
+// CreateShareLink shares the document of the user, expiresAt is a unix time and maxViews is 0 when unlimited.
+func (d *watermarkService) CreateShareLink(ctx context.Context, documentID string, expiresAt int64, password string, maxViews int32) (internal.ShareLink, error) {
+	claimedUser, ok := ctx.Value("user").(*internal.User)
+	if !ok {
+		return internal.ShareLink{}, util.ErrForbidden
+	}
+	if maxViews < 0 || (expiresAt != 0 && expiresAt <= time.Now().Unix()) {
+		return internal.ShareLink{}, util.ErrInvalidArg
+	}
+	doc, err := d.findDocument(claimedUser.ID, documentID)
+	if err != nil {
+		return internal.ShareLink{}, err
+	}
+	link := watermark.ShareLink{DocumentID: doc.ID, AuthorId: claimedUser.ID, MaxViews: maxViews}
+	if expiresAt != 0 {
+		expires := time.Unix(expiresAt, 0)
+		link.ExpiresAt = &expires
+	}
+	if password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), sharePasswordCost)
+		if err != nil {
+			return internal.ShareLink{}, util.ErrInvalidArg
+		}
+		link.PasswordHash = string(hash)
+	}
+	if err := d.ORMInstance.Create(&link).Error; err != nil {
+		return internal.ShareLink{}, err
+	}
+	res := d.shareLinkToInternal(link)
+	d.emit(claimedUser.ID, internal.Update{Event: internal.EventDocumentShared, TicketID: doc.ID.String(), ImageUrl: res.URL})
+	return res, nil
+}
+
+// ListShareLinks reports the share links of the document, or of all the documents of the user when documentID is empty.
+func (d *watermarkService) ListShareLinks(ctx context.Context, documentID string) ([]internal.ShareLink, error) {
+	claimedUser, ok := ctx.Value("user").(*internal.User)
+	if !ok {
+		return nil, util.ErrForbidden
+	}
+	query := d.ORMInstance.Where("author_id = ?", claimedUser.ID)
+	if documentID != "" {
+		doc, err := d.findDocument(claimedUser.ID, documentID)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("document_id = ?", doc.ID)
+	}
+	var rows []watermark.ShareLink
+	if err := query.Order("created_at").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	links := make([]internal.ShareLink, len(rows))
+	for i, row := range rows {
+		links[i] = d.shareLinkToInternal(row)
+	}
+	return links, nil
+}
+
+func (d *watermarkService) RevokeShareLink(ctx context.Context, linkID string) error {
+	claimedUser, ok := ctx.Value("user").(*internal.User)
+	if !ok {
+		return util.ErrForbidden
+	}
+	id, err := uuid.Parse(linkID)
+	if err != nil {
+		return util.ErrInvalidArg
+	}
+	res := d.ORMInstance.Model(&watermark.ShareLink{}).
+		Where("id = ? AND author_id = ? AND revoked_at IS NULL", id, claimedUser.ID).
+		Update("revoked_at", time.Now())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return util.ErrUnknownArg
+	}
+	return nil
+}
+
+// OpenShareLink streams the shared document to anyone with the link and its password, every opening
+// is counted as a view. A wrong password doesn't use up a view but the link is locked after shareMaxFailures of them.
+func (d *watermarkService) OpenShareLink(ctx context.Context, linkID string, password string) (internal.Download, error) {
+	id, err := uuid.Parse(linkID)
+	if err != nil {
+		return internal.Download{}, util.ErrUnknownArg
+	}
+	var link watermark.ShareLink
+	res := d.ORMInstance.Limit(1).Find(&link, "id = ?", id)
+	if res.Error != nil {
+		return internal.Download{}, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return internal.Download{}, util.ErrUnknownArg
+	}
+	now := time.Now()
+	if link.RevokedAt != nil || (link.ExpiresAt != nil && !now.Before(*link.ExpiresAt)) || (link.MaxViews > 0 && link.Views >= link.MaxViews) {
+		return internal.Download{}, util.ErrForbidden
+	}
+	if link.LockedUntil != nil && now.Before(*link.LockedUntil) {
+		return internal.Download{}, util.ErrForbidden
+	}
+	if link.PasswordHash != "" && bcrypt.CompareHashAndPassword([]byte(link.PasswordHash), []byte(password)) != nil {
+		d.failShareLink(link.ID, now)
+		return internal.Download{}, util.ErrForbidden
+	}
+	var doc watermark.Document
+	res = d.ORMInstance.Limit(1).Find(&doc, "id = ?", link.DocumentID)
+	if res.Error != nil {
+		return internal.Download{}, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return internal.Download{}, util.ErrUnknownArg
+	}
+	// the view is taken before opening the document as the concurrent views may exhaust the link meanwhile
+	res = d.ORMInstance.Model(&watermark.ShareLink{}).
+		Where("id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?) AND (max_views = 0 OR views < max_views)", id, now).
+		UpdateColumns(map[string]interface{}{"views": gorm.Expr("views + 1"), "failed_attempts": 0})
+	if res.Error != nil {
+		return internal.Download{}, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return internal.Download{}, util.ErrForbidden
+	}
+	download, err := d.open(ctx, doc)
+	if err != nil {
+		// the view is given back when the document couldn't be read
+		if err := d.ORMInstance.Model(&watermark.ShareLink{}).Where("id = ? AND views > 0", id).
+			UpdateColumn("views", gorm.Expr("views - 1")).Error; err != nil {
+			d.log.Error("Share link", zap.String("ID", link.ID.String()), zap.String("View return", "failed"), zap.Error(err))
+		}
+		return internal.Download{}, err
+	}
+	d.log.Info("Share link", zap.String("ID", link.ID.String()), zap.Int32("Views", link.Views+1))
+	return download, nil
+}
+
+// failShareLink counts a wrong password of the link, the shareMaxFailures one locks the link for shareLockout.
+func (d *watermarkService) failShareLink(id uuid.UUID, now time.Time) {
+	err := d.ORMInstance.Model(&watermark.ShareLink{}).Where("id = ?", id).UpdateColumns(map[string]interface{}{
+		"failed_attempts": gorm.Expr("CASE WHEN failed_attempts + 1 >= ? THEN 0 ELSE failed_attempts + 1 END", shareMaxFailures),
+		"locked_until":    gorm.Expr("CASE WHEN failed_attempts + 1 >= ? THEN ? ELSE locked_until END", shareMaxFailures, now.Add(shareLockout)),
+	}).Error
+	if err != nil {
+		d.log.Error("Share link", zap.String("ID", id.String()), zap.String("Failed attempt", "not counted"), zap.Error(err))
+	}
+}
+
+// findDocument finds the document of the user by its ID, download link or storage URL.
+func (d *watermarkService) findDocument(authorID int32, ticket string) (watermark.Document, error) {
+	var doc watermark.Document
+	column, value := documentRef(ticket)
+	res := d.ORMInstance.Where("author_id = ?", authorID).Limit(1).Find(&doc, column, value)
+	if res.Error != nil {
+		return doc, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return doc, util.ErrUnknownArg
+	}
+	return doc, nil
+}
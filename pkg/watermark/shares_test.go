@@ -0,0 +1,191 @@
+package watermark
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+	"watermark-service/internal"
+	"watermark-service/internal/util"
+	"watermark-service/internal/watermark"
+
+	"github.com/google/uuid"
+)
+
+func TestCreateShareLink(t *testing.T) {
+	d := newTestService(t)
+	doc, err := d.store(context.Background(), &internal.User{ID: 1}, "title", "name.png", bytes.NewReader([]byte("shared")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		document  string
+		expiresAt int64
+		maxViews  int32
+		err       error
+	}{
+		{"unlimited", userContext(1), doc.ID.String(), 0, 0, nil},
+		{"limited", userContext(1), doc.ID.String(), time.Now().Add(time.Hour).Unix(), 3, nil},
+		{"expired", userContext(1), doc.ID.String(), time.Now().Add(-time.Hour).Unix(), 0, util.ErrInvalidArg},
+		{"negative views", userContext(1), doc.ID.String(), 0, -1, util.ErrInvalidArg},
+		{"stranger", userContext(2), doc.ID.String(), 0, 0, util.ErrUnknownArg},
+		{"anonymous", context.Background(), doc.ID.String(), 0, 0, util.ErrForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			link, err := d.CreateShareLink(tt.ctx, tt.document, tt.expiresAt, "", tt.maxViews)
+			if err != tt.err {
+				t.Fatalf("CreateShareLink() error = %v, want %v", err, tt.err)
+			}
+			if err == nil && (link.DocumentID != doc.ID || link.URL != "http://watermark.test"+SharePath+link.ID.String()) {
+				t.Errorf("CreateShareLink() = %+v", link)
+			}
+		})
+	}
+}
+
+func TestOpenShareLink(t *testing.T) {
+	d := newTestService(t)
+	ctx := context.Background()
+	doc, err := d.store(ctx, &internal.User{ID: 1}, "title", "name.png", bytes.NewReader([]byte("shared")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	share := func(password string, maxViews int32) string {
+		link, err := d.CreateShareLink(userContext(1), doc.ID.String(), 0, password, maxViews)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return link.ID.String()
+	}
+	open := func(id, password string) error {
+		download, err := d.OpenShareLink(ctx, id, password)
+		if err != nil {
+			return err
+		}
+		defer download.Content.Close()
+		if data, _ := io.ReadAll(download.Content); string(data) != "shared" {
+			t.Errorf("OpenShareLink() content = %q", data)
+		}
+		return nil
+	}
+	views := func(id string) int32 {
+		var link watermark.ShareLink
+		d.ORMInstance.First(&link, "id = ?", id)
+		return link.Views
+	}
+
+	t.Run("max views", func(t *testing.T) {
+		id := share("", 2)
+		for i, want := range []error{nil, nil, util.ErrForbidden} {
+			if err := open(id, ""); err != want {
+				t.Errorf("view %d error = %v, want %v", i+1, err, want)
+			}
+		}
+		if got := views(id); got != 2 {
+			t.Errorf("views = %d", got)
+		}
+	})
+
+	t.Run("expiry", func(t *testing.T) {
+		id := share("", 0)
+		if err := open(id, ""); err != nil {
+			t.Fatal(err)
+		}
+		d.ORMInstance.Model(&watermark.ShareLink{}).Where("id = ?", id).Update("expires_at", time.Now().Add(-time.Second))
+		if err := open(id, ""); err != util.ErrForbidden {
+			t.Errorf("expired link error = %v", err)
+		}
+	})
+
+	t.Run("revoked", func(t *testing.T) {
+		id := share("", 0)
+		if err := d.RevokeShareLink(userContext(2), id); err != util.ErrUnknownArg {
+			t.Errorf("RevokeShareLink() of a stranger = %v", err)
+		}
+		if err := d.RevokeShareLink(userContext(1), id); err != nil {
+			t.Fatal(err)
+		}
+		if err := open(id, ""); err != util.ErrForbidden {
+			t.Errorf("revoked link error = %v", err)
+		}
+	})
+
+	t.Run("password", func(t *testing.T) {
+		id := share("secret", 1)
+		tests := []struct {
+			password string
+			err      error
+		}{
+			{"", util.ErrForbidden},
+			{"wrong", util.ErrForbidden},
+			{"secret", nil},
+			{"secret", util.ErrForbidden},
+		}
+		for _, tt := range tests {
+			if err := open(id, tt.password); err != tt.err {
+				t.Errorf("OpenShareLink(%q) error = %v, want %v", tt.password, err, tt.err)
+			}
+		}
+		if got := views(id); got != 1 {
+			t.Errorf("wrong passwords used up views: %d", got)
+		}
+	})
+
+	t.Run("lockout", func(t *testing.T) {
+		id := share("secret", 0)
+		for i := 0; i < shareMaxFailures; i++ {
+			open(id, "wrong")
+		}
+		if err := open(id, "secret"); err != util.ErrForbidden {
+			t.Errorf("locked link error = %v", err)
+		}
+		d.ORMInstance.Model(&watermark.ShareLink{}).Where("id = ?", id).Update("locked_until", time.Now().Add(-time.Second))
+		if err := open(id, "secret"); err != nil {
+			t.Errorf("unlocked link error = %v", err)
+		}
+	})
+
+	t.Run("failures reset by opening", func(t *testing.T) {
+		id := share("secret", 0)
+		for i := 0; i < shareMaxFailures-1; i++ {
+			open(id, "wrong")
+		}
+		if err := open(id, "secret"); err != nil {
+			t.Fatal(err)
+		}
+		open(id, "wrong")
+		if err := open(id, "secret"); err != nil {
+			t.Errorf("link locked by failures before an opening: %v", err)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		id := share("", 1)
+		other, err := d.store(ctx, &internal.User{ID: 1}, "title", "name.png", bytes.NewReader([]byte("gone")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		d.ORMInstance.Model(&watermark.ShareLink{}).Where("id = ?", id).Update("document_id", other.ID)
+		if _, err := d.storage.Delete(ctx, d.locations(other)); err != nil {
+			t.Fatal(err)
+		}
+		if err := open(id, ""); err != util.ErrUnknownArg {
+			t.Errorf("OpenShareLink() of a missing file = %v", err)
+		}
+		if got := views(id); got != 0 {
+			t.Errorf("failed opening used up a view: %d", got)
+		}
+	})
+
+	t.Run("unknown link", func(t *testing.T) {
+		for _, id := range []string{uuid.NewString(), "link"} {
+			if err := open(id, ""); err != util.ErrUnknownArg {
+				t.Errorf("OpenShareLink(%q) error = %v", id, err)
+			}
+		}
+	})
+}
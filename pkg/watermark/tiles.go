@@ -0,0 +1,113 @@
+package watermark
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"watermark-service/internal"
+	"watermark-service/internal/util"
+
+	"github.com/opentracing/opentracing-go"
+	"go.uber.org/zap"
+)
+
+// Tiling configures the distributed rendering of huge images, the images above Threshold megapixels
+// are split into horizontal tiles of about TileSize megapixels rendered by the picture service Replicas.
+type Tiling struct {
+	Replicas  []string
+	Threshold float64
+	TileSize  float64
+}
+
+// tileEncoder favours speed, the tiles only travel to the picture service and back.
+var tileEncoder = png.Encoder{CompressionLevel: png.BestSpeed}
+
+func (d *watermarkService) tiled(img internal.Blob) bool {
+	if img.Format == ".svg" {
+		return false
+	}
+	bounds, err := util.CheckImage(img.Data, img.Format)
+	return err == nil && float64(bounds.Dx())*float64(bounds.Dy()) > d.tiling.Threshold*1e6
+}
+
+// renderTiles fans the tiles of the image out to the picture service replicas, a tile failing on
+// one replica is retried on the next one. The watermarked tiles are stitched back band by band
+// while the result is encoded, so the whole image is never held uncompressed twice. The source itself
+// is still decoded in full before it is split.
+func (d *watermarkService) renderTiles(ctx context.Context, img internal.Blob, logo internal.Blob, text string, fill bool, pos internal.Position, logoOpts internal.LogoOptions) (internal.Blob, error) {
+	span := internal.StartSpan("tiled rendering", ctx)
+	defer span.Finish()
+	ctx = opentracing.ContextWithSpan(ctx, span)
+	src, err := util.DecodeImage(bytes.NewReader(img.Data), img.Format)
+	if err != nil {
+		d.log.Error("Image decoding", zap.String("Format", img.Format), zap.Error(err))
+		return internal.Blob{}, util.ErrInvalidArg
+	}
+	sub, ok := src.(interface {
+		SubImage(r image.Rectangle) image.Image
+	})
+	if !ok {
+		return internal.Blob{}, util.ErrInvalidArg
+	}
+	b := src.Bounds()
+	// the tiles are stitched as bands, aligned so the encoder loads each of them once
+	rows := internal.AlignRows(int(d.tiling.TileSize*1e6) / max(1, b.Dx()))
+	n := (b.Dy() + rows - 1) / rows
+	d.log.Info("Tiled rendering", zap.Int("Width", b.Dx()), zap.Int("Height", b.Dy()), zap.Int("Tiles", n), zap.Int("Replicas", len(d.replicas)))
+
+	tiles := make([]internal.Blob, n)
+	errs := make([]error, n)
+	internal.ForEach(n, 2*len(d.replicas), func(i int) {
+		r := image.Rect(b.Min.X, b.Min.Y+i*rows, b.Max.X, min(b.Min.Y+(i+1)*rows, b.Max.Y))
+		var buf bytes.Buffer
+		if errs[i] = tileEncoder.Encode(&buf, sub.SubImage(r)); errs[i] != nil {
+			return
+		}
+		tile := internal.Tile{X: 0, Y: r.Min.Y - b.Min.Y, Width: b.Dx(), Height: b.Dy()}
+		blob := internal.Blob{Data: buf.Bytes(), Format: ".png"}
+		for attempt := 0; attempt < len(d.replicas); attempt++ {
+			client := d.replicas[(i+attempt)%len(d.replicas)]
+			tiles[i], errs[i] = client.CreateTile(ctx, blob, logo, text, fill, pos, logoOpts, tile)
+			if errs[i] == nil || errs[i] == util.ErrInvalidArg || errs[i] == util.ErrTooLarge {
+				return
+			}
+			d.log.Warn("Tiled rendering", zap.Int("Tile", i), zap.Int("Attempt", attempt), zap.Error(errs[i]))
+		}
+	})
+	for i, err := range errs {
+		if err != nil {
+			d.log.Error("Tiled rendering", zap.Int("Tile", i), zap.Error(err))
+			return internal.Blob{}, err
+		}
+	}
+
+	var stitchErr error
+	opaque := false
+	if o, ok := src.(interface{ Opaque() bool }); ok {
+		opaque = o.Opaque()
+	}
+	stitched := internal.StitchBands(image.Rect(0, 0, b.Dx(), b.Dy()), rows, opaque, func(i int) image.Image {
+		tile, err := util.DecodeImage(bytes.NewReader(tiles[i].Data), tiles[i].Format)
+		if err != nil {
+			stitchErr = err
+			return nil
+		}
+		// every band is read once by the encoder
+		tiles[i] = internal.Blob{}
+		return tile
+	})
+	format := ".png"
+	if img.Format == ".jpg" {
+		format = ".jpg"
+	}
+	data, err := util.EncodeImage(stitched, format, 0)
+	if err == nil {
+		err = stitchErr
+	}
+	if err != nil {
+		d.log.Error("Image encoding", zap.String("Status", "failed"), zap.Error(err))
+		return internal.Blob{}, err
+	}
+	return internal.Blob{Data: data, Format: format}, nil
+}
@@ -1,24 +1,45 @@
 package transport
 
 import (
+	"bytes"
 	"context"
+	"io"
+	"watermark-service/api/v1/protos/picture"
 	"watermark-service/api/v1/protos/watermark"
 	"watermark-service/internal"
 	"watermark-service/internal/util"
+	pictureTransport "watermark-service/pkg/picture/transport"
 	"watermark-service/pkg/watermark/endpoints"
 
+	"github.com/go-kit/kit/endpoint"
 	zapkit "github.com/go-kit/kit/log/zap"
 	"github.com/go-kit/kit/tracing/opentracing"
 	grpckit "github.com/go-kit/kit/transport/grpc"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 type grpcServer struct {
-	get           grpckit.Handler
-	add           grpckit.Handler
-	remove        grpckit.Handler
-	serviceStatus grpckit.Handler
+	get                   grpckit.Handler
+	add                   grpckit.Handler
+	addBatch              grpckit.Handler
+	getStatus             grpckit.Handler
+	listDeadJobs          grpckit.Handler
+	requeueJob            grpckit.Handler
+	registerWebhook       grpckit.Handler
+	listWebhooks          grpckit.Handler
+	removeWebhook         grpckit.Handler
+	listWebhookDeliveries grpckit.Handler
+	pingWebhook           grpckit.Handler
+	createShareLink       grpckit.Handler
+	listShareLinks        grpckit.Handler
+	revokeShareLink       grpckit.Handler
+	remove                grpckit.Handler
+	serviceStatus         grpckit.Handler
+	watch                 endpoint.Endpoint
 	watermark.UnimplementedWatermarkServer
 }
 
@@ -41,6 +62,102 @@ func NewGRPCServer(ep endpoints.Set) watermark.WatermarkServer {
 				opentracing.GRPCToContext(internal.Tracer, "Add method", zapkit.NewZapSugarLogger(zap.L(), zapcore.DebugLevel)),
 			),
 		),
+		addBatch: grpckit.NewServer(
+			ep.AddBatchEndpoint,
+			decodeGRPCAddBatchRequest,
+			encodeGRPCAddBatchResponse,
+			grpckit.ServerBefore(
+				opentracing.GRPCToContext(internal.Tracer, "AddBatch method", zapkit.NewZapSugarLogger(zap.L(), zapcore.DebugLevel)),
+			),
+		),
+		getStatus: grpckit.NewServer(
+			ep.GetStatusEndpoint,
+			decodeGRPCGetStatusRequest,
+			encodeGRPCGetStatusResponse,
+			grpckit.ServerBefore(
+				opentracing.GRPCToContext(internal.Tracer, "GetStatus method", zapkit.NewZapSugarLogger(zap.L(), zapcore.DebugLevel)),
+			),
+		),
+		listDeadJobs: grpckit.NewServer(
+			ep.ListDeadJobsEndpoint,
+			decodeGRPCListDeadJobsRequest,
+			encodeGRPCListDeadJobsResponse,
+			grpckit.ServerBefore(
+				opentracing.GRPCToContext(internal.Tracer, "ListDeadJobs method", zapkit.NewZapSugarLogger(zap.L(), zapcore.DebugLevel)),
+			),
+		),
+		requeueJob: grpckit.NewServer(
+			ep.RequeueJobEndpoint,
+			decodeGRPCRequeueJobRequest,
+			encodeGRPCRequeueJobResponse,
+			grpckit.ServerBefore(
+				opentracing.GRPCToContext(internal.Tracer, "RequeueJob method", zapkit.NewZapSugarLogger(zap.L(), zapcore.DebugLevel)),
+			),
+		),
+		registerWebhook: grpckit.NewServer(
+			ep.RegisterWebhookEndpoint,
+			decodeGRPCRegisterWebhookRequest,
+			encodeGRPCRegisterWebhookResponse,
+			grpckit.ServerBefore(
+				opentracing.GRPCToContext(internal.Tracer, "RegisterWebhook method", zapkit.NewZapSugarLogger(zap.L(), zapcore.DebugLevel)),
+			),
+		),
+		listWebhooks: grpckit.NewServer(
+			ep.ListWebhooksEndpoint,
+			decodeGRPCListWebhooksRequest,
+			encodeGRPCListWebhooksResponse,
+			grpckit.ServerBefore(
+				opentracing.GRPCToContext(internal.Tracer, "ListWebhooks method", zapkit.NewZapSugarLogger(zap.L(), zapcore.DebugLevel)),
+			),
+		),
+		removeWebhook: grpckit.NewServer(
+			ep.RemoveWebhookEndpoint,
+			decodeGRPCWebhookRequest,
+			encodeGRPCRemoveWebhookResponse,
+			grpckit.ServerBefore(
+				opentracing.GRPCToContext(internal.Tracer, "RemoveWebhook method", zapkit.NewZapSugarLogger(zap.L(), zapcore.DebugLevel)),
+			),
+		),
+		listWebhookDeliveries: grpckit.NewServer(
+			ep.ListWebhookDeliveriesEndpoint,
+			decodeGRPCWebhookRequest,
+			encodeGRPCListWebhookDeliveriesResponse,
+			grpckit.ServerBefore(
+				opentracing.GRPCToContext(internal.Tracer, "ListWebhookDeliveries method", zapkit.NewZapSugarLogger(zap.L(), zapcore.DebugLevel)),
+			),
+		),
+		pingWebhook: grpckit.NewServer(
+			ep.PingWebhookEndpoint,
+			decodeGRPCWebhookRequest,
+			encodeGRPCPingWebhookResponse,
+			grpckit.ServerBefore(
+				opentracing.GRPCToContext(internal.Tracer, "PingWebhook method", zapkit.NewZapSugarLogger(zap.L(), zapcore.DebugLevel)),
+			),
+		),
+		createShareLink: grpckit.NewServer(
+			ep.CreateShareLinkEndpoint,
+			decodeGRPCCreateShareLinkRequest,
+			encodeGRPCShareLinkResponse,
+			grpckit.ServerBefore(
+				opentracing.GRPCToContext(internal.Tracer, "CreateShareLink method", zapkit.NewZapSugarLogger(zap.L(), zapcore.DebugLevel)),
+			),
+		),
+		listShareLinks: grpckit.NewServer(
+			ep.ListShareLinksEndpoint,
+			decodeGRPCListShareLinksRequest,
+			encodeGRPCListShareLinksResponse,
+			grpckit.ServerBefore(
+				opentracing.GRPCToContext(internal.Tracer, "ListShareLinks method", zapkit.NewZapSugarLogger(zap.L(), zapcore.DebugLevel)),
+			),
+		),
+		revokeShareLink: grpckit.NewServer(
+			ep.RevokeShareLinkEndpoint,
+			decodeGRPCShareLinkRequest,
+			encodeGRPCRevokeShareLinkResponse,
+			grpckit.ServerBefore(
+				opentracing.GRPCToContext(internal.Tracer, "RevokeShareLink method", zapkit.NewZapSugarLogger(zap.L(), zapcore.DebugLevel)),
+			),
+		),
 		remove: grpckit.NewServer(
 			ep.RemoveEndpoint,
 			decodeGRPCRemoveRequest,
@@ -49,6 +166,7 @@ func NewGRPCServer(ep endpoints.Set) watermark.WatermarkServer {
 				opentracing.GRPCToContext(internal.Tracer, "Remove method", zapkit.NewZapSugarLogger(zap.L(), zapcore.DebugLevel)),
 			),
 		),
+		watch: ep.WatchEndpoint,
 		serviceStatus: grpckit.NewServer(
 			ep.ServiceStatusEndpoint,
 			decodeGRPCServiceStatusRequest,
@@ -76,6 +194,139 @@ func (g *grpcServer) Add(ctx context.Context, r *watermark.AddRequest) (*waterma
 	return resp.(*watermark.AddResponse), nil
 }
 
+func (g *grpcServer) AddBatch(ctx context.Context, r *watermark.AddBatchRequest) (*watermark.AddBatchResponse, error) {
+	_, resp, err := g.addBatch.ServeGRPC(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*watermark.AddBatchResponse), nil
+}
+
+func (g *grpcServer) GetStatus(ctx context.Context, r *watermark.GetStatusRequest) (*watermark.GetStatusResponse, error) {
+	_, resp, err := g.getStatus.ServeGRPC(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*watermark.GetStatusResponse), nil
+}
+
+func (g *grpcServer) ListDeadJobs(ctx context.Context, r *watermark.ListDeadJobsRequest) (*watermark.ListDeadJobsResponse, error) {
+	_, resp, err := g.listDeadJobs.ServeGRPC(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*watermark.ListDeadJobsResponse), nil
+}
+
+func (g *grpcServer) RequeueJob(ctx context.Context, r *watermark.RequeueJobRequest) (*watermark.RequeueJobResponse, error) {
+	_, resp, err := g.requeueJob.ServeGRPC(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*watermark.RequeueJobResponse), nil
+}
+
+func (g *grpcServer) RegisterWebhook(ctx context.Context, r *watermark.RegisterWebhookRequest) (*watermark.WebhookResponse, error) {
+	_, resp, err := g.registerWebhook.ServeGRPC(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*watermark.WebhookResponse), nil
+}
+
+func (g *grpcServer) ListWebhooks(ctx context.Context, r *watermark.ListWebhooksRequest) (*watermark.ListWebhooksResponse, error) {
+	_, resp, err := g.listWebhooks.ServeGRPC(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*watermark.ListWebhooksResponse), nil
+}
+
+func (g *grpcServer) RemoveWebhook(ctx context.Context, r *watermark.WebhookRequest) (*watermark.RemoveWebhookResponse, error) {
+	_, resp, err := g.removeWebhook.ServeGRPC(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*watermark.RemoveWebhookResponse), nil
+}
+
+func (g *grpcServer) ListWebhookDeliveries(ctx context.Context, r *watermark.WebhookRequest) (*watermark.ListWebhookDeliveriesResponse, error) {
+	_, resp, err := g.listWebhookDeliveries.ServeGRPC(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*watermark.ListWebhookDeliveriesResponse), nil
+}
+
+func (g *grpcServer) PingWebhook(ctx context.Context, r *watermark.WebhookRequest) (*watermark.PingWebhookResponse, error) {
+	_, resp, err := g.pingWebhook.ServeGRPC(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*watermark.PingWebhookResponse), nil
+}
+
+// Watch sends the updates until the client goes away, the token is taken from the "token" metadata.
+func (g *grpcServer) CreateShareLink(ctx context.Context, r *watermark.CreateShareLinkRequest) (*watermark.ShareLinkResponse, error) {
+	_, resp, err := g.createShareLink.ServeGRPC(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*watermark.ShareLinkResponse), nil
+}
+
+func (g *grpcServer) ListShareLinks(ctx context.Context, r *watermark.ListShareLinksRequest) (*watermark.ListShareLinksResponse, error) {
+	_, resp, err := g.listShareLinks.ServeGRPC(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*watermark.ListShareLinksResponse), nil
+}
+
+func (g *grpcServer) RevokeShareLink(ctx context.Context, r *watermark.ShareLinkRequest) (*watermark.RevokeShareLinkResponse, error) {
+	_, resp, err := g.revokeShareLink.ServeGRPC(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*watermark.RevokeShareLinkResponse), nil
+}
+
+func (g *grpcServer) Watch(_ *watermark.WatchRequest, stream watermark.Watermark_WatchServer) error {
+	ctx := stream.Context()
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = opentracing.GRPCToContext(internal.Tracer, "Watch method", zapkit.NewZapSugarLogger(zap.L(), zapcore.DebugLevel))(ctx, md)
+	if token := md.Get("token"); len(token) > 0 {
+		ctx = context.WithValue(ctx, "token", token[0])
+	}
+	resp, err := g.watch(ctx, endpoints.WatchRequest{})
+	if err != nil {
+		return err
+	}
+	watchResp := resp.(endpoints.WatchResponse)
+	if watchResp.Err != "" {
+		return status.Error(codes.Unauthenticated, watchResp.Err)
+	}
+	for update := range watchResp.Updates {
+		if err := stream.Send(updateToProto(update)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddStream runs the unary Add handler on the request assembled from the stream.
+func (g *grpcServer) AddStream(stream watermark.Watermark_AddStreamServer) error {
+	req, err := receiveAddRequest(stream)
+	if err != nil {
+		return err
+	}
+	_, resp, err := g.add.ServeGRPC(stream.Context(), req)
+	if err != nil {
+		return err
+	}
+	return stream.SendAndClose(resp.(*watermark.AddResponse))
+}
+
 func (g *grpcServer) Remove(ctx context.Context, r *watermark.RemoveRequest) (*watermark.RemoveResponse, error) {
 	_, resp, err := g.remove.ServeGRPC(ctx, r)
 	if err != nil {
@@ -108,15 +359,98 @@ func decodeGRPCRemoveRequest(_ context.Context, grpcReq interface{}) (interface{
 
 func decodeGRPCAddRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
 	req := grpcReq.(*watermark.AddRequest)
+	logoOpts, err := pictureTransport.LogoOptionsFromProto(req.GetLogoOptions())
+	if err != nil {
+		return nil, err
+	}
+	logo, err := checkImage(req.GetLogo())
+	if err != nil {
+		return nil, err
+	}
+	img, err := checkImage(req.GetImage())
+	if err != nil {
+		return nil, err
+	}
 	return endpoints.AddRequest{
-		Logo:  util.ByteToImage(req.Logo.Data, req.Logo.Type),
-		Image: util.ByteToImage(req.Image.Data, req.Image.Type),
-		Text:  req.Text,
-		Fill:  req.Fill,
-		Pos:   internal.PositionFromString(req.Pos.String()),
+		Logo:        logo,
+		Image:       img,
+		Text:        req.Text,
+		Fill:        req.Fill,
+		Pos:         internal.PositionFromString(req.Pos.String()),
+		LogoOptions: logoOpts,
+		Presets:     req.GetPresets(),
+	}, nil
+}
+
+// decodeGRPCAddBatchRequest leaves the images which can't be decoded empty, they fail on their own
+// without failing the batch.
+func decodeGRPCAddBatchRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*watermark.AddBatchRequest)
+	logoOpts, err := pictureTransport.LogoOptionsFromProto(req.GetLogoOptions())
+	if err != nil {
+		return nil, err
+	}
+	logo, err := checkImage(req.GetLogo())
+	if err != nil {
+		return nil, err
+	}
+	images := make([]internal.Blob, len(req.GetImages()))
+	for i, img := range req.GetImages() {
+		images[i], _ = checkImage(img)
+	}
+	return endpoints.AddBatchRequest{
+		Logo:        logo,
+		Images:      images,
+		Text:        req.Text,
+		Fill:        req.Fill,
+		Pos:         internal.PositionFromString(req.Pos.String()),
+		LogoOptions: logoOpts,
 	}, nil
 }
 
+func decodeGRPCGetStatusRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*watermark.GetStatusRequest)
+	return endpoints.GetStatusRequest{TicketID: req.TicketID}, nil
+}
+
+func decodeGRPCListDeadJobsRequest(_ context.Context, _ interface{}) (interface{}, error) {
+	return endpoints.ListDeadJobsRequest{}, nil
+}
+
+func decodeGRPCRequeueJobRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*watermark.RequeueJobRequest)
+	return endpoints.RequeueJobRequest{TicketID: req.TicketID}, nil
+}
+
+func decodeGRPCRegisterWebhookRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*watermark.RegisterWebhookRequest)
+	return endpoints.RegisterWebhookRequest{URL: req.Url, Events: req.Events}, nil
+}
+
+func decodeGRPCListWebhooksRequest(_ context.Context, _ interface{}) (interface{}, error) {
+	return endpoints.ListWebhooksRequest{}, nil
+}
+
+func decodeGRPCWebhookRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*watermark.WebhookRequest)
+	return endpoints.WebhookRequest{WebhookID: req.WebhookID}, nil
+}
+
+func decodeGRPCCreateShareLinkRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*watermark.CreateShareLinkRequest)
+	return endpoints.CreateShareLinkRequest{DocumentID: req.DocumentID, ExpiresAt: req.ExpiresAt, Password: req.Password, MaxViews: req.MaxViews}, nil
+}
+
+func decodeGRPCListShareLinksRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*watermark.ListShareLinksRequest)
+	return endpoints.ListShareLinksRequest{DocumentID: req.DocumentID}, nil
+}
+
+func decodeGRPCShareLinkRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*watermark.ShareLinkRequest)
+	return endpoints.ShareLinkRequest{LinkID: req.LinkID}, nil
+}
+
 func decodeGRPCServiceStatusRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
 	return endpoints.ServiceStatusRequest{}, nil
 }
@@ -150,7 +484,222 @@ func encodeGRPCServiceStatusResponse(_ context.Context, grpcResponse interface{}
 	return &watermark.ServiceStatusResponse{Code: int64(response.Code), Err: response.Err}, nil
 }
 
+func encodeGRPCAddBatchResponse(_ context.Context, grpcResponse interface{}) (interface{}, error) {
+	response := grpcResponse.(endpoints.AddBatchResponse)
+	items := make([]*watermark.AddResponse, len(response.Items))
+	for i, item := range response.Items {
+		items[i] = &watermark.AddResponse{TicketID: item.TicketID, Err: item.Err}
+	}
+	return &watermark.AddBatchResponse{Items: items, Err: response.Err}, nil
+}
+
+func encodeGRPCGetStatusResponse(_ context.Context, grpcResponse interface{}) (interface{}, error) {
+	response := grpcResponse.(endpoints.GetStatusResponse)
+	if response.Err != "" {
+		return &watermark.GetStatusResponse{Err: response.Err}, nil
+	}
+	return &watermark.GetStatusResponse{Job: jobToProto(response.Job)}, nil
+}
+
+func encodeGRPCListDeadJobsResponse(_ context.Context, grpcResponse interface{}) (interface{}, error) {
+	response := grpcResponse.(endpoints.ListDeadJobsResponse)
+	jobs := make([]*watermark.Job, len(response.Jobs))
+	for i, job := range response.Jobs {
+		jobs[i] = jobToProto(job)
+	}
+	return &watermark.ListDeadJobsResponse{Jobs: jobs, Err: response.Err}, nil
+}
+
+func encodeGRPCRequeueJobResponse(_ context.Context, grpcResponse interface{}) (interface{}, error) {
+	response := grpcResponse.(endpoints.RequeueJobResponse)
+	return &watermark.RequeueJobResponse{Err: response.Err}, nil
+}
+
+func encodeGRPCRegisterWebhookResponse(_ context.Context, grpcResponse interface{}) (interface{}, error) {
+	response := grpcResponse.(endpoints.WebhookResponse)
+	if response.Err != "" {
+		return &watermark.WebhookResponse{Err: response.Err}, nil
+	}
+	return &watermark.WebhookResponse{Webhook: webhookToProto(response.Webhook)}, nil
+}
+
+func encodeGRPCListWebhooksResponse(_ context.Context, grpcResponse interface{}) (interface{}, error) {
+	response := grpcResponse.(endpoints.ListWebhooksResponse)
+	hooks := make([]*watermark.Webhook, len(response.Webhooks))
+	for i, hook := range response.Webhooks {
+		hooks[i] = webhookToProto(hook)
+	}
+	return &watermark.ListWebhooksResponse{Webhooks: hooks, Err: response.Err}, nil
+}
+
+func encodeGRPCRemoveWebhookResponse(_ context.Context, grpcResponse interface{}) (interface{}, error) {
+	response := grpcResponse.(endpoints.RemoveWebhookResponse)
+	return &watermark.RemoveWebhookResponse{Err: response.Err}, nil
+}
+
+func encodeGRPCListWebhookDeliveriesResponse(_ context.Context, grpcResponse interface{}) (interface{}, error) {
+	response := grpcResponse.(endpoints.ListWebhookDeliveriesResponse)
+	deliveries := make([]*watermark.WebhookDelivery, len(response.Deliveries))
+	for i, delivery := range response.Deliveries {
+		deliveries[i] = deliveryToProto(delivery)
+	}
+	return &watermark.ListWebhookDeliveriesResponse{Deliveries: deliveries, Err: response.Err}, nil
+}
+
+func encodeGRPCPingWebhookResponse(_ context.Context, grpcResponse interface{}) (interface{}, error) {
+	response := grpcResponse.(endpoints.PingWebhookResponse)
+	if response.Err != "" {
+		return &watermark.PingWebhookResponse{Err: response.Err}, nil
+	}
+	return &watermark.PingWebhookResponse{Delivery: deliveryToProto(response.Delivery)}, nil
+}
+
+func encodeGRPCShareLinkResponse(_ context.Context, grpcResponse interface{}) (interface{}, error) {
+	response := grpcResponse.(endpoints.ShareLinkResponse)
+	if response.Err != "" {
+		return &watermark.ShareLinkResponse{Err: response.Err}, nil
+	}
+	return &watermark.ShareLinkResponse{Link: shareLinkToProto(response.Link)}, nil
+}
+
+func encodeGRPCListShareLinksResponse(_ context.Context, grpcResponse interface{}) (interface{}, error) {
+	response := grpcResponse.(endpoints.ListShareLinksResponse)
+	links := make([]*watermark.ShareLink, len(response.Links))
+	for i, link := range response.Links {
+		links[i] = shareLinkToProto(link)
+	}
+	return &watermark.ListShareLinksResponse{Links: links, Err: response.Err}, nil
+}
+
+func encodeGRPCRevokeShareLinkResponse(_ context.Context, grpcResponse interface{}) (interface{}, error) {
+	response := grpcResponse.(endpoints.RevokeShareLinkResponse)
+	return &watermark.RevokeShareLinkResponse{Err: response.Err}, nil
+}
+
+func shareLinkToProto(link internal.ShareLink) *watermark.ShareLink {
+	var expiresAt int64
+	if link.ExpiresAt != nil {
+		expiresAt = link.ExpiresAt.Unix()
+	}
+	return &watermark.ShareLink{
+		Id:         link.ID.String(),
+		DocumentId: link.DocumentID.String(),
+		Url:        link.URL,
+		ExpiresAt:  expiresAt,
+		MaxViews:   link.MaxViews,
+		Views:      link.Views,
+		Protected:  link.Protected,
+		Revoked:    link.Revoked,
+		CreatedAt:  link.CreatedAt.Unix(),
+	}
+}
+
+func webhookToProto(hook internal.Webhook) *watermark.Webhook {
+	return &watermark.Webhook{
+		Id:        hook.ID.String(),
+		Url:       hook.URL,
+		Events:    hook.Events,
+		Secret:    hook.Secret,
+		CreatedAt: hook.CreatedAt.Unix(),
+	}
+}
+
+func deliveryToProto(delivery internal.WebhookDelivery) *watermark.WebhookDelivery {
+	return &watermark.WebhookDelivery{
+		Id:           delivery.ID.String(),
+		WebhookId:    delivery.WebhookID.String(),
+		Event:        delivery.Event,
+		Status:       string(delivery.Status),
+		Attempts:     delivery.Attempts,
+		ResponseCode: delivery.ResponseCode,
+		Error:        delivery.Err,
+		CreatedAt:    delivery.CreatedAt.Unix(),
+		UpdatedAt:    delivery.UpdatedAt.Unix(),
+	}
+}
+
+func updateToProto(update internal.Update) *watermark.Update {
+	return &watermark.Update{
+		Event:    update.Event,
+		TicketID: update.TicketID,
+		Status:   string(update.Status),
+		Progress: update.Progress,
+		ImageUrl: update.ImageUrl,
+		Outputs:  update.Outputs,
+		Error:    update.Err,
+	}
+}
+
+func jobToProto(job internal.Job) *watermark.Job {
+	return &watermark.Job{
+		Id:       job.ID.String(),
+		Status:   string(job.Status),
+		Progress: job.Progress,
+		Error:    job.Err,
+		ImageUrl: job.ImageUrl,
+		Outputs:  job.Outputs,
+		Attempts: job.Attempts,
+		AuthorId: job.AuthorId,
+	}
+}
+
 func encodeGRPCAddResponse(_ context.Context, grpcResponse interface{}) (interface{}, error) {
 	response := grpcResponse.(endpoints.AddResponse)
-	return &watermark.AddResponse{TicketID: response.TicketID, Err: response.Err}, nil
+	return &watermark.AddResponse{TicketID: response.TicketID, Outputs: response.Outputs, Err: response.Err}, nil
+}
+
+// checkImage rejects images exceeding the limits with InvalidArgument, the images which can't be
+// decoded result in an empty blob as before. The image is passed on encoded.
+func checkImage(img *picture.Image) (internal.Blob, error) {
+	if img == nil {
+		return internal.Blob{}, nil
+	}
+	_, err := util.CheckImage(img.Data, img.Type)
+	if err == util.ErrTooLarge {
+		return internal.Blob{}, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		return internal.Blob{}, nil
+	}
+	return internal.Blob{Data: img.Data, Format: img.Type}, nil
+}
+
+// receiveAddRequest assembles the unary request from the header and the chunks following it.
+func receiveAddRequest(stream watermark.Watermark_AddStreamServer) (*watermark.AddRequest, error) {
+	msg, err := stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	req := msg.GetHeader()
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "stream must start with the header")
+	}
+	var img, logo bytes.Buffer
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch part := msg.Part.(type) {
+		case *watermark.AddStreamRequest_Image:
+			err = pictureTransport.AppendChunk(&img, part.Image.GetData())
+		case *watermark.AddStreamRequest_Logo:
+			err = pictureTransport.AppendChunk(&logo, part.Logo.GetData())
+		default:
+			err = status.Error(codes.InvalidArgument, "unexpected header")
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if req.Image != nil {
+		req.Image.Data = img.Bytes()
+	}
+	if req.Logo != nil {
+		req.Logo.Data = logo.Bytes()
+	}
+	return req, nil
 }
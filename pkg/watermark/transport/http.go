@@ -3,13 +3,19 @@ package transport
 import (
 	"context"
 	"encoding/json"
-	"image"
-	"image/jpeg"
-	"image/png"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
 	"net/http"
 	"regexp"
+	"strconv"
+	"strings"
+	"time"
 	"watermark-service/internal"
 	"watermark-service/internal/util"
+	pictureTransport "watermark-service/pkg/picture/transport"
+	"watermark-service/pkg/watermark"
 	"watermark-service/pkg/watermark/endpoints"
 
 	zapkit "github.com/go-kit/kit/log/zap"
@@ -19,6 +25,9 @@ import (
 	"go.uber.org/zap/zapcore"
 )
 
+// maxBatchBody limits the whole multipart body of a batch upload, every image is checked against util.Limits too.
+const maxBatchBody = watermark.MaxMessageSize
+
 func NewHttpHandler(ep endpoints.Set) http.Handler {
 	m := http.NewServeMux()
 
@@ -31,12 +40,107 @@ func NewHttpHandler(ep endpoints.Set) http.Handler {
 		ep.AddEndpoint,
 		decodeHTTPAddRequest,
 		encodeResponse,
+		httpkit.ServerErrorEncoder(encodeError),
 		httpkit.ServerBefore(
 			injectContext,
 			extractImages,
 			opentracing.HTTPToContext(internal.Tracer, "Add method", zapkit.NewZapSugarLogger(zap.L(), zapcore.DebugLevel)),
 		),
 	))
+	m.Handle("/add_batch", httpkit.NewServer(
+		ep.AddBatchEndpoint,
+		decodeHTTPAddBatchRequest,
+		encodeResponse,
+		httpkit.ServerErrorEncoder(encodeError),
+		httpkit.ServerBefore(
+			injectContext,
+			extractBatchImages,
+			opentracing.HTTPToContext(internal.Tracer, "AddBatch method", zapkit.NewZapSugarLogger(zap.L(), zapcore.DebugLevel)),
+		),
+	))
+	m.Handle("/status/", httpkit.NewServer(
+		ep.GetStatusEndpoint,
+		decodeHTTPGetStatusRequest,
+		encodeGetStatusResponse,
+		httpkit.ServerBefore(
+			injectContext,
+			opentracing.HTTPToContext(internal.Tracer, "GetStatus method", zapkit.NewZapSugarLogger(zap.L(), zapcore.DebugLevel)),
+		),
+	))
+	m.Handle("/admin/jobs/dead", httpkit.NewServer(
+		ep.ListDeadJobsEndpoint,
+		decodeHTTPListDeadJobsRequest,
+		encodeResponse,
+		httpkit.ServerBefore(
+			injectContext,
+			opentracing.HTTPToContext(internal.Tracer, "ListDeadJobs method", zapkit.NewZapSugarLogger(zap.L(), zapcore.DebugLevel)),
+		),
+	))
+	m.Handle("/admin/jobs/requeue", httpkit.NewServer(
+		ep.RequeueJobEndpoint,
+		decodeHTTPRequeueJobRequest,
+		encodeResponse,
+		httpkit.ServerBefore(
+			injectContext,
+			opentracing.HTTPToContext(internal.Tracer, "RequeueJob method", zapkit.NewZapSugarLogger(zap.L(), zapcore.DebugLevel)),
+		),
+	))
+	m.Handle("/webhooks/register", httpkit.NewServer(
+		ep.RegisterWebhookEndpoint,
+		decodeHTTPRegisterWebhookRequest,
+		encodeResponse,
+		httpkit.ServerBefore(
+			injectContext,
+			opentracing.HTTPToContext(internal.Tracer, "RegisterWebhook method", zapkit.NewZapSugarLogger(zap.L(), zapcore.DebugLevel)),
+		),
+	))
+	m.Handle("/webhooks/list", httpkit.NewServer(
+		ep.ListWebhooksEndpoint,
+		decodeHTTPListWebhooksRequest,
+		encodeResponse,
+		httpkit.ServerBefore(
+			injectContext,
+			opentracing.HTTPToContext(internal.Tracer, "ListWebhooks method", zapkit.NewZapSugarLogger(zap.L(), zapcore.DebugLevel)),
+		),
+	))
+	m.Handle("/webhooks/remove", httpkit.NewServer(
+		ep.RemoveWebhookEndpoint,
+		decodeHTTPWebhookRequest,
+		encodeResponse,
+		httpkit.ServerBefore(
+			injectContext,
+			opentracing.HTTPToContext(internal.Tracer, "RemoveWebhook method", zapkit.NewZapSugarLogger(zap.L(), zapcore.DebugLevel)),
+		),
+	))
+	m.Handle("/webhooks/deliveries", httpkit.NewServer(
+		ep.ListWebhookDeliveriesEndpoint,
+		decodeHTTPWebhookRequest,
+		encodeResponse,
+		httpkit.ServerBefore(
+			injectContext,
+			opentracing.HTTPToContext(internal.Tracer, "ListWebhookDeliveries method", zapkit.NewZapSugarLogger(zap.L(), zapcore.DebugLevel)),
+		),
+	))
+	m.Handle("/webhooks/ping", httpkit.NewServer(
+		ep.PingWebhookEndpoint,
+		decodeHTTPWebhookRequest,
+		encodeResponse,
+		httpkit.ServerBefore(
+			injectContext,
+			opentracing.HTTPToContext(internal.Tracer, "PingWebhook method", zapkit.NewZapSugarLogger(zap.L(), zapcore.DebugLevel)),
+		),
+	))
+	m.Handle("/events/token", httpkit.NewServer(
+		ep.StreamTokenEndpoint,
+		decodeHTTPStreamTokenRequest,
+		encodeResponse,
+		httpkit.ServerErrorEncoder(encodeError),
+		httpkit.ServerBefore(
+			injectContext,
+			opentracing.HTTPToContext(internal.Tracer, "StreamToken method", zapkit.NewZapSugarLogger(zap.L(), zapcore.DebugLevel)),
+		),
+	))
+	m.Handle("/events", newEventsHandler(ep))
 	m.Handle("/get", httpkit.NewServer(
 		ep.GetEndpoint,
 		decodeHTTPGetRequest,
@@ -46,6 +150,52 @@ func NewHttpHandler(ep endpoints.Set) http.Handler {
 			opentracing.HTTPToContext(internal.Tracer, "Get method", zapkit.NewZapSugarLogger(zap.L(), zapcore.DebugLevel)),
 		),
 	))
+	m.Handle(watermark.DownloadPath, httpkit.NewServer(
+		ep.DownloadEndpoint,
+		decodeHTTPDownloadRequest,
+		encodeDownloadResponse,
+		httpkit.ServerErrorEncoder(encodeError),
+		httpkit.ServerBefore(
+			injectContext,
+			opentracing.HTTPToContext(internal.Tracer, "Download method", zapkit.NewZapSugarLogger(zap.L(), zapcore.DebugLevel)),
+		),
+	))
+	m.Handle("/shares/create", httpkit.NewServer(
+		ep.CreateShareLinkEndpoint,
+		decodeHTTPCreateShareLinkRequest,
+		encodeResponse,
+		httpkit.ServerBefore(
+			injectContext,
+			opentracing.HTTPToContext(internal.Tracer, "CreateShareLink method", zapkit.NewZapSugarLogger(zap.L(), zapcore.DebugLevel)),
+		),
+	))
+	m.Handle("/shares/list", httpkit.NewServer(
+		ep.ListShareLinksEndpoint,
+		decodeHTTPListShareLinksRequest,
+		encodeResponse,
+		httpkit.ServerBefore(
+			injectContext,
+			opentracing.HTTPToContext(internal.Tracer, "ListShareLinks method", zapkit.NewZapSugarLogger(zap.L(), zapcore.DebugLevel)),
+		),
+	))
+	m.Handle("/shares/revoke", httpkit.NewServer(
+		ep.RevokeShareLinkEndpoint,
+		decodeHTTPShareLinkRequest,
+		encodeResponse,
+		httpkit.ServerBefore(
+			injectContext,
+			opentracing.HTTPToContext(internal.Tracer, "RevokeShareLink method", zapkit.NewZapSugarLogger(zap.L(), zapcore.DebugLevel)),
+		),
+	))
+	m.Handle(watermark.SharePath, httpkit.NewServer(
+		ep.OpenShareLinkEndpoint,
+		decodeHTTPOpenShareLinkRequest,
+		encodeDownloadResponse,
+		httpkit.ServerErrorEncoder(encodeError),
+		httpkit.ServerBefore(
+			opentracing.HTTPToContext(internal.Tracer, "OpenShareLink method", zapkit.NewZapSugarLogger(zap.L(), zapcore.DebugLevel)),
+		),
+	))
 	m.Handle("/remove", httpkit.NewServer(
 		ep.RemoveEndpoint,
 		decodeHTTPRemoveRequest,
@@ -71,6 +221,109 @@ func decodeHTTPGetRequest(_ context.Context, r *http.Request) (interface{}, erro
 	return req, nil
 }
 
+// decodeHTTPGetStatusRequest takes the ticket from the path, /status/{ticket}.
+func decodeHTTPGetStatusRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	return endpoints.GetStatusRequest{TicketID: strings.TrimPrefix(r.URL.Path, "/status/")}, nil
+}
+
+func decodeHTTPListDeadJobsRequest(_ context.Context, _ *http.Request) (interface{}, error) {
+	return endpoints.ListDeadJobsRequest{}, nil
+}
+
+func decodeHTTPRequeueJobRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req endpoints.RequeueJobRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func decodeHTTPRegisterWebhookRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req endpoints.RegisterWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func decodeHTTPStreamTokenRequest(_ context.Context, _ *http.Request) (interface{}, error) {
+	return endpoints.StreamTokenRequest{}, nil
+}
+
+func decodeHTTPListWebhooksRequest(_ context.Context, _ *http.Request) (interface{}, error) {
+	return endpoints.ListWebhooksRequest{}, nil
+}
+
+func decodeHTTPWebhookRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req endpoints.WebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func decodeHTTPDownloadRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	query := r.URL.Query()
+	req := endpoints.DownloadRequest{
+		DocumentID: strings.TrimPrefix(r.URL.Path, watermark.DownloadPath),
+		Signature:  query.Get("signature"),
+	}
+	if expires := query.Get("expires"); expires != "" {
+		var err error
+		if req.Expires, err = strconv.ParseInt(expires, 10, 64); err != nil {
+			return nil, util.ErrInvalidArg
+		}
+	}
+	return req, nil
+}
+
+func decodeHTTPCreateShareLinkRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req endpoints.CreateShareLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func decodeHTTPListShareLinksRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req endpoints.ListShareLinksRequest
+	if r.ContentLength == 0 {
+		return req, nil
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func decodeHTTPShareLinkRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req endpoints.ShareLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// decodeHTTPOpenShareLinkRequest takes the link from the path, /share/{link}, and its password from
+// the X-Share-Password header or the JSON body of a POST, {"password": ...}. The password isn't taken
+// from the query so it doesn't end up in the logs and the browser history.
+func decodeHTTPOpenShareLinkRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	req := endpoints.OpenShareLinkRequest{
+		LinkID:   strings.TrimPrefix(r.URL.Path, watermark.SharePath),
+		Password: r.Header.Get("X-Share-Password"),
+	}
+	if req.Password == "" && r.Method == http.MethodPost {
+		var body struct {
+			Password string `json:"password"`
+		}
+		if err := json.NewDecoder(io.LimitReader(r.Body, 1<<10)).Decode(&body); err != nil && err != io.EOF {
+			return nil, util.ErrInvalidArg
+		}
+		req.Password = body.Password
+	}
+	return req, nil
+}
+
 func decodeHTTPRemoveRequest(_ context.Context, r *http.Request) (interface{}, error) {
 	var req endpoints.RemoveRequest
 	err := json.NewDecoder(r.Body).Decode(&req)
@@ -82,17 +335,15 @@ func decodeHTTPRemoveRequest(_ context.Context, r *http.Request) (interface{}, e
 
 func decodeHTTPAddRequest(ctx context.Context, r *http.Request) (interface{}, error) {
 	var req endpoints.AddRequest
-	val := ctx.Value("image")
-	if val == nil {
-		return nil, util.ErrInvalidArg
+	if err, ok := ctx.Value("error").(error); ok {
+		return nil, err
 	}
-	img, ok := val.(image.Image)
-	if !ok {
+	img, ok := ctx.Value("image").(internal.Blob)
+	if !ok || img.Empty() {
 		return nil, util.ErrInvalidArg
 	}
-	val = ctx.Value("logo")
-	logo, ok := val.(image.Image)
-	if !ok {
+	logo, ok := ctx.Value("logo").(internal.Blob)
+	if !ok || logo.Empty() {
 		return nil, util.ErrInvalidArg
 	}
 	req.Image = img
@@ -100,10 +351,40 @@ func decodeHTTPAddRequest(ctx context.Context, r *http.Request) (interface{}, er
 	req.Fill = r.FormValue("fill") == "true"
 	req.Text = r.FormValue("text")
 	req.Pos = internal.PositionFromString(r.FormValue("pos"))
+	logoOpts, err := pictureTransport.LogoOptionsFromForm(r)
+	if err != nil {
+		return nil, err
+	}
+	req.LogoOptions = logoOpts
+	if presets := r.FormValue("presets"); presets != "" {
+		req.Presets = strings.Split(presets, ",")
+	}
 
 	return req, nil
 }
 
+func decodeHTTPAddBatchRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req endpoints.AddBatchRequest
+	if err, ok := ctx.Value("error").(error); ok {
+		return nil, err
+	}
+	images, ok := ctx.Value("images").([]internal.Blob)
+	if !ok || len(images) == 0 {
+		return nil, util.ErrInvalidArg
+	}
+	req.Images = images
+	req.Logo, _ = ctx.Value("logo").(internal.Blob)
+	req.Fill = r.FormValue("fill") == "true"
+	req.Text = r.FormValue("text")
+	req.Pos = internal.PositionFromString(r.FormValue("pos"))
+	logoOpts, err := pictureTransport.LogoOptionsFromForm(r)
+	if err != nil {
+		return nil, err
+	}
+	req.LogoOptions = logoOpts
+	return req, nil
+}
+
 func decodeHTTPServiceStatusRequest(_ context.Context, _ *http.Request) (interface{}, error) {
 	var req endpoints.ServiceStatusRequest
 	return req, nil
@@ -116,6 +397,30 @@ func encodeResponse(ctx context.Context, w http.ResponseWriter, response interfa
 	return json.NewEncoder(w).Encode(response)
 }
 
+func encodeGetStatusResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	if resp, ok := response.(endpoints.GetStatusResponse); ok && resp.Err != "" {
+		encodeError(ctx, util.FromString(resp.Err), w)
+		return nil
+	}
+	return encodeResponse(ctx, w, response)
+}
+
+func encodeDownloadResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	resp := response.(endpoints.DownloadResponse)
+	if resp.Err != "" {
+		encodeError(ctx, util.FromString(resp.Err), w)
+		return nil
+	}
+	defer resp.Download.Content.Close()
+	if resp.Download.ContentType != "" {
+		w.Header().Set("Content-Type", resp.Download.ContentType)
+	}
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": resp.Download.Name}))
+	w.Header().Set("Cache-Control", "private, no-store")
+	_, err := io.Copy(w, resp.Download.Content)
+	return err
+}
+
 func encodeError(_ context.Context, err error, w http.ResponseWriter) {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	switch err {
@@ -123,6 +428,10 @@ func encodeError(_ context.Context, err error, w http.ResponseWriter) {
 		w.WriteHeader(http.StatusNotFound)
 	case util.ErrInvalidArg:
 		w.WriteHeader(http.StatusBadRequest)
+	case util.ErrTooLarge:
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+	case util.ErrForbidden:
+		w.WriteHeader(http.StatusForbidden)
 	default:
 		w.WriteHeader(http.StatusInternalServerError)
 	}
@@ -131,46 +440,147 @@ func encodeError(_ context.Context, err error, w http.ResponseWriter) {
 	})
 }
 
+// extractImages puts the uploaded images in the context, an upload exceeding util.Limits
+// is stored there as an error to be returned by the request decoder.
 func extractImages(ctx context.Context, r *http.Request) (newCtx context.Context) {
+	// the image and the logo with some room for the other fields
+	r.Body = http.MaxBytesReader(nil, r.Body, 2*util.Limits.MaxBytes+1<<20)
 	err := r.ParseMultipartForm(32 << 20)
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return context.WithValue(ctx, "error", util.ErrTooLarge)
+	}
 	if err != nil {
 		return ctx
 	}
-	img := getImageFromFile("image", r)
+	img, err := getImageFromFile("image", r)
+	if err != nil {
+		return context.WithValue(ctx, "error", err)
+	}
 	newCtx = context.WithValue(ctx, "image", img)
-	logo := getImageFromFile("logo", r)
+	logo, err := getImageFromFile("logo", r)
+	if err != nil {
+		return context.WithValue(ctx, "error", err)
+	}
 	newCtx = context.WithValue(newCtx, "logo", logo)
 	return
 }
 
-func getImageFromFile(name string, r *http.Request) image.Image {
-	var image image.Image
-	var regexp_result []string
+// extractBatchImages works like extractImages for the batches, the images are sent as repeated "images" files.
+// An image which can't be decoded is kept empty and fails on its own.
+func extractBatchImages(ctx context.Context, r *http.Request) context.Context {
+	r.Body = http.MaxBytesReader(nil, r.Body, maxBatchBody)
+	err := r.ParseMultipartForm(32 << 20)
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return context.WithValue(ctx, "error", util.ErrTooLarge)
+	}
+	if err != nil {
+		return ctx
+	}
+	res, _ := regexp.Compile(`.[0-9a-z]+$`)
+	headers := r.MultipartForm.File["images"]
+	images := make([]internal.Blob, len(headers))
+	for i, header := range headers {
+		ext := res.FindString(header.Filename)
+		if util.Limits.CheckBytes(header.Size) != nil || ext == "" {
+			continue
+		}
+		file, err := header.Open()
+		if err != nil {
+			continue
+		}
+		data, err := io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			continue
+		}
+		if _, err := util.CheckImage(data, ext); err == nil {
+			images[i] = internal.Blob{Data: data, Format: ext}
+		}
+	}
+	ctx = context.WithValue(ctx, "images", images)
+	logo, err := getImageFromFile("logo", r)
+	if err != nil {
+		return context.WithValue(ctx, "error", err)
+	}
+	return context.WithValue(ctx, "logo", logo)
+}
 
+// getImageFromFile returns an error only for images exceeding util.Limits, missing or undecodable
+// files result in an empty blob. The upload is kept encoded, only its header is checked.
+func getImageFromFile(name string, r *http.Request) (internal.Blob, error) {
 	res, _ := regexp.Compile(`.[0-9a-z]+$`)
 	file, header, err := r.FormFile(name)
 	if err != nil {
-		return nil
+		return internal.Blob{}, nil
 	}
-	regexp_result = res.FindAllString(header.Filename, -1)
+	defer file.Close()
+	if err := util.Limits.CheckBytes(header.Size); err != nil {
+		return internal.Blob{}, err
+	}
+	regexp_result := res.FindAllString(header.Filename, -1)
 	if len(regexp_result) == 0 {
-		return nil
+		return internal.Blob{}, nil
 	}
-	switch regexp_result[0] {
-	case ".png":
-		image, err = png.Decode(file)
-		if err != nil {
-			image = nil
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return internal.Blob{}, nil
+	}
+	if _, err := util.CheckImage(data, regexp_result[0]); err == util.ErrTooLarge {
+		return internal.Blob{}, err
+	} else if err != nil {
+		return internal.Blob{}, nil
+	}
+	return internal.Blob{Data: data, Format: regexp_result[0]}, nil
+}
+
+// sseKeepAlive keeps the proxies from closing an idle event stream.
+const sseKeepAlive = 30 * time.Second
+
+// newEventsHandler streams the updates as Server-Sent Events. EventSource can't set headers,
+// so instead of the token it may pass a stream token from /events/token in the "stream_token" query parameter.
+func newEventsHandler(ep endpoints.Set) http.Handler {
+	logger := zapkit.NewZapSugarLogger(zap.L(), zapcore.DebugLevel)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := injectContext(r.Context(), r)
+		ctx = opentracing.HTTPToContext(internal.Tracer, "Watch method", logger)(ctx, r)
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			encodeError(ctx, errors.New("streaming unsupported"), w)
+			return
 		}
-	case ".jpg":
-		image, err = jpeg.Decode(file)
+		resp, err := ep.WatchEndpoint(ctx, endpoints.WatchRequest{StreamToken: r.URL.Query().Get("stream_token")})
 		if err != nil {
-			image = nil
+			encodeError(ctx, err, w)
+			return
 		}
-	default:
-		image = nil
-	}
-	return image
+		watchResp := resp.(endpoints.WatchResponse)
+		if watchResp.Err != "" {
+			encodeError(ctx, util.FromString(watchResp.Err), w)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+		keepAlive := time.NewTicker(sseKeepAlive)
+		defer keepAlive.Stop()
+		for {
+			select {
+			case update, ok := <-watchResp.Updates:
+				if !ok {
+					return
+				}
+				data, _ := json.Marshal(update)
+				fmt.Fprintf(w, "event: %s\ndata: %s\n\n", update.Event, data)
+			case <-keepAlive.C:
+				fmt.Fprint(w, ": keep-alive\n\n")
+			}
+			flusher.Flush()
+		}
+	})
 }
 
 func injectContext(ctx context.Context, r *http.Request) context.Context {
@@ -0,0 +1,194 @@
+package watermark
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"watermark-service/internal"
+	"watermark-service/internal/util"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+const (
+	// updatesChannel is the Postgres channel fanning the updates out to all the instances
+	updatesChannel = "watermark_updates"
+	// watchBuffer updates may wait for a slow watcher, the following ones are dropped
+	watchBuffer = 64
+	// listenRetry is the delay before the listener reconnects
+	listenRetry = 2 * time.Second
+	// streamTokenTTL is how long a stream token may be used to open a stream, the open streams aren't cut
+	streamTokenTTL = time.Minute
+)
+
+// notification is the payload of the updates sent through updatesChannel.
+type notification struct {
+	AuthorId int32           `json:"author_id"`
+	Update   internal.Update `json:"update"`
+}
+
+// hub dispatches the updates to the watchers connected to this instance.
+type hub struct {
+	mu       sync.Mutex
+	watchers map[int32]map[chan internal.Update]struct{}
+}
+
+func newHub() *hub {
+	return &hub{watchers: make(map[int32]map[chan internal.Update]struct{})}
+}
+
+func (h *hub) subscribe(authorID int32) (chan internal.Update, func()) {
+	ch := make(chan internal.Update, watchBuffer)
+	h.mu.Lock()
+	if h.watchers[authorID] == nil {
+		h.watchers[authorID] = make(map[chan internal.Update]struct{})
+	}
+	h.watchers[authorID][ch] = struct{}{}
+	h.mu.Unlock()
+	return ch, func() {
+		h.mu.Lock()
+		delete(h.watchers[authorID], ch)
+		if len(h.watchers[authorID]) == 0 {
+			delete(h.watchers, authorID)
+		}
+		h.mu.Unlock()
+		close(ch)
+	}
+}
+
+func (h *hub) publish(authorID int32, update internal.Update) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.watchers[authorID] {
+		select {
+		case ch <- update:
+		default:
+		}
+	}
+}
+
+// listener delivers the notifications of all the instances to the hub, it reconnects until the
+// database is reachable again. Meanwhile the updates are only published locally.
+type listener struct {
+	dsn       string
+	hub       *hub
+	listening atomic.Bool
+	log       *zap.Logger
+}
+
+func (l *listener) run() {
+	for {
+		if err := l.listen(context.Background()); err != nil {
+			l.log.Error("Listen", zap.String("Channel", updatesChannel), zap.Error(err))
+		}
+		l.listening.Store(false)
+		time.Sleep(listenRetry)
+	}
+}
+
+func (l *listener) listen(ctx context.Context) error {
+	conn, err := pgx.Connect(ctx, l.dsn)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(ctx)
+	if _, err := conn.Exec(ctx, "LISTEN "+updatesChannel); err != nil {
+		return err
+	}
+	l.listening.Store(true)
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		var msg notification
+		if err := json.Unmarshal([]byte(n.Payload), &msg); err != nil {
+			l.log.Error("Listen", zap.String("Payload", n.Payload), zap.Error(err))
+			continue
+		}
+		l.hub.publish(msg.AuthorId, msg.Update)
+	}
+}
+
+// notify sends the update to the watchers of the user on every instance.
+func (d *watermarkService) notify(authorID int32, update internal.Update) {
+	if !d.DBAvailable || !d.listener.listening.Load() {
+		d.hub.publish(authorID, update)
+		return
+	}
+	payload, err := json.Marshal(notification{AuthorId: authorID, Update: update})
+	if err == nil {
+		err = d.ORMInstance.Exec("SELECT pg_notify(?, ?)", updatesChannel, string(payload)).Error
+	}
+	if err != nil {
+		d.log.Error("Notify", zap.String("Event", update.Event), zap.Error(err))
+		d.hub.publish(authorID, update)
+	}
+}
+
+// streamSubject is signed by the stream tokens, the prefix keeps them apart from the download links.
+func streamSubject(authorID int32) string {
+	return "stream:" + strconv.Itoa(int(authorID))
+}
+
+// StreamToken issues a short-lived token opening the update stream of the user, it's meant for the
+// clients which can't set headers, like EventSource, and grants nothing but Watch.
+func (d *watermarkService) StreamToken(ctx context.Context) (internal.StreamToken, error) {
+	claimedUser, ok := ctx.Value("user").(*internal.User)
+	if !ok {
+		return internal.StreamToken{}, util.ErrForbidden
+	}
+	expires := time.Now().Add(streamTokenTTL).Unix()
+	signature := signLink(d.links.Secrets[0], streamSubject(claimedUser.ID), expires)
+	return internal.StreamToken{
+		Token:     strings.Join([]string{strconv.Itoa(int(claimedUser.ID)), strconv.FormatInt(expires, 10), signature}, "."),
+		ExpiresAt: expires,
+	}, nil
+}
+
+// streamUser is the user a valid stream token was issued to.
+func (d *watermarkService) streamUser(token string) (int32, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return 0, false
+	}
+	id, err := strconv.ParseInt(parts[0], 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	expires, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || !d.validLink(streamSubject(int32(id)), expires, parts[2]) {
+		return 0, false
+	}
+	return int32(id), true
+}
+
+// Watch streams the updates of the documents and the jobs of the user until ctx is done, the user
+// is identified by the stream token when it's given.
+func (d *watermarkService) Watch(ctx context.Context, streamToken string) (<-chan internal.Update, error) {
+	var authorID int32
+	if streamToken != "" {
+		id, ok := d.streamUser(streamToken)
+		if !ok {
+			return nil, util.ErrForbidden
+		}
+		authorID = id
+	} else {
+		claimedUser, ok := ctx.Value("user").(*internal.User)
+		if !ok {
+			return nil, util.ErrForbidden
+		}
+		authorID = claimedUser.ID
+	}
+	ch, unsubscribe := d.hub.subscribe(authorID)
+	go func() {
+		<-ctx.Done()
+		unsubscribe()
+	}()
+	return ch, nil
+}
@@ -0,0 +1,78 @@
+package watermark
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+	"watermark-service/internal"
+	"watermark-service/internal/util"
+)
+
+func TestStreamToken(t *testing.T) {
+	d := newTestService(t)
+	if _, err := d.StreamToken(context.Background()); err != util.ErrForbidden {
+		t.Errorf("StreamToken() without a user = %v", err)
+	}
+	token, err := d.StreamToken(userContext(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.ExpiresAt <= time.Now().Unix() || token.ExpiresAt > time.Now().Add(streamTokenTTL).Unix() {
+		t.Errorf("ExpiresAt = %d", token.ExpiresAt)
+	}
+	expired := time.Now().Add(-time.Second).Unix()
+	expiredToken := "7." + strconv.FormatInt(expired, 10) + "." + signLink("secret", streamSubject(7), expired)
+	future := time.Now().Add(time.Hour).Unix()
+	tests := []struct {
+		name  string
+		token string
+		id    int32
+		ok    bool
+	}{
+		{"issued", token.Token, 7, true},
+		{"expired", expiredToken, 0, false},
+		{"other user", "8" + token.Token[1:], 0, false},
+		{"download link signature", "7." + strconv.FormatInt(future, 10) + "." + signLink("secret", "7", future), 0, false},
+		{"malformed", "7.abc", 0, false},
+		{"empty", "", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, ok := d.streamUser(tt.token)
+			if id != tt.id || ok != tt.ok {
+				t.Errorf("streamUser() = %d, %v, want %d, %v", id, ok, tt.id, tt.ok)
+			}
+		})
+	}
+}
+
+func TestWatch(t *testing.T) {
+	d := newTestService(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if _, err := d.Watch(ctx, ""); err != util.ErrForbidden {
+		t.Errorf("Watch() without a user = %v", err)
+	}
+	if _, err := d.Watch(ctx, "7.1.abc"); err != util.ErrForbidden {
+		t.Errorf("Watch() with an invalid stream token = %v", err)
+	}
+	token, err := d.StreamToken(userContext(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	updates, err := d.Watch(ctx, token.Token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.hub.publish(8, internal.Update{Event: internal.EventDocumentRemoved})
+	d.hub.publish(7, internal.Update{Event: internal.EventDocumentFinished})
+	select {
+	case update := <-updates:
+		if update.Event != internal.EventDocumentFinished {
+			t.Errorf("update of another user: %+v", update)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no update")
+	}
+}
@@ -4,12 +4,12 @@ import (
 	"bytes"
 	"context"
 	"errors"
-	"image"
-	"image/png"
+	"io"
 	"net/http"
 	"strings"
 	"time"
 	"watermark-service/internal"
+	"watermark-service/internal/util"
 	"watermark-service/internal/watermark"
 	pictureService "watermark-service/pkg/picture"
 	pictureTransport "watermark-service/pkg/picture/transport"
@@ -20,6 +20,7 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type watermarkService struct {
@@ -28,11 +29,23 @@ type watermarkService struct {
 	Dsn              string
 	pictureAvailable bool
 	pictureClient    pictureService.Service
-	storage          watermark.Storage
+	replicas         []pictureService.Service
+	tiling           Tiling
+	jobs             Jobs
+	links            Links
+	wake             chan struct{}
+	hooksWake        chan struct{}
+	hub              *hub
+	listener         *listener
+	storage          *watermark.Storages
 	log              *zap.Logger
 }
 
-func NewService(dbConnection internal.DatabaseConnectionStr, pictureServiceAddr string, cloudName, apiKey, secretKey string) *watermarkService {
+// NewService creates the watermark service, the huge images are rendered by the picture service
+// replicas listed in tiling, or by the main picture service when there are none.
+// The Add jobs are processed in the background as configured by jobs, the results are kept in the storage backends
+// and downloaded through the links.
+func NewService(dbConnection internal.DatabaseConnectionStr, pictureServiceAddr string, tiling Tiling, jobs Jobs, links Links, storage *watermark.Storages) *watermarkService {
 	dsn := dbConnection.GetDSN()
 	db, err := gorm.Open(postgres.New(postgres.Config{
 		DSN: dsn,
@@ -41,7 +54,7 @@ func NewService(dbConnection internal.DatabaseConnectionStr, pictureServiceAddr
 		ORMInstance:      db,
 		DBAvailable:      true,
 		Dsn:              dsn,
-		storage:          watermark.NewCloudinaryStorage(cloudName, apiKey, secretKey),
+		storage:          storage,
 		pictureAvailable: true,
 		log:              zap.L().With(zap.String("Service", "WatermarkService")),
 	}
@@ -51,19 +64,74 @@ func NewService(dbConnection internal.DatabaseConnectionStr, pictureServiceAddr
 		service.DBAvailable = false
 		go service.Reconnect()
 	}
-	conn, err := grpc.Dial(
-		pictureServiceAddr,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
+	service.pictureClient, err = dialPicture(pictureServiceAddr)
 	if err != nil {
 		service.log.Error("Dialing", zap.String("Dialing", "Picture Service"), zap.Error(err))
 		service.pictureAvailable = false
-	} else {
-		service.pictureClient = pictureTransport.NewGRPCClient(conn)
 	}
+	if tiling.Threshold <= 0 {
+		tiling.Threshold = DefaultTilingThreshold
+	}
+	if tiling.TileSize <= 0 {
+		tiling.TileSize = DefaultTileSize
+	}
+	service.tiling = tiling
+	for _, addr := range tiling.Replicas {
+		client, err := dialPicture(addr)
+		if err != nil {
+			service.log.Error("Dialing", zap.String("Dialing", "Picture Service replica"), zap.String("Address", addr), zap.Error(err))
+			continue
+		}
+		service.replicas = append(service.replicas, client)
+	}
+	if len(service.replicas) == 0 && service.pictureClient != nil {
+		service.replicas = []pictureService.Service{service.pictureClient}
+	}
+	if jobs.Workers <= 0 {
+		jobs.Workers = DefaultJobWorkers
+	}
+	if jobs.QueueSize <= 0 {
+		jobs.QueueSize = DefaultJobQueueSize
+	}
+	if jobs.MaxAttempts <= 0 {
+		jobs.MaxAttempts = DefaultJobMaxAttempts
+	}
+	if jobs.Lease <= 0 {
+		jobs.Lease = DefaultJobLease
+	}
+	if jobs.Backoff <= 0 {
+		jobs.Backoff = DefaultJobBackoff
+	}
+	service.jobs = jobs
+	if err := links.init(service.log); err != nil {
+		service.log.Fatal("Links", zap.String("Secret", "generation failed"), zap.Error(err))
+	}
+	service.links = links
+	service.wake = make(chan struct{}, 1)
+	service.startWorkers()
+	service.hooksWake = make(chan struct{}, 1)
+	service.startWebhookWorkers()
+	service.hub = newHub()
+	service.listener = &listener{dsn: dsn, hub: service.hub, log: service.log}
+	go service.listener.run()
 	return service
 }
 
+func dialPicture(addr string) (pictureService.Service, error) {
+	conn, err := grpc.Dial(
+		addr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(
+			grpc.MaxCallRecvMsgSize(pictureService.MaxMessageSize),
+			grpc.MaxCallSendMsgSize(pictureService.MaxMessageSize),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pictureTransport.NewGRPCClient(conn), nil
+}
+
 func (w *watermarkService) Reconnect() {
 	for idleTime := time.Duration(2); idleTime < 1000; idleTime *= idleTime {
 		db, err := gorm.Open(postgres.New(postgres.Config{
@@ -84,48 +152,234 @@ func (w *watermarkService) Reconnect() {
 	w.log.Info("Reconnect", zap.String("Status", "Success"), zap.String("Connection", w.Dsn))
 }
 
-func (d *watermarkService) Add(ctx context.Context, logo image.Image, image image.Image, text string, fill bool, pos internal.Position) (string, error) {
+// Add validates the request and queues it, the returned ticket is polled with GetStatus
+// which reports the URL of the document once the job is finished. The requests which can't
+// succeed are rejected here rather than retried by the workers.
+func (d *watermarkService) Add(ctx context.Context, logo internal.Blob, image internal.Blob, text string, fill bool, pos internal.Position, logoOpts internal.LogoOptions, presets []string) (string, map[string]string, error) {
 	span := internal.StartSpan("Add", ctx)
 	defer span.Finish()
 	claimedUser, ok := ctx.Value("user").(*internal.User)
 	if !ok {
-		return "", nil
-	}
-	resImg, err := d.pictureClient.Create(
-		opentracing.ContextWithSpan(ctx, span),
-		image,
-		logo,
-		text,
-		fill,
-		pos,
-	)
+		return "", nil, nil
+	}
+	if text == "" && logo.Empty() {
+		return "", nil, util.ErrInvalidArg
+	}
+	blobs := []internal.Blob{image}
+	if !logo.Empty() {
+		blobs = append(blobs, logo)
+	}
+	for _, blob := range blobs {
+		if _, err := util.CheckImage(blob.Data, blob.Format); err == util.ErrTooLarge {
+			return "", nil, err
+		} else if err != nil {
+			return "", nil, util.ErrInvalidArg
+		}
+	}
+	if err := logoOpts.Validate(); err != nil {
+		d.log.Error("Logo options", zap.Any("Options", logoOpts), zap.Error(err))
+		return "", nil, util.ErrInvalidArg
+	}
+	for _, name := range presets {
+		if _, err := internal.PresetFromString(name); err != nil {
+			d.log.Error("Presets", zap.String("Preset", name), zap.Error(err))
+			return "", nil, util.ErrInvalidArg
+		}
+	}
+	j := &job{
+		user:     claimedUser,
+		logo:     logo,
+		image:    image,
+		text:     text,
+		fill:     fill,
+		pos:      pos,
+		logoOpts: logoOpts,
+		presets:  presets,
+	}
+	if err := d.enqueue(opentracing.ContextWithSpan(ctx, span), j); err != nil {
+		return "", nil, err
+	}
+	d.emit(claimedUser.ID, internal.Update{Event: internal.EventDocumentCreated, TicketID: j.id.String(), Status: internal.Pending})
+	return j.id.String(), nil, nil
+}
+
+// render watermarks the image of the job and stores the result, progress is reported in percents
+// for each stored preset, a single result is reported by the Finished update.
+func (d *watermarkService) render(ctx context.Context, j *job, progress func(int32)) (string, map[string]string, error) {
+	if len(j.presets) > 0 {
+		return d.addPresets(ctx, j, progress)
+	}
+	doc, err := d.storeOnce(ctx, j, "", "TestImage", "text", func() (internal.Blob, error) {
+		if d.tiled(j.image) {
+			return d.renderTiles(ctx, j.image, j.logo, j.text, j.fill, j.pos, j.logoOpts)
+		}
+		return d.pictureClient.Create(
+			ctx,
+			j.image,
+			j.logo,
+			j.text,
+			j.fill,
+			j.pos,
+			j.logoOpts,
+		)
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return doc.ImageUrl, nil, nil
+}
+
+// addPresets produces one document per preset, the URL of the first one is reported
+// as the main result for the clients unaware of presets.
+func (d *watermarkService) addPresets(ctx context.Context, j *job, progress func(int32)) (string, map[string]string, error) {
+	bounds, err := util.CheckImage(j.image.Data, j.image.Format)
+	if err == util.ErrTooLarge {
+		return "", nil, err
+	} else if err != nil {
+		return "", nil, util.ErrInvalidArg
+	}
+	resolved := make([]internal.Preset, len(j.presets))
+	for i, name := range j.presets {
+		preset, err := internal.PresetFromString(name)
+		if err != nil {
+			d.log.Error("Presets", zap.String("Preset", name), zap.Error(err))
+			return "", nil, util.ErrInvalidArg
+		}
+		resolved[i] = preset
+	}
+	outputs := make(map[string]string, len(resolved))
+	var mainURL string
+	for i, preset := range resolved {
+		doc, err := d.storeOnce(ctx, j, preset.Name, preset.Name, preset.Name, func() (internal.Blob, error) {
+			ops := preset.Operations(bounds, j.text, j.fill, j.logoOpts)
+			return d.pictureClient.Process(ctx, j.image, j.logo, ops)
+		})
+		if err != nil {
+			return mainURL, outputs, err
+		}
+		url := doc.ImageUrl
+		if mainURL == "" {
+			mainURL = url
+		}
+		outputs[preset.Name] = url
+		progress(int32(100 * (i + 1) / (len(resolved) + 1)))
+	}
+	return mainURL, outputs, nil
+}
+
+// storeOnce renders and stores the result of the job for the preset, the result stored by
+// a previous attempt of the job is reused. When two workers store the same result, because the
+// lease of the first one expired, the copy of the one losing the race is deleted.
+func (d *watermarkService) storeOnce(ctx context.Context, j *job, preset, title, name string, render func() (internal.Blob, error)) (watermark.Document, error) {
+	var doc watermark.Document
+	res := d.ORMInstance.Limit(1).Find(&doc, "job_id = ? AND preset = ?", j.id, preset)
+	if res.Error != nil {
+		return watermark.Document{}, res.Error
+	}
+	if res.RowsAffected > 0 {
+		d.log.Info("Job", zap.String("ID", j.id.String()), zap.String("Preset", preset), zap.String("Result", "already stored"))
+		return doc, nil
+	}
+	resImg, err := render()
+	if err != nil {
+		d.log.Error("Picture Service", zap.String("Request", "failed"), zap.String("Preset", preset), zap.Error(err))
+		return watermark.Document{}, err
+	}
+	// the picture service returns the result encoded, it is uploaded as is
+	placement, err := d.upload(ctx, name+resImg.Format, bytes.NewReader(resImg.Data))
 	if err != nil {
-		d.log.Error("Picture Service", zap.String("Create request", "failed"), zap.Error(err))
-		return "", err
+		return watermark.Document{}, err
+	}
+	doc = watermark.Document{
+		AuthorId:  j.user.ID,
+		Title:     title,
+		ImageUrl:  placement.URL,
+		Locations: placement.Locations,
+		JobID:     &j.id,
+		Preset:    preset,
+	}
+	res = d.ORMInstance.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "job_id"}, {Name: "preset"}},
+		DoNothing: true,
+	}).Create(&doc)
+	if res.Error != nil || res.RowsAffected == 0 {
+		if remaining, err := d.storage.Delete(ctx, placement.Locations); err != nil {
+			d.log.Warn("Storage", zap.String("Duplicate result removal", "failed"), zap.Any("Locations", remaining), zap.Error(err))
+		}
+	}
+	if res.Error != nil {
+		return watermark.Document{}, res.Error
+	}
+	if res.RowsAffected == 0 {
+		doc = watermark.Document{}
+		res = d.ORMInstance.Limit(1).Find(&doc, "job_id = ? AND preset = ?", j.id, preset)
+		if res.Error == nil && res.RowsAffected == 0 {
+			return watermark.Document{}, util.ErrUnknownArg
+		}
+		return doc, res.Error
+	}
+	return doc, nil
+}
+
+// AddBatch watermarks all the images with a single picture service call and uploads
+// the results concurrently, a failed image doesn't fail the rest of the batch.
+func (d *watermarkService) AddBatch(ctx context.Context, logo internal.Blob, images []internal.Blob, text string, fill bool, pos internal.Position, logoOpts internal.LogoOptions) ([]internal.BatchItem, error) {
+	span := internal.StartSpan("AddBatch", ctx)
+	defer span.Finish()
+	claimedUser, ok := ctx.Value("user").(*internal.User)
+	if !ok {
+		return nil, nil
 	}
-	buf := new(bytes.Buffer)
-	err = png.Encode(buf, resImg)
+	items, err := d.pictureClient.CreateBatch(opentracing.ContextWithSpan(ctx, span), images, logo, text, fill, pos, logoOpts)
 	if err != nil {
-		d.log.Error("Image encoding", zap.String("Status", "failed"), zap.Error(err))
-		return "", err
+		d.log.Error("Picture Service", zap.String("CreateBatch request", "failed"), zap.Error(err))
+		return nil, err
 	}
-	url, err := d.storage.Upload(ctx, "text.png", buf)
+	internal.ForEach(len(items), uploadWorkers, func(i int) {
+		item := &items[i]
+		if item.Err != "" {
+			return
+		}
+		img := item.Image
+		item.Image = internal.Blob{}
+		doc, err := d.store(ctx, claimedUser, "TestImage", "text"+img.Format, bytes.NewReader(img.Data))
+		if err != nil {
+			item.Err = err.Error()
+			return
+		}
+		item.TicketID = doc.ID.String()
+		d.emit(claimedUser.ID, internal.Update{Event: internal.EventDocumentFinished, TicketID: item.TicketID, Status: internal.Finished, ImageUrl: d.link(doc.ID)})
+	})
+	return items, nil
+}
+
+// upload stores the object in the storage backends, logging the backends which failed.
+func (d *watermarkService) upload(ctx context.Context, name string, data io.Reader) (watermark.Placement, error) {
+	placement, err := d.storage.Upload(ctx, name, data)
+	for backend, err := range placement.Errs {
+		d.log.Error("Storage", zap.String("image upload", "failed"), zap.String("Backend", backend), zap.Error(err))
+	}
+	return placement, err
+}
+
+func (d *watermarkService) store(ctx context.Context, user *internal.User, title, name string, data io.Reader) (watermark.Document, error) {
+	placement, err := d.upload(ctx, name, data)
 	if err != nil {
-		d.log.Error("Storage", zap.String("image upload", "failed"), zap.Error(err))
-		return "", nil
+		return watermark.Document{}, err
 	}
 	newDoc := watermark.Document{
-		AuthorId: claimedUser.ID,
-		Title:    "TestImage",
-		ImageUrl: url,
+		AuthorId:  user.ID,
+		Title:     title,
+		ImageUrl:  placement.URL,
+		Locations: placement.Locations,
 	}
 	result := d.ORMInstance.Create(&newDoc)
 	if result.Error != nil && strings.Contains(result.Error.Error(), "duplicate key value violates unique") {
-		return "", errors.New("Document already exists")
+		return watermark.Document{}, errors.New("Document already exists")
 	} else if result.Error != nil {
-		return "", errors.New(result.Error.Error())
+		return watermark.Document{}, errors.New(result.Error.Error())
 	}
-	return url, nil
+	return newDoc, nil
 }
 
 func (d *watermarkService) Get(ctx context.Context, filters ...internal.Filter) ([]internal.Document, error) {
@@ -144,7 +398,7 @@ func (d *watermarkService) Get(ctx context.Context, filters ...internal.Filter)
 			ID:       doc.ID,
 			AuthorId: doc.AuthorId,
 			Title:    doc.Title,
-			ImageUrl: doc.ImageUrl,
+			ImageUrl: d.link(doc.ID),
 		}
 	}
 	return docs, nil
@@ -155,26 +409,35 @@ func (d *watermarkService) Remove(ctx context.Context, ticketId string) (int, er
 	if !ok {
 		return http.StatusUnauthorized, nil
 	}
-	var result []watermark.Document
-	r := d.ORMInstance.Model(&watermark.Document{}).Find(&result, "author_id = ? AND image_url = ?", claimedUser.ID, ticketId)
-	if r.Error != nil {
-		return http.StatusInternalServerError, r.Error
-	}
-	if len(result) == 0 {
+	doc, err := d.findDocument(claimedUser.ID, ticketId)
+	if err == util.ErrUnknownArg {
 		return http.StatusNotFound, nil
 	}
-	r = d.ORMInstance.Delete(&watermark.Document{}, "image_url = ?", ticketId)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	r := d.ORMInstance.Delete(&watermark.Document{}, "id = ?", doc.ID)
 	if r.Error != nil {
 		return http.StatusInternalServerError, r.Error
 	}
-	err := d.storage.Delete(ctx, ticketId)
+	remaining, err := d.storage.Delete(ctx, d.locations(doc))
 	if err != nil {
-		d.ORMInstance.Model(&watermark.Document{}).Where("image_url", ticketId).Update("deleted_at", nil)
+		d.ORMInstance.Unscoped().Model(&watermark.Document{}).Where("id = ?", doc.ID).
+			Select("deleted_at", "locations").Updates(&watermark.Document{Locations: remaining})
 		return http.StatusInternalServerError, err
 	}
+	d.emit(claimedUser.ID, internal.Update{Event: internal.EventDocumentRemoved, TicketID: doc.ID.String()})
 	return http.StatusOK, nil
 }
 
+// locations are the copies of the document, a document without recorded ones is held by the primary backend.
+func (d *watermarkService) locations(doc watermark.Document) watermark.Locations {
+	if len(doc.Locations) == 0 {
+		return watermark.Locations{d.storage.Primary(): doc.ImageUrl}
+	}
+	return doc.Locations
+}
+
 func (d *watermarkService) ServiceStatus(_ context.Context) (int, error) {
 	return http.StatusOK, nil
 }
@@ -0,0 +1,110 @@
+package watermark
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"testing"
+	"time"
+	"watermark-service/internal"
+	"watermark-service/internal/util"
+	"watermark-service/internal/watermark"
+
+	"github.com/glebarez/sqlite"
+	"github.com/opentracing/opentracing-go"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestService creates a service backed by a SQLite database and a filesystem storage,
+// the background workers aren't started.
+func newTestService(t *testing.T) *watermarkService {
+	t.Helper()
+	internal.Tracer = opentracing.NoopTracer{}
+	db, err := gorm.Open(sqlite.Open(t.TempDir()+"/db.sqlite"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := watermark.InitDb(db); err != nil {
+		t.Fatal(err)
+	}
+	fs, err := watermark.NewFilesystemStorage(t.TempDir(), "http://files.test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	storage, err := watermark.NewStorages(map[string]watermark.Storage{"filesystem": fs}, "filesystem", "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := &watermarkService{
+		ORMInstance: db,
+		DBAvailable: true,
+		jobs:        Jobs{Workers: 1, QueueSize: 10, MaxAttempts: 3, Lease: time.Minute, Backoff: time.Second},
+		links:       Links{BaseURL: "http://watermark.test", Secrets: []string{"secret"}, TTL: time.Hour},
+		wake:        make(chan struct{}, 1),
+		hooksWake:   make(chan struct{}, 1),
+		hub:         newHub(),
+		listener:    &listener{},
+		storage:     storage,
+		log:         zap.NewNop(),
+	}
+	if err := d.links.init(d.log); err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func userContext(id int32) context.Context {
+	return context.WithValue(context.Background(), "user", &internal.User{ID: id, Email: "user@example.com"})
+}
+
+func pngBlob(t *testing.T, w, h int) internal.Blob {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, w, h))); err != nil {
+		t.Fatal(err)
+	}
+	return internal.Blob{Data: buf.Bytes(), Format: ".png"}
+}
+
+func TestAddValidation(t *testing.T) {
+	d := newTestService(t)
+	img := pngBlob(t, 20, 10)
+	tests := []struct {
+		name     string
+		logo     internal.Blob
+		image    internal.Blob
+		text     string
+		logoOpts internal.LogoOptions
+		presets  []string
+		err      error
+	}{
+		{"text", internal.Blob{}, img, "text", internal.LogoOptions{}, nil, nil},
+		{"logo", img, img, "", internal.LogoOptions{Tint: "#f00", Interpolation: "bilinear"}, []string{"instagram_square"}, nil},
+		{"no data", internal.Blob{}, img, "", internal.LogoOptions{}, nil, util.ErrInvalidArg},
+		{"broken image", internal.Blob{}, internal.Blob{Data: []byte("png"), Format: ".png"}, "text", internal.LogoOptions{}, nil, util.ErrInvalidArg},
+		{"broken logo", internal.Blob{Data: []byte("png"), Format: ".png"}, img, "", internal.LogoOptions{}, nil, util.ErrInvalidArg},
+		{"huge image", internal.Blob{}, pngBlob(t, 40000, 1), "text", internal.LogoOptions{}, nil, util.ErrTooLarge},
+		{"bad key color", img, img, "", internal.LogoOptions{KeyColor: "green"}, nil, util.ErrInvalidArg},
+		{"bad tint", img, img, "", internal.LogoOptions{Tint: "#12"}, nil, util.ErrInvalidArg},
+		{"bad interpolation", img, img, "", internal.LogoOptions{Interpolation: "cubic"}, nil, util.ErrInvalidArg},
+		{"unknown preset", internal.Blob{}, img, "text", internal.LogoOptions{}, []string{"billboard"}, util.ErrInvalidArg},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var before int64
+			d.ORMInstance.Model(&watermark.Job{}).Count(&before)
+			ticket, _, err := d.Add(userContext(1), tt.logo, tt.image, tt.text, false, internal.LeftTop, tt.logoOpts, tt.presets)
+			if err != tt.err {
+				t.Fatalf("Add() error = %v, want %v", err, tt.err)
+			}
+			var after int64
+			d.ORMInstance.Model(&watermark.Job{}).Count(&after)
+			if queued := after - before; (err == nil) != (queued == 1) || (err == nil) != (ticket != "") {
+				t.Errorf("Add() = %q, queued %d jobs", ticket, queued)
+			}
+		})
+	}
+}
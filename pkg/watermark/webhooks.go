@@ -0,0 +1,403 @@
+package watermark
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"syscall"
+	"time"
+	"watermark-service/internal"
+	"watermark-service/internal/util"
+	"watermark-service/internal/watermark"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// The callbacks are sent by webhookWorkers workers, a failed delivery is retried after webhookBackoff
+// doubled on every attempt and given up after webhookMaxAttempts.
+const (
+	webhookWorkers     = 2
+	webhookMaxAttempts = 8
+	webhookBackoff     = 10 * time.Second
+	webhookMaxBackoff  = time.Hour
+	webhookTimeout     = 10 * time.Second
+	// webhookLease holds a claimed delivery, it is sent again if the instance dies meanwhile
+	webhookLease = time.Minute
+	// maxDeliveries bounds the delivery log returned for a webhook
+	maxDeliveries = 100
+)
+
+// webhookClient sends the callbacks, the addresses a callback can reach are checked when it connects
+// so a host resolving to an internal address later on doesn't get through.
+var webhookClient = newWebhookClient(publicAddress)
+
+var (
+	errWebhookRemoved = errors.New("webhook removed")
+	errWebhookAddress = errors.New("webhook address is not public")
+)
+
+// newWebhookClient creates a client connecting only to the addresses allowed, the redirects aren't
+// followed and the proxies configured in the environment aren't used as they would bypass the check.
+func newWebhookClient(allowed func(ip net.IP) bool) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: webhookTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !allowed(ip) {
+				return errWebhookAddress
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout:   webhookTimeout,
+		Transport: &http.Transport{DialContext: dialer.DialContext, TLSHandshakeTimeout: webhookTimeout},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// cgnat is the shared address space of the carrier-grade NATs, RFC 6598.
+var cgnat = net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// publicAddress rejects the loopback, private, link-local, shared and unspecified addresses.
+func publicAddress(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() || cgnat.Contains(ip))
+}
+
+// event is the JSON body of a callback, ID is the same for every attempt so the receivers can drop the duplicates.
+type event struct {
+	ID        uuid.UUID   `json:"id"`
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// Sign computes the X-Watermark-Signature of a callback, the receivers check it against
+// the X-Watermark-Timestamp header and the raw body.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (d *watermarkService) startWebhookWorkers() {
+	for i := 0; i < webhookWorkers; i++ {
+		go d.deliverWebhooks()
+	}
+}
+
+func (d *watermarkService) deliverWebhooks() {
+	ticker := time.NewTicker(jobPollInterval)
+	defer ticker.Stop()
+	for {
+		if d.DBAvailable {
+			if row, ok := d.claimDelivery(); ok {
+				d.deliver(row, false)
+				continue
+			}
+		}
+		select {
+		case <-d.hooksWake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// emit pushes the update to the watchers and queues it for the webhooks of the user subscribed to it,
+// a failure is only logged as the event doesn't affect the outcome of the request.
+func (d *watermarkService) emit(authorID int32, update internal.Update) {
+	d.notify(authorID, update)
+	if !d.DBAvailable {
+		return
+	}
+	name := update.Event
+	var hooks []watermark.Webhook
+	if err := d.ORMInstance.Find(&hooks, "author_id = ?", authorID).Error; err != nil {
+		d.log.Error("Webhooks", zap.String("Event", name), zap.Error(err))
+		return
+	}
+	queued := false
+	for _, hook := range hooks {
+		if len(hook.Events) > 0 && !slices.Contains(hook.Events, name) {
+			continue
+		}
+		row, err := newDelivery(hook.ID, name, update)
+		if err == nil {
+			err = d.ORMInstance.Create(row).Error
+		}
+		if err != nil {
+			d.log.Error("Webhooks", zap.String("Event", name), zap.String("Webhook", hook.ID.String()), zap.Error(err))
+			continue
+		}
+		queued = true
+	}
+	if queued {
+		select {
+		case d.hooksWake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func newDelivery(webhookID uuid.UUID, name string, data interface{}) (*watermark.WebhookDelivery, error) {
+	id := uuid.New()
+	payload, err := json.Marshal(event{ID: id, Event: name, CreatedAt: time.Now().UTC(), Data: data})
+	if err != nil {
+		return nil, err
+	}
+	return &watermark.WebhookDelivery{
+		ID:        id,
+		WebhookID: webhookID,
+		Event:     name,
+		Payload:   payload,
+		Status:    string(internal.DeliveryPending),
+		RunAt:     time.Now(),
+	}, nil
+}
+
+// claimDelivery takes the next due delivery pushing its RunAt by webhookLease, the rows locked by the other workers are skipped.
+func (d *watermarkService) claimDelivery() (*watermark.WebhookDelivery, bool) {
+	var row watermark.WebhookDelivery
+	err := d.ORMInstance.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		res := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND run_at <= ?", string(internal.DeliveryPending), now).
+			Order("run_at").
+			Limit(1).
+			Find(&row)
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		row.Attempts++
+		return tx.Model(&row).Updates(map[string]interface{}{
+			"attempts": row.Attempts,
+			"run_at":   now.Add(webhookLease),
+		}).Error
+	})
+	if err != nil {
+		d.log.Error("Webhooks", zap.String("Claim", "failed"), zap.Error(err))
+		return nil, false
+	}
+	return &row, row.ID != uuid.Nil
+}
+
+// deliver sends the delivery and records the outcome, a failed delivery is scheduled again unless final.
+func (d *watermarkService) deliver(row *watermark.WebhookDelivery, final bool) {
+	var hook watermark.Webhook
+	res := d.ORMInstance.Limit(1).Find(&hook, "id = ?", row.WebhookID)
+	var code int
+	err := res.Error
+	if err == nil && res.RowsAffected == 0 {
+		err, final = errWebhookRemoved, true
+	}
+	if err == nil {
+		code, err = send(hook, row)
+	}
+	row.ResponseCode = int32(code)
+	fields := map[string]interface{}{"response_code": row.ResponseCode, "error": ""}
+	switch {
+	case err == nil:
+		row.Status = string(internal.DeliveryDelivered)
+	case final || row.Attempts >= webhookMaxAttempts:
+		row.Status, row.Error = string(internal.DeliveryFailed), err.Error()
+		d.log.Warn("Webhooks", zap.String("Delivery", row.ID.String()), zap.String("Status", "failed"), zap.Error(err))
+	default:
+		row.Status, row.Error = string(internal.DeliveryPending), err.Error()
+		fields["run_at"] = time.Now().Add(min(webhookBackoff<<min(row.Attempts-1, 16), webhookMaxBackoff))
+	}
+	fields["status"], fields["error"] = row.Status, row.Error
+	if err := d.ORMInstance.Model(&watermark.WebhookDelivery{}).Where("id = ?", row.ID).Updates(fields).Error; err != nil {
+		d.log.Error("Webhooks", zap.String("Delivery", row.ID.String()), zap.Error(err))
+	}
+}
+
+// send posts the signed payload, any status but 2xx fails the delivery.
+func send(hook watermark.Webhook, row *watermark.WebhookDelivery) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, hook.URL, bytes.NewReader(row.Payload))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Watermark-Event", row.Event)
+	req.Header.Set("X-Watermark-Delivery", row.ID.String())
+	req.Header.Set("X-Watermark-Timestamp", timestamp)
+	req.Header.Set("X-Watermark-Signature", Sign(hook.Secret, timestamp, row.Payload))
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+func webhookToInternal(hook watermark.Webhook) internal.Webhook {
+	return internal.Webhook{ID: hook.ID, URL: hook.URL, Events: hook.Events, CreatedAt: hook.CreatedAt}
+}
+
+func deliveryToInternal(row watermark.WebhookDelivery) internal.WebhookDelivery {
+	return internal.WebhookDelivery{
+		ID:           row.ID,
+		WebhookID:    row.WebhookID,
+		Event:        row.Event,
+		Status:       internal.DeliveryStatus(row.Status),
+		Attempts:     row.Attempts,
+		ResponseCode: row.ResponseCode,
+		Err:          row.Error,
+		CreatedAt:    row.CreatedAt,
+		UpdatedAt:    row.UpdatedAt,
+	}
+}
+
+// RegisterWebhook subscribes the http(s) endpoint to the events, to all of them when none are given.
+// The endpoints resolving to non public addresses are rejected, the callbacks check the address again
+// when they connect. The returned webhook carries the secret signing the callbacks, it isn't reported afterwards.
+func (d *watermarkService) RegisterWebhook(ctx context.Context, endpoint string, events []string) (internal.Webhook, error) {
+	claimedUser, ok := ctx.Value("user").(*internal.User)
+	if !ok {
+		return internal.Webhook{}, nil
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return internal.Webhook{}, util.ErrInvalidArg
+	}
+	for _, e := range events {
+		if !slices.Contains(internal.Events, e) {
+			return internal.Webhook{}, util.ErrInvalidArg
+		}
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, u.Hostname())
+	if err != nil {
+		d.log.Error("Webhooks", zap.String("Endpoint", u.Host), zap.Error(err))
+		return internal.Webhook{}, util.ErrInvalidArg
+	}
+	for _, addr := range addrs {
+		if !publicAddress(addr.IP) {
+			d.log.Error("Webhooks", zap.String("Endpoint", u.Host), zap.Error(errWebhookAddress))
+			return internal.Webhook{}, util.ErrInvalidArg
+		}
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return internal.Webhook{}, err
+	}
+	hook := watermark.Webhook{AuthorId: claimedUser.ID, URL: u.String(), Secret: hex.EncodeToString(secret), Events: events}
+	if err := d.ORMInstance.Create(&hook).Error; err != nil {
+		return internal.Webhook{}, err
+	}
+	res := webhookToInternal(hook)
+	res.Secret = hook.Secret
+	return res, nil
+}
+
+func (d *watermarkService) ListWebhooks(ctx context.Context) ([]internal.Webhook, error) {
+	claimedUser, ok := ctx.Value("user").(*internal.User)
+	if !ok {
+		return nil, nil
+	}
+	var hooks []watermark.Webhook
+	if err := d.ORMInstance.Order("created_at").Find(&hooks, "author_id = ?", claimedUser.ID).Error; err != nil {
+		return nil, err
+	}
+	res := make([]internal.Webhook, len(hooks))
+	for i, hook := range hooks {
+		res[i] = webhookToInternal(hook)
+	}
+	return res, nil
+}
+
+// RemoveWebhook drops the webhook along with its delivery log.
+func (d *watermarkService) RemoveWebhook(ctx context.Context, webhookID string) error {
+	hook, err := d.findWebhook(ctx, webhookID)
+	if err != nil {
+		return err
+	}
+	return d.ORMInstance.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&watermark.WebhookDelivery{}, "webhook_id = ?", hook.ID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&hook).Error
+	})
+}
+
+// ListWebhookDeliveries reports the latest deliveries of the webhook, the newest first.
+func (d *watermarkService) ListWebhookDeliveries(ctx context.Context, webhookID string) ([]internal.WebhookDelivery, error) {
+	hook, err := d.findWebhook(ctx, webhookID)
+	if err != nil {
+		return nil, err
+	}
+	var rows []watermark.WebhookDelivery
+	res := d.ORMInstance.Omit("payload").Order("created_at DESC").Limit(maxDeliveries).Find(&rows, "webhook_id = ?", hook.ID)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	deliveries := make([]internal.WebhookDelivery, len(rows))
+	for i, row := range rows {
+		deliveries[i] = deliveryToInternal(row)
+	}
+	return deliveries, nil
+}
+
+// PingWebhook sends a ping event right away and reports its delivery, a failed ping isn't retried.
+func (d *watermarkService) PingWebhook(ctx context.Context, webhookID string) (internal.WebhookDelivery, error) {
+	hook, err := d.findWebhook(ctx, webhookID)
+	if err != nil {
+		return internal.WebhookDelivery{}, err
+	}
+	row, err := newDelivery(hook.ID, internal.EventPing, struct{}{})
+	if err != nil {
+		return internal.WebhookDelivery{}, err
+	}
+	// the ping is claimed at once so the workers leave it alone
+	row.Attempts, row.RunAt = 1, time.Now().Add(webhookLease)
+	if err := d.ORMInstance.Create(row).Error; err != nil {
+		return internal.WebhookDelivery{}, err
+	}
+	d.deliver(row, true)
+	return deliveryToInternal(*row), nil
+}
+
+func (d *watermarkService) findWebhook(ctx context.Context, webhookID string) (watermark.Webhook, error) {
+	var hook watermark.Webhook
+	claimedUser, ok := ctx.Value("user").(*internal.User)
+	if !ok {
+		return hook, util.ErrUnknownArg
+	}
+	id, err := uuid.Parse(webhookID)
+	if err != nil {
+		return hook, util.ErrInvalidArg
+	}
+	res := d.ORMInstance.Limit(1).Find(&hook, "id = ? AND author_id = ?", id, claimedUser.ID)
+	if res.Error != nil {
+		return hook, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return hook, util.ErrUnknownArg
+	}
+	return hook, nil
+}
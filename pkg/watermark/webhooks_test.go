@@ -0,0 +1,210 @@
+package watermark
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+	"watermark-service/internal"
+	"watermark-service/internal/util"
+	"watermark-service/internal/watermark"
+)
+
+func TestSign(t *testing.T) {
+	tests := []struct {
+		secret, timestamp, body string
+		want                    string
+	}{
+		{"secret", "1700000000", `{"event":"ping"}`, "sha256=4d39bd2442f073b6bc62e95d0297ce25475582a17389ab860abdc778fe1d9f77"},
+		{"", "0", "", "sha256=b849d5a581847b281957065739df36df2463d1977ea8d6e1e4e6cf33fadc68c3"},
+	}
+	for _, tt := range tests {
+		if got := Sign(tt.secret, tt.timestamp, []byte(tt.body)); got != tt.want {
+			t.Errorf("Sign(%q, %q, %q) = %s, want %s", tt.secret, tt.timestamp, tt.body, got, tt.want)
+		}
+	}
+	if Sign("secret", "1", []byte("body")) == Sign("other", "1", []byte("body")) {
+		t.Error("signature doesn't depend on the secret")
+	}
+}
+
+func TestPublicAddress(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"fc00::1", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"::ffff:127.0.0.1", false},
+		{"224.0.0.1", false},
+	}
+	for _, tt := range tests {
+		if got := publicAddress(net.ParseIP(tt.ip)); got != tt.want {
+			t.Errorf("publicAddress(%s) = %v, want %v", tt.ip, got, tt.want)
+		}
+	}
+}
+
+func TestWebhookClient(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer target.Close()
+	redirect := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusFound))
+	defer redirect.Close()
+
+	// the test servers listen on the loopback address
+	if _, err := webhookClient.Get(target.URL); err == nil {
+		t.Error("loopback address reached")
+	}
+	client := newWebhookClient(func(net.IP) bool { return true })
+	resp, err := client.Get(redirect.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Errorf("redirect followed, status %d", resp.StatusCode)
+	}
+}
+
+func TestRegisterWebhook(t *testing.T) {
+	d := newTestService(t)
+	tests := []struct {
+		name     string
+		endpoint string
+		events   []string
+		err      error
+	}{
+		{"public", "https://93.184.216.34/hook", nil, nil},
+		{"events", "https://93.184.216.34/hook", []string{internal.EventDocumentFinished}, nil},
+		{"unknown event", "https://93.184.216.34/hook", []string{"document.lost"}, util.ErrInvalidArg},
+		{"scheme", "ftp://93.184.216.34/hook", nil, util.ErrInvalidArg},
+		{"loopback", "http://127.0.0.1:8080/hook", nil, util.ErrInvalidArg},
+		{"localhost", "http://localhost/hook", nil, util.ErrInvalidArg},
+		{"metadata", "http://169.254.169.254/latest", nil, util.ErrInvalidArg},
+		{"private", "http://[fd00::1]/hook", nil, util.ErrInvalidArg},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hook, err := d.RegisterWebhook(userContext(1), tt.endpoint, tt.events)
+			if err != tt.err {
+				t.Fatalf("RegisterWebhook() error = %v, want %v", err, tt.err)
+			}
+			if err == nil && len(hook.Secret) != 64 {
+				t.Errorf("RegisterWebhook() secret = %q", hook.Secret)
+			}
+		})
+	}
+}
+
+func TestWebhookDelivery(t *testing.T) {
+	statuses := make(chan int, 16)
+	var got *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		body = make([]byte, r.ContentLength)
+		r.Body.Read(body)
+		w.WriteHeader(<-statuses)
+	}))
+	defer server.Close()
+	client := webhookClient
+	webhookClient = newWebhookClient(func(net.IP) bool { return true })
+	defer func() { webhookClient = client }()
+
+	d := newTestService(t)
+	// the test server is registered directly, its loopback address is rejected by RegisterWebhook
+	hook := watermark.Webhook{AuthorId: 1, URL: server.URL, Secret: "secret", Events: []string{internal.EventDocumentFinished}}
+	if err := d.ORMInstance.Create(&hook).Error; err != nil {
+		t.Fatal(err)
+	}
+	d.emit(1, internal.Update{Event: internal.EventDocumentRemoved})
+	d.emit(2, internal.Update{Event: internal.EventDocumentFinished})
+	d.emit(1, internal.Update{Event: internal.EventDocumentFinished, TicketID: "ticket"})
+
+	claim := func() *watermark.WebhookDelivery {
+		t.Helper()
+		row, ok := d.claimDelivery()
+		if !ok {
+			t.Fatal("no delivery due")
+		}
+		return row
+	}
+	row := claim()
+	if _, ok := d.claimDelivery(); ok {
+		t.Fatal("unsubscribed event or claimed delivery queued")
+	}
+
+	// a failure is retried with the backoff until webhookMaxAttempts
+	for attempt := 1; attempt < webhookMaxAttempts; attempt++ {
+		statuses <- http.StatusInternalServerError
+		d.deliver(row, false)
+		var stored watermark.WebhookDelivery
+		d.ORMInstance.First(&stored, "id = ?", row.ID)
+		if stored.Status != string(internal.DeliveryPending) || stored.ResponseCode != http.StatusInternalServerError {
+			t.Fatalf("attempt %d: delivery %s, code %d", attempt, stored.Status, stored.ResponseCode)
+		}
+		wait := webhookBackoff << (attempt - 1)
+		if delay := time.Until(stored.RunAt); delay <= wait-time.Second || delay > min(wait, webhookMaxBackoff) {
+			t.Fatalf("attempt %d: retried in %v", attempt, delay)
+		}
+		if _, ok := d.claimDelivery(); ok {
+			t.Fatalf("attempt %d: retried before the backoff", attempt)
+		}
+		d.ORMInstance.Model(&stored).Update("run_at", time.Now())
+		row = claim()
+	}
+	if row.Attempts != webhookMaxAttempts {
+		t.Fatalf("attempts = %d", row.Attempts)
+	}
+	statuses <- http.StatusBadGateway
+	d.deliver(row, false)
+	deliveries, err := d.ListWebhookDeliveries(userContext(1), hook.ID.String())
+	if err != nil || len(deliveries) != 1 {
+		t.Fatal(deliveries, err)
+	}
+	if dl := deliveries[0]; dl.Status != internal.DeliveryFailed || dl.Attempts != webhookMaxAttempts || dl.ResponseCode != http.StatusBadGateway {
+		t.Errorf("dead delivery = %+v", dl)
+	}
+	if _, ok := d.claimDelivery(); ok {
+		t.Error("failed delivery claimed again")
+	}
+
+	statuses <- http.StatusOK
+	ping, err := d.PingWebhook(userContext(1), hook.ID.String())
+	if err != nil || ping.Status != internal.DeliveryDelivered {
+		t.Fatal(ping, err)
+	}
+	timestamp := got.Header.Get("X-Watermark-Timestamp")
+	if _, err := strconv.ParseInt(timestamp, 10, 64); err != nil {
+		t.Fatal(err)
+	}
+	if got.Header.Get("X-Watermark-Event") != internal.EventPing || got.Header.Get("X-Watermark-Delivery") != ping.ID.String() {
+		t.Errorf("headers = %v", got.Header)
+	}
+	if sig := got.Header.Get("X-Watermark-Signature"); sig != Sign("secret", timestamp, body) {
+		t.Errorf("signature %s doesn't match the body", sig)
+	}
+
+	if err := d.RemoveWebhook(userContext(1), hook.ID.String()); err != nil {
+		t.Fatal(err)
+	}
+	row, _ = newDelivery(hook.ID, internal.EventDocumentFinished, nil)
+	d.deliver(row, false)
+	if row.Status != string(internal.DeliveryFailed) || row.Error != errWebhookRemoved.Error() {
+		t.Errorf("delivery of a removed webhook = %s %s", row.Status, row.Error)
+	}
+}